@@ -16,6 +16,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/spf13/cobra"
@@ -29,14 +30,20 @@ var isInit = false
 
 func getClient(ctx context.Context) (*lldap.LldapClient, error) {
 	username := os.Getenv("LLDAP_USER")
-	if username == "" {
+	password, passwordErr := lldap.ResolvePassword(os.Getenv("LLDAP_PASSWORD"), os.Getenv("LLDAP_PASSWORD_FILE"), os.Getenv("LLDAP_PASSWORD_COMMAND"))
+	if passwordErr != nil {
+		return nil, passwordErr
+	}
+	token := os.Getenv("LLDAP_TOKEN")
+	refreshToken := os.Getenv("LLDAP_REFRESH_TOKEN")
+	if password == "" && token == "" && refreshToken == "" {
+		return nil, fmt.Errorf("LLDAP_PASSWORD not set, set it or one of LLDAP_PASSWORD_FILE, LLDAP_PASSWORD_COMMAND, LLDAP_TOKEN and LLDAP_REFRESH_TOKEN")
+	}
+	// With a token, the username is taken from its claims unless LLDAP_USER is set
+	if username == "" && token == "" && refreshToken == "" {
 		logger.Debug("LLDAP_USER not set, defaulting to 'admin'")
 		username = "admin"
 	}
-	password := os.Getenv("LLDAP_PASSWORD")
-	if password == "" {
-		return nil, fmt.Errorf("LLDAP_PASSWORD not set")
-	}
 	baseDn := os.Getenv("LLDAP_BASE_DN")
 	if baseDn == "" {
 		return nil, fmt.Errorf("LLDAP_BASE_DN not set")
@@ -76,22 +83,65 @@ func getClient(ctx context.Context) (*lldap.LldapClient, error) {
 			BaseDn:                baseDn,
 			InsecureSkipCertCheck: insecureCert,
 		},
+		RefreshToken: refreshToken,
+	}
+	if token != "" {
+		if setTokenErr := client.SetToken(token); setTokenErr != nil {
+			return nil, setTokenErr
+		}
+	}
+	if policyFile := os.Getenv("LLDAP_PASSWORD_POLICY_FILE"); policyFile != "" {
+		policy, policyErr := lldap.ReadPasswordPolicyFile(policyFile)
+		if policyErr != nil {
+			return nil, policyErr
+		}
+		if setPolicyErr := client.SetPasswordPolicy(policy); setPolicyErr != nil {
+			return nil, setPolicyErr
+		}
 	}
 	return &client, nil
 }
 
+// parseAttributeValues validates and canonicalizes attribute values for the given type.
+// For JPEG_PHOTO attributes, a value may also be the path to a JPEG file.
+func parseAttributeValues(attributeType lldap.LldapCustomAttributeType, values []string) ([]string, error) {
+	result := make([]string, len(values))
+	for i, value := range values {
+		if attributeType == lldap.AttributeTypeJpegPhoto {
+			if stat, statErr := os.Stat(value); statErr == nil && !stat.IsDir() {
+				encoded, readErr := lldap.ReadJpegPhotoFile(value)
+				if readErr != nil {
+					return nil, readErr
+				}
+				value = encoded
+			}
+		}
+		parsed, parseErr := lldap.ParseAttributeValue(attributeType, value)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		result[i] = parsed.Canonical
+	}
+	return result, nil
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "lldap-cli",
 	Short: "Basic client CLI to interact with a LLDAP server",
 	Long: `lldap-cli is a command line tool for interacting with an LLDAP server.
 
 The following environment variables are supported by all subcommands:
-- LLDAP_USER      (optional, default: 'admin', username for the administrative user)
-- LLDAP_PASSWORD  (required, password for the administrative user)
-- LLDAP_BASE_DN   (required, LDAP base DN in the format 'dc=example,dc=com')
-- LLDAP_HTTP_URL  (required, HTTP URL in the format 'http[s]://(hostname)[:port]')
-- LLDAP_LDAP_URL  (required, LDAP URL in the format 'ldap[s]://(hostname)[:port]')
-- INSECURE_CERT   (optional, default: 'false', skip cert check for HTTPS connections)`,
+- LLDAP_USER              (optional, default: 'admin' or the user of the token, username for the administrative user)
+- LLDAP_PASSWORD          (required unless one of the following is set, password for the administrative user)
+- LLDAP_PASSWORD_FILE     (optional, file containing the password)
+- LLDAP_PASSWORD_COMMAND  (optional, shell command printing the password)
+- LLDAP_TOKEN             (optional, pre-issued JWT used instead of the password, LLDAP_USER is taken from it)
+- LLDAP_REFRESH_TOKEN     (optional, refresh token used to get tokens instead of the password)
+- LLDAP_BASE_DN           (required, LDAP base DN in the format 'dc=example,dc=com')
+- LLDAP_HTTP_URL          (required, HTTP URL in the format 'http[s]://(hostname)[:port]')
+- LLDAP_LDAP_URL          (required, LDAP URL in the format 'ldap[s]://(hostname)[:port]')
+- INSECURE_CERT           (optional, default: 'false', skip cert check for HTTPS connections)
+- LLDAP_PASSWORD_POLICY_FILE  (optional, JSON file with the keys of the provider's password_policy block, checked before passwords are set)`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		if cmd.CalledAs() == "help" || cmd.Flags().Lookup("help").Changed {
 			return nil
@@ -146,8 +196,22 @@ var userCmds = map[string]*cobra.Command{
 		SilenceErrors: true,
 		SilenceUsage:  true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 1 {
-				user, getErr := lc.GetUser(args[0])
+			email, _ := cmd.Flags().GetString("email")
+			uuid, _ := cmd.Flags().GetString("uuid")
+			if len(args) == 1 && (email != "" || uuid != "") {
+				return fmt.Errorf("uid cannot be combined with --email or --uuid")
+			}
+			if len(args) == 1 || email != "" || uuid != "" {
+				var user *lldap.LldapUser
+				var getErr diag.Diagnostics
+				switch {
+				case email != "":
+					user, getErr = lc.GetUserByEmail(email)
+				case uuid != "":
+					user, getErr = lc.GetUserByUuid(uuid)
+				default:
+					user, getErr = lc.GetUser(args[0])
+				}
 				if getErr != nil {
 					logger.Error("could not get user", slog.Any("error", getErr))
 					return fmt.Errorf("could not get user")
@@ -230,6 +294,26 @@ var userCmds = map[string]*cobra.Command{
 			return nil
 		},
 	},
+	"rename": {
+		Use:   "rename <uid> <new-uid>",
+		Short: "Rename an user by migrating it to a new user",
+		Long: `LLDAP cannot rename users, so this creates the new user with the profile fields,
+custom attributes and group memberships of the old one, and then deletes the old user.
+The password cannot be copied, set it with --password. The new user gets a new UUID.`,
+		Args:          cobra.ExactArgs(2),
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			password, _ := cmd.Flags().GetString("password")
+			user, renameErr := lc.RenameUser(args[0], args[1], password)
+			if renameErr != nil {
+				logger.Error("could not rename user", slog.Any("error", renameErr), slog.String("uid", args[0]))
+				return fmt.Errorf("could not rename user")
+			}
+			user.Password = ""
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(user)
+		},
+	},
 }
 
 var groupCmds = map[string]*cobra.Command{
@@ -376,6 +460,84 @@ var memberCmds = map[string]*cobra.Command{
 			return nil
 		},
 	},
+	"prune-expired": {
+		Use:           "prune-expired (--state <file> | --attribute <name>)",
+		Short:         "Remove expired memberships managed by Terraform or recorded in a DATE_TIME user attribute",
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stateFiles, _ := cmd.Flags().GetStringSlice("state")
+			attributeName, _ := cmd.Flags().GetString("attribute")
+			groupIds, _ := cmd.Flags().GetIntSlice("group")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			if len(stateFiles) == 0 && attributeName == "" {
+				return fmt.Errorf("at least one of --state or --attribute is required")
+			}
+			now := time.Now()
+			expired := make([]lldap.LldapMembership, 0)
+			for _, stateFile := range stateFiles {
+				state, readErr := os.ReadFile(stateFile)
+				if readErr != nil {
+					logger.Error("could not read state file", slog.String("file", stateFile), slog.Any("err", readErr))
+					return fmt.Errorf("could not read state file %s", stateFile)
+				}
+				fromState, parseErr := lldap.ExpiredMembershipsFromState(state, now)
+				if parseErr != nil {
+					logger.Error("could not parse state file", slog.String("file", stateFile), slog.Any("err", parseErr))
+					return fmt.Errorf("could not parse state file %s", stateFile)
+				}
+				expired = append(expired, fromState...)
+			}
+			if attributeName != "" {
+				fromAttribute, getErr := lc.GetExpiredMembershipsFromAttribute(attributeName, groupIds, now)
+				if getErr != nil {
+					logger.Error("could not get expired memberships", slog.Any("err", getErr))
+					return fmt.Errorf("could not get expired memberships")
+				}
+				expired = append(expired, fromAttribute...)
+			}
+			groupMembers := map[int][]string{}
+			for _, membership := range expired {
+				if _, ok := groupMembers[membership.GroupId]; ok {
+					continue
+				}
+				group, getErr := lc.GetGroup(membership.GroupId)
+				if getErr != nil {
+					logger.Error("could not get group", slog.Int("gid", membership.GroupId), slog.Any("err", getErr))
+					return fmt.Errorf("could not get group")
+				}
+				groupMembers[membership.GroupId] = group.GetUserIds()
+				// Removing our own admin access would break every later API call
+				if group.DisplayName == lldap.LldapAdminGroupName && slices.ContainsFunc(expired, func(m lldap.LldapMembership) bool {
+					return m.GroupId == group.Id && strings.EqualFold(m.UserId, lc.Config.UserName) && slices.Contains(groupMembers[group.Id], m.UserId)
+				}) {
+					logger.Error("refusing to remove the LLDAP_USER from the admin group", slog.Int("gid", group.Id), slog.String("uid", lc.Config.UserName))
+					return fmt.Errorf("refusing to remove %s from %s, which revokes the admin access of LLDAP_USER", lc.Config.UserName, lldap.LldapAdminGroupName)
+				}
+			}
+			pruned := make([]lldap.LldapMembership, 0)
+			for _, membership := range expired {
+				// Skip memberships that are already gone, e.g. listed in multiple sources
+				if !slices.Contains(groupMembers[membership.GroupId], membership.UserId) {
+					continue
+				}
+				if !dryRun {
+					removeErr := lc.RemoveUserFromGroup(membership.GroupId, membership.UserId)
+					if removeErr != nil {
+						logger.Error("could not remove user from group", slog.Any("err", removeErr))
+						return fmt.Errorf("could not remove user from group")
+					}
+					logger.Info("removed expired member from group", slog.Int("gid", membership.GroupId), slog.String("uid", membership.UserId))
+				}
+				groupMembers[membership.GroupId] = slices.DeleteFunc(groupMembers[membership.GroupId], func(userId string) bool {
+					return userId == membership.UserId
+				})
+				pruned = append(pruned, membership)
+			}
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(pruned)
+		},
+	},
 }
 
 var attributeCmds = map[string]*cobra.Command{
@@ -460,9 +622,25 @@ var attributeCmds = map[string]*cobra.Command{
 				return fmt.Errorf("either --user or --group must be set")
 			}
 			flagValues, _ := cmd.Flags().GetStringSlice("values")
+			var attributeType lldap.LldapCustomAttributeType
+			var getTypeErr diag.Diagnostics
+			if flagUser {
+				attributeType, getTypeErr = lc.GetUserAttributeType(attribute)
+			}
+			if flagGroup {
+				attributeType, getTypeErr = lc.GetGroupAttributeType(attribute)
+			}
+			if getTypeErr != nil {
+				logger.Error("could not get attribute type", slog.Any("err", getTypeErr), slog.String("attribute", attribute))
+				return fmt.Errorf("could not get attribute type")
+			}
+			values, parseErr := parseAttributeValues(attributeType, flagValues)
+			if parseErr != nil {
+				return parseErr
+			}
 			var addErr diag.Diagnostics
 			if flagUser {
-				addErr = lc.AddAttributeToUser(id, attribute, flagValues)
+				addErr = lc.AddAttributeToUser(id, attribute, values)
 			}
 			if flagGroup {
 				gid, invalidGid := strconv.Atoi(id)
@@ -470,7 +648,7 @@ var attributeCmds = map[string]*cobra.Command{
 					logger.Error("invalid gid", slog.Any("err", invalidGid))
 					return invalidGid
 				}
-				addErr = lc.AddAttributeToGroup(gid, attribute, flagValues)
+				addErr = lc.AddAttributeToGroup(gid, attribute, values)
 			}
 			if addErr != nil {
 				logger.Error("could not add attribute",
@@ -585,6 +763,39 @@ var attributeCmds = map[string]*cobra.Command{
 	},
 }
 
+var ldapCmds = map[string]*cobra.Command{
+	"search": {
+		Use:           "search [filter]",
+		Short:         "Search entries over the LDAP interface, the filter defaults to '" + lldap.LdapSearchDefaultFilter + "'",
+		Args:          cobra.RangeArgs(0, 1),
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := lldap.LdapSearchDefaultFilter
+			if len(args) == 1 {
+				filter = args[0]
+			}
+			baseDn, _ := cmd.Flags().GetString("base")
+			if baseDn == "" {
+				baseDn = lc.Config.BaseDn
+			}
+			scope, _ := cmd.Flags().GetString("scope")
+			attributes, _ := cmd.Flags().GetStringSlice("attributes")
+			sizeLimit, _ := cmd.Flags().GetInt("size-limit")
+			validateErr := lldap.ValidateLdapSearch(scope, filter)
+			if validateErr != nil {
+				return validateErr
+			}
+			entries, searchErr := lc.LdapSearch(baseDn, scope, filter, attributes, sizeLimit)
+			if searchErr != nil {
+				logger.Error("could not search", slog.Any("err", searchErr), slog.String("filter", filter), slog.String("base", baseDn))
+				return fmt.Errorf("could not search")
+			}
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(entries)
+		},
+	},
+}
+
 var mainCmds = map[string]*cobra.Command{
 	"user": {
 		Use:   "user",
@@ -602,6 +813,10 @@ var mainCmds = map[string]*cobra.Command{
 		Use:   "attribute",
 		Short: "Attribute operations",
 	},
+	"ldap": {
+		Use:   "ldap",
+		Short: "LDAP operations",
+	},
 }
 
 func initCmds() *cobra.Command {
@@ -615,13 +830,25 @@ func initCmds() *cobra.Command {
 		userCmds[cmdName].Flags().String("lastname", "", "Last name")
 		userCmds[cmdName].Flags().String("avatar", "", "Base 64 encoded JPEG image")
 	}
+	userCmds["get"].Flags().String("email", "", "Get the user with this email")
+	userCmds["get"].Flags().String("uuid", "", "Get the user with this UUID")
+	userCmds["get"].MarkFlagsMutuallyExclusive("email", "uuid")
+	userCmds["rename"].Flags().String("password", "", "Password of the new user")
 	groupCmds["create"].Flags().String("displayname", "", "Display name")
 	groupCmds["update"].Flags().String("displayname", "", "Display name")
+	memberCmds["prune-expired"].Flags().StringSlice("state", nil, "Terraform state file(s) with lldap_member and lldap_group_memberships resources")
+	memberCmds["prune-expired"].Flags().String("attribute", "", "DATE_TIME user attribute holding the expiry of the user's memberships")
+	memberCmds["prune-expired"].Flags().IntSlice("group", nil, "Only prune memberships of these group ids when using --attribute, all groups but lldap_admin if not set")
+	memberCmds["prune-expired"].Flags().Bool("dry-run", false, "Only print the expired memberships, do not remove them")
 	attributeCmds["create"].Flags().Bool("list", false, "Does this attribute represent a list?")
 	attributeCmds["create"].Flags().Bool("visible", true, "Is this attribute visible in LDAP?")
 	attributeCmds["create"].Flags().Bool("editable", false, "Is this attribute user editable?")
 	attributeCmds["create"].Flags().String("displayname", "", "Display name")
-	attributeCmds["add"].Flags().StringSlice("values", nil, "List of values for this attribute")
+	attributeCmds["add"].Flags().StringSlice("values", nil, "List of values for this attribute, JPEG_PHOTO values may be paths to JPEG files")
+	ldapCmds["search"].Flags().String("base", "", "DN to search below, defaults to LLDAP_BASE_DN")
+	ldapCmds["search"].Flags().String("scope", lldap.LdapSearchScopeSub, "Search scope, one of: "+strings.Join(lldap.LdapSearchScopes, ", "))
+	ldapCmds["search"].Flags().StringSlice("attributes", nil, "Attributes to return, all attributes if not set")
+	ldapCmds["search"].Flags().Int("size-limit", 0, "Maximum number of entries to return, 0 for no limit")
 	for _, cmd := range userCmds {
 		mainCmds["user"].AddCommand(cmd)
 	}
@@ -636,6 +863,9 @@ func initCmds() *cobra.Command {
 		cmd.Flags().Bool("group", false, "Handle group-specific attribute")
 		mainCmds["attribute"].AddCommand(cmd)
 	}
+	for _, cmd := range ldapCmds {
+		mainCmds["ldap"].AddCommand(cmd)
+	}
 	for _, cmd := range mainCmds {
 		rootCmd.AddCommand(cmd)
 	}
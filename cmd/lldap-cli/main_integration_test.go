@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -62,7 +63,7 @@ func integrationTestWrap(args []string) (*bytes.Buffer, *bytes.Buffer, error) {
 	// Reset all command flags to avoid "flag redefined" errors
 	if isInit {
 		// Reset flags on all commands that have them
-		for _, cmdName := range []string{"create", "update"} {
+		for _, cmdName := range []string{"create", "get", "update"} {
 			if cmd, exists := userCmds[cmdName]; exists {
 				cmd.ResetFlags()
 			}
@@ -73,9 +74,15 @@ func integrationTestWrap(args []string) (*bytes.Buffer, *bytes.Buffer, error) {
 		if cmd, exists := groupCmds["update"]; exists {
 			cmd.ResetFlags()
 		}
+		if cmd, exists := memberCmds["prune-expired"]; exists {
+			cmd.ResetFlags()
+		}
 		for _, cmd := range attributeCmds {
 			cmd.ResetFlags()
 		}
+		for _, cmd := range ldapCmds {
+			cmd.ResetFlags()
+		}
 
 		// Reset root command
 		rootCmd.ResetFlags()
@@ -126,6 +133,40 @@ func TestUserPasswordCommand(t *testing.T) {
 	client.DeleteUser(username)
 }
 
+func TestUserGetByEmailAndUuid(t *testing.T) {
+	username := randomTestSuffix("testgetbyemail")
+	email := strings.Join([]string{username, "test.local"}, "@")
+	client := getTestClient()
+
+	testUser := lldap.LldapUser{
+		Id:    username,
+		Email: email,
+	}
+	cerr := client.CreateUser(&testUser)
+	assert.Nil(t, cerr)
+
+	for _, args := range [][]string{
+		{"user", "get", "--email", email},
+		{"user", "get", "--uuid", testUser.Uuid},
+	} {
+		stdOut, stdErr, err := integrationTestWrap(args)
+		assert.Empty(t, stdErr)
+		assert.Nil(t, err)
+
+		var user lldap.LldapUser
+		err = json.Unmarshal(stdOut.Bytes(), &user)
+		assert.Nil(t, err)
+		assert.Equal(t, username, user.Id)
+	}
+
+	// Lookup flags cannot be combined
+	_, _, err := integrationTestWrap([]string{"user", "get", username, "--email", email})
+	assert.NotNil(t, err)
+
+	// Clean up
+	client.DeleteUser(username)
+}
+
 func TestGroupGetAll(t *testing.T) {
 	// Test getting all groups
 	stdOut, stdErr, err := integrationTestWrap([]string{
@@ -550,6 +591,50 @@ func TestUserDelete(t *testing.T) {
 	assert.Contains(t, diags[0].Summary, "Entity not found")
 }
 
+func TestUserRename(t *testing.T) {
+
+	username := randomTestSuffix("testuserrename")
+	newUsername := randomTestSuffix("testuserrenamed")
+	email := strings.Join([]string{username, "test.local"}, "@")
+	client := getTestClient()
+	derr := client.CreateUser(&lldap.LldapUser{Id: username, Email: email, DisplayName: "Rename Me"})
+	assert.Nil(t, derr)
+	testGroup := lldap.LldapGroup{DisplayName: randomTestSuffix("Test User Rename Group")}
+	derr = client.CreateGroup(&testGroup)
+	assert.Nil(t, derr)
+	derr = client.AddUserToGroup(testGroup.Id, username)
+	assert.Nil(t, derr)
+
+	stdOut, stdErr, err := integrationTestWrap([]string{
+		"user",
+		"rename",
+		username,
+		newUsername,
+		"--password",
+		"renamed-password",
+	})
+
+	assert.Empty(t, stdErr)
+	assert.Nil(t, err)
+	var renamedUser lldap.LldapUser
+	err = json.Unmarshal(stdOut.Bytes(), &renamedUser)
+	assert.Nil(t, err)
+	assert.Equal(t, newUsername, renamedUser.Id)
+	assert.Empty(t, renamedUser.Password)
+
+	user, diags := client.GetUser(newUsername)
+	assert.Nil(t, diags)
+	assert.Equal(t, email, user.Email)
+	assert.Equal(t, "Rename Me", user.DisplayName)
+	assert.Contains(t, user.GetGroupIds(), testGroup.Id)
+	_, diags = client.GetUser(username)
+	assert.True(t, diags.HasError())
+
+	// Clean up
+	client.DeleteUser(newUsername)
+	client.DeleteGroup(testGroup.Id)
+}
+
 func TestGroupCreate(t *testing.T) {
 
 	groupname := randomTestSuffix("testgroupcreate")
@@ -866,3 +951,108 @@ func TestAttributeRemove(t *testing.T) {
 	client.DeleteUser(username)
 	client.DeleteUserAttribute(attrName)
 }
+
+func TestMemberPruneExpired(t *testing.T) {
+	username := randomTestSuffix("testmemberpruneexpired")
+	client := getTestClient()
+
+	testGroup := lldap.LldapGroup{
+		DisplayName: randomTestSuffix("Test Member Prune Expired Group"),
+	}
+	derr := client.CreateGroup(&testGroup)
+	assert.Nil(t, derr)
+	derr = client.CreateUser(&lldap.LldapUser{Id: username, Email: username + "@test.local"})
+	assert.Nil(t, derr)
+	derr = client.AddUserToGroup(testGroup.Id, username)
+	assert.Nil(t, derr)
+
+	stateFile := filepath.Join(t.TempDir(), "terraform.tfstate")
+	state := fmt.Sprintf(`{"version": 4, "resources": [{"mode": "managed", "type": "lldap_member", "instances": [
+		{"attributes": {"group_id": %d, "user_id": "%s", "expires_at": "2000-01-01T00:00:00Z"}}
+	]}]}`, testGroup.Id, username)
+	assert.Nil(t, os.WriteFile(stateFile, []byte(state), 0600))
+
+	// A dry run must not remove anything
+	stdOut, _, err := integrationTestWrap([]string{"member", "prune-expired", "--state", stateFile, "--dry-run"})
+	assert.Nil(t, err)
+	var pruned []lldap.LldapMembership
+	assert.Nil(t, json.Unmarshal(stdOut.Bytes(), &pruned))
+	assert.Len(t, pruned, 1)
+	group, derr := client.GetGroup(testGroup.Id)
+	assert.Nil(t, derr)
+	assert.Contains(t, group.GetUserIds(), username)
+
+	stdOut, _, err = integrationTestWrap([]string{"member", "prune-expired", "--state", stateFile})
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(stdOut.Bytes(), &pruned))
+	assert.Equal(t, []lldap.LldapMembership{{GroupId: testGroup.Id, UserId: username, ExpiresAt: "2000-01-01T00:00:00Z"}}, pruned)
+	group, derr = client.GetGroup(testGroup.Id)
+	assert.Nil(t, derr)
+	assert.NotContains(t, group.GetUserIds(), username)
+
+	// Clean up
+	client.DeleteUser(username)
+	client.DeleteGroup(testGroup.Id)
+}
+
+func TestMemberPruneExpiredLockout(t *testing.T) {
+	client := getTestClient()
+	groups, derr := client.GetGroups()
+	assert.Nil(t, derr)
+	adminGroupId := -1
+	for _, group := range groups {
+		if group.DisplayName == lldap.LldapAdminGroupName {
+			adminGroupId = group.Id
+		}
+	}
+	assert.NotEqual(t, -1, adminGroupId)
+
+	stateFile := filepath.Join(t.TempDir(), "terraform.tfstate")
+	state := fmt.Sprintf(`{"version": 4, "resources": [{"mode": "managed", "type": "lldap_member", "instances": [
+		{"attributes": {"group_id": %d, "user_id": "%s", "expires_at": "2000-01-01T00:00:00Z"}}
+	]}]}`, adminGroupId, client.Config.UserName)
+	assert.Nil(t, os.WriteFile(stateFile, []byte(state), 0600))
+
+	// Removing the CLI user from lldap_admin is refused
+	_, _, err := integrationTestWrap([]string{"member", "prune-expired", "--state", stateFile})
+	assert.NotNil(t, err)
+	group, derr := client.GetGroup(adminGroupId)
+	assert.Nil(t, derr)
+	assert.Contains(t, group.GetUserIds(), client.Config.UserName)
+}
+
+func TestLdapSearch(t *testing.T) {
+	username := randomTestSuffix("testldapsearch")
+	client := getTestClient()
+	testUser := lldap.LldapUser{
+		Id:    username,
+		Email: username + "@test.local",
+	}
+	assert.Nil(t, client.CreateUser(&testUser))
+
+	stdOut, stdErr, err := integrationTestWrap([]string{
+		"ldap",
+		"search",
+		"(uid=" + username + ")",
+		"--attributes", "uid,mail",
+	})
+	assert.Empty(t, stdErr)
+	assert.Nil(t, err)
+
+	var entries []lldap.LdapSearchEntry
+	assert.Nil(t, json.Unmarshal(stdOut.Bytes(), &entries))
+	assert.Len(t, entries, 1)
+	if len(entries) == 1 {
+		assert.Equal(t, []string{username}, entries[0].Attributes["uid"])
+	}
+
+	_, _, scopeErr := integrationTestWrap([]string{
+		"ldap",
+		"search",
+		"--scope", "subtree",
+	})
+	assert.ErrorContains(t, scopeErr, "invalid scope")
+
+	// Clean up
+	client.DeleteUser(username)
+}
@@ -19,7 +19,7 @@ func testWrap(args []string) (*bytes.Buffer, *bytes.Buffer, error) {
 	// Reset all command flags to avoid "flag redefined" errors
 	if isInit {
 		// Reset flags on all commands that have them
-		for _, cmdName := range []string{"create", "update"} {
+		for _, cmdName := range []string{"create", "get", "update", "rename"} {
 			if cmd, exists := userCmds[cmdName]; exists {
 				cmd.ResetFlags()
 			}
@@ -30,9 +30,15 @@ func testWrap(args []string) (*bytes.Buffer, *bytes.Buffer, error) {
 		if cmd, exists := groupCmds["update"]; exists {
 			cmd.ResetFlags()
 		}
+		if cmd, exists := memberCmds["prune-expired"]; exists {
+			cmd.ResetFlags()
+		}
 		for _, cmd := range attributeCmds {
 			cmd.ResetFlags()
 		}
+		for _, cmd := range ldapCmds {
+			cmd.ResetFlags()
+		}
 
 		// Reset root command
 		rootCmd.ResetFlags()
@@ -107,3 +113,38 @@ func TestGroupGetInvalidGid(t *testing.T) {
 	assert.True(t, hasValidationError || hasConnectionError,
 		"Should fail with either validation error or connection error, got: %s", errorMsg)
 }
+
+func TestLdapSearchInvalidArguments(t *testing.T) {
+	// Test ldap search with more than one filter
+	_, stdErr, err := testWrap([]string{
+		"ldap",
+		"search",
+		"(uid=admin)",
+		"(uid=other)",
+	})
+
+	var errorMsg string
+	if err != nil {
+		errorMsg = err.Error()
+	} else {
+		errorMsg = stdErr.String()
+	}
+	assert.Contains(t, errorMsg, "accepts between 0 and 1 arg(s), received 2")
+}
+
+func TestUserRenameInvalidArguments(t *testing.T) {
+	// Test user rename without the new user ID
+	_, stdErr, err := testWrap([]string{
+		"user",
+		"rename",
+		"someone",
+	})
+
+	var errorMsg string
+	if err != nil {
+		errorMsg = err.Error()
+	} else {
+		errorMsg = stdErr.String()
+	}
+	assert.Contains(t, errorMsg, "accepts 2 arg(s), received 1")
+}
@@ -2,3 +2,8 @@
 data "lldap_group" "lldap_admin" {
   id = 1
 }
+
+# Get a group by its display name instead of its id
+data "lldap_group" "developers" {
+  display_name = "developers"
+}
@@ -0,0 +1,4 @@
+# Get all effective members of the "platform" group, including members of included groups
+data "lldap_group_inclusion" "platform" {
+  group_id = 3
+}
@@ -0,0 +1,18 @@
+locals {
+  base_dn = "dc=example,dc=com"
+  # The filter configured in the LDAP authentication source of an app, e.g. Gitea
+  gitea_filter = "(&(objectClass=person)(uid=%s)${provider::lldap::member_of_filter(["gitea_users"], local.base_dn)})"
+}
+
+data "lldap_ldap_search" "gitea_users" {
+  base_dn    = "ou=people,${local.base_dn}"
+  filter     = replace(local.gitea_filter, "%s", "*")
+  attributes = ["uid", "mail"]
+}
+
+check "gitea_users" {
+  assert {
+    condition     = contains(flatten(data.lldap_ldap_search.gitea_users.entries[*].attributes.uid), "jane")
+    error_message = "The Gitea filter must match jane"
+  }
+}
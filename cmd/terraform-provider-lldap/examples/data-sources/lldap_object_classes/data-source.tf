@@ -0,0 +1,2 @@
+# Read all extra object classes for users and groups
+data "lldap_object_classes" "this" {}
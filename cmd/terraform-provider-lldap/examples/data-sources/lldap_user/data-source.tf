@@ -1,4 +1,13 @@
 # Get the default admin user
 data "lldap_user" "admin" {
   id = "admin"
-}
\ No newline at end of file
+}
+
+# Get a user by email or UUID instead of its id
+data "lldap_user" "by_email" {
+  email = "jane.doe@example.com"
+}
+
+data "lldap_user" "by_uuid" {
+  uuid = "1b4e28ba-2fa1-11d2-883f-0016d3cca427"
+}
@@ -0,0 +1,4 @@
+# cn=Doe\, John,ou=contacts,dc=example,dc=com
+output "contact_dn" {
+  value = "cn=${provider::lldap::escape_dn_value("Doe, John")},ou=contacts,dc=example,dc=com"
+}
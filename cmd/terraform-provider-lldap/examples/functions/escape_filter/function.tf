@@ -0,0 +1,4 @@
+# (&(objectClass=person)(mail=\2a@example.com))
+output "mail_filter" {
+  value = "(&(objectClass=person)(mail=${provider::lldap::escape_filter("*@example.com")}))"
+}
@@ -0,0 +1,12 @@
+locals {
+  base_dn = "dc=example,dc=com"
+}
+
+resource "lldap_group" "example" {
+  display_name = "R&D, Europe"
+}
+
+# cn=R&D\, Europe,ou=groups,dc=example,dc=com
+output "group_dn" {
+  value = provider::lldap::group_dn(lldap_group.example.display_name, local.base_dn)
+}
@@ -0,0 +1,8 @@
+locals {
+  base_dn = "dc=example,dc=com"
+}
+
+# (&(objectClass=person)(|(memberOf=cn=admins,ou=groups,dc=example,dc=com)(memberOf=cn=ops,ou=groups,dc=example,dc=com)))
+output "user_filter" {
+  value = "(&(objectClass=person)${provider::lldap::member_of_filter(["admins", "ops"], local.base_dn)})"
+}
@@ -0,0 +1,8 @@
+locals {
+  base_dn = "dc=example,dc=com"
+}
+
+# uid=jane\,doe,ou=people,dc=example,dc=com
+output "user_dn" {
+  value = provider::lldap::user_dn("jane,doe", local.base_dn)
+}
@@ -0,0 +1,5 @@
+# A dynamic group can be imported by specifying the group ID.
+terraform import lldap_dynamic_group.example 3
+
+# Alternatively, the group can be specified by its display name, prefixed with `name:`
+terraform import lldap_dynamic_group.example name:everyone-in-engineering
@@ -0,0 +1,14 @@
+resource "lldap_user_attribute" "department" {
+  name           = "department"
+  attribute_type = "STRING"
+}
+
+resource "lldap_group" "engineering" {
+  display_name = "everyone-in-engineering"
+}
+
+# All users in the engineering department, except contractors
+resource "lldap_dynamic_group" "engineering" {
+  group_id = lldap_group.engineering.id
+  filter   = "(&(department=engineering)(!(mail=*@contractor.example.com)))"
+}
@@ -1,2 +1,5 @@
 # A group can be imported by specifying the numeric group id.
 terraform import lldap_group.example 1
+
+# Alternatively, a group can be imported by its display name, prefixed with `name:`
+terraform import lldap_group.example name:developers
@@ -1,3 +1,6 @@
 # A group attribute assignment can be imported by combining the group id
 # with the attribute name, separated by a colon, i.e. `1:myattribute`
-terraform import lldap_user_attribute_assignment.example 1:myattribute
+terraform import lldap_group_attribute_assignment.example 1:myattribute
+
+# A colon or percent sign in the attribute name must be escaped as `%3A` or `%25`
+terraform import lldap_group_attribute_assignment.example 1:my%3Aattribute
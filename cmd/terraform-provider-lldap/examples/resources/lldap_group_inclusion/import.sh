@@ -0,0 +1,5 @@
+# A group inclusion can be imported by specifying the parent group ID.
+terraform import lldap_group_inclusion.example 3
+
+# Alternatively, the parent group can be specified by its display name, prefixed with `name:`
+terraform import lldap_group_inclusion.example name:platform
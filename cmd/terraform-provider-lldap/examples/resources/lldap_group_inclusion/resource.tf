@@ -0,0 +1,17 @@
+resource "lldap_group" "platform" {
+  display_name = "platform"
+}
+
+resource "lldap_group" "sre" {
+  display_name = "sre"
+}
+
+resource "lldap_group" "dbas" {
+  display_name = "dbas"
+}
+
+# Everyone in "sre" and "dbas" is also a member of "platform"
+resource "lldap_group_inclusion" "platform" {
+  parent_group_id = lldap_group.platform.id
+  child_group_ids = [lldap_group.sre.id, lldap_group.dbas.id]
+}
@@ -1,2 +1,5 @@
 # A group's memberships can be imported by specifying the group ID.
 terraform import lldap_group_memberships.example 3
+
+# Alternatively, the group can be specified by its display name, prefixed with `name:`
+terraform import lldap_group_memberships.example name:developers
@@ -11,4 +11,9 @@ resource "lldap_user" "user" {
 resource "lldap_group_memberships" "group" {
   group_id = lldap_group.group.id
   user_ids = toset([ for user in lldap_user.user : user.id ])
+
+  # user-9 is removed from the group after this time
+  expires_at = {
+    (lldap_user.user[9].id) = "2030-12-31T23:59:59Z"
+  }
 }
@@ -0,0 +1,2 @@
+# Import a group object class by specifying its name
+terraform import lldap_group_object_class.example posixGroup
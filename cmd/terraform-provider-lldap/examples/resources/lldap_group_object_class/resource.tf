@@ -0,0 +1,4 @@
+# Add the posixGroup object class to all groups
+resource "lldap_group_object_class" "posix" {
+  name = "posixGroup"
+}
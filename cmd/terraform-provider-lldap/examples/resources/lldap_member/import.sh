@@ -1,3 +1,9 @@
 # A membership can be imported by specifying the numeric group id and the
 # username string separated by a colon, i.e. `group_id:user_id`
 terraform import lldap_member.example 1:admin
+
+# Alternatively, the group can be specified by its display name, prefixed with `name:`
+terraform import lldap_member.example name:lldap_admin:admin
+
+# A colon or percent sign in the user id must be escaped as `%3A` or `%25`
+terraform import lldap_member.example 1:we%3Aird
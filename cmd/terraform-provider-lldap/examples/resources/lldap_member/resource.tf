@@ -13,3 +13,15 @@ resource "lldap_member" "test_admin" {
   group_id = lldap_group.test_admin.id
   user_id  = data.lldap_user.admin.id
 }
+
+# Contractors only keep their membership until the end of their contract
+resource "lldap_user" "contractor" {
+  username = "contractor"
+  email    = "contractor@example.com"
+}
+
+resource "lldap_member" "contractor" {
+  group_id   = lldap_group.test_admin.id
+  user_id    = lldap_user.contractor.id
+  expires_at = "2030-12-31T23:59:59Z"
+}
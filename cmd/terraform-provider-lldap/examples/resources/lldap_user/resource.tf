@@ -7,4 +7,11 @@ resource "lldap_user" "user" {
   first_name   = "My"
   last_name    = "User"
   avatar       = filebase64("${path.module}/myuser.jpeg")
-}
\ No newline at end of file
+}
+# Manage an user whose password is never stored in the plan or state
+resource "lldap_user" "write_only" {
+  username            = "otheruser"
+  email               = "otheruser@in.the.test"
+  password_wo         = var.otheruser_password
+  password_wo_version = 1
+}
@@ -1,3 +1,6 @@
 # An user attribute assignment can be imported by combining the user id
 # with the attribute name, separated by a colon, i.e. `myuser:myattribute`
 terraform import lldap_user_attribute_assignment.example admin:myattribute
+
+# A colon or percent sign in the user id or attribute name must be escaped as `%3A` or `%25`
+terraform import lldap_user_attribute_assignment.example we%3Aird:myattribute
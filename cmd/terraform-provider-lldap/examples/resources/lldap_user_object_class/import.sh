@@ -0,0 +1,2 @@
+# Import an user object class by specifying its name
+terraform import lldap_user_object_class.example posixAccount
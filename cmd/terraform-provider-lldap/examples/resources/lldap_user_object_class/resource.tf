@@ -0,0 +1,4 @@
+# Add the posixAccount object class to all users
+resource "lldap_user_object_class" "posix" {
+  name = "posixAccount"
+}
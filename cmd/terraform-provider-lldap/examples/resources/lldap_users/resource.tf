@@ -0,0 +1,13 @@
+locals {
+  people = {
+    alice = { email = "alice@this.test", first_name = "Alice" }
+    bob   = { email = "bob@this.test", display_name = "Bob B." }
+  }
+}
+
+resource "lldap_users" "people" {
+  users = local.people
+
+  # Also delete all users not listed above, except the provider user
+  authoritative = true
+}
@@ -7,12 +7,21 @@
 package main
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"context"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
 	lldap "github.com/tasansga/terraform-provider-lldap/lldap"
 )
 
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: lldap.Provider,
-	})
+	ctx := context.Background()
+	providerServer, providerServerErr := lldap.ProviderServer(ctx)
+	if providerServerErr != nil {
+		log.Fatal(providerServerErr)
+	}
+	serveErr := tf6server.Serve("registry.terraform.io/tasansga/lldap", providerServer)
+	if serveErr != nil {
+		log.Fatal(serveErr)
+	}
 }
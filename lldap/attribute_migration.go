@@ -0,0 +1,234 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var migrateValuesSchema = schema.Schema{
+	Type:        schema.TypeBool,
+	Optional:    true,
+	Description: "Changes the attribute type, `is_list` and the other settings in place instead of replacing the attribute, which deletes its values on every user or group. LLDAP cannot change a schema, so any of these changes, even of `is_visible` or `is_editable` alone, still deletes and recreates the schema and writes the values back. The values are converted, e.g. a single value into a one-element list or STRING into INTEGER, and the plan fails if one cannot be, or while `deletion_protection` is `true` (default: `false`)",
+}
+
+// attributeMigrationCustomizeDiff replaces the attribute schema when one of the fields changes, unless
+// migrate_values is set. Then the values are converted at plan time, so values that cannot be fail the plan.
+// Migrating deletes the schema, so it is refused while the attribute has deletion protection.
+func attributeMigrationCustomizeDiff(kind string, fields []string, attributeValues func(lc *LldapClient, name string) (map[string][]string, diag.Diagnostics)) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, m any) error {
+		if d.Id() == "" || d.HasChange("name") || !d.HasChanges(fields...) {
+			return nil
+		}
+		if !d.Get("migrate_values").(bool) {
+			for _, field := range fields {
+				if d.HasChange(field) {
+					if forceNewErr := d.ForceNew(field); forceNewErr != nil {
+						return forceNewErr
+					}
+				}
+			}
+			return nil
+		}
+		if deletionProtection, _ := d.GetChange("deletion_protection"); deletionProtection.(bool) {
+			return fmt.Errorf("cannot migrate %s attribute '%s' while deletion_protection is enabled, as migrating deletes and recreates its schema. Set deletion_protection = false and apply before changing it", kind, d.Id())
+		}
+		lc, ok := m.(*LldapClient)
+		if !ok || !d.NewValueKnown("attribute_type") || !d.NewValueKnown("is_list") {
+			return nil
+		}
+		snapshot, snapshotErr := attributeValues(lc, d.Id())
+		if snapshotErr != nil {
+			return fmt.Errorf("could not read the values of %s attribute '%s': %s", kind, d.Id(), snapshotErr[0].Summary)
+		}
+		toType := LldapCustomAttributeType(d.Get("attribute_type").(string))
+		_, convertErr := convertAttributeSnapshot(kind, d.Id(), toType, d.Get("is_list").(bool), snapshot)
+		return convertErr
+	}
+}
+
+// ConvertAttributeValues converts the values of one user or group to a new attribute type and
+// list-ness. A scalar becomes a one-element list, a list only becomes a scalar if it has at
+// most one value. Values converted to STRING are kept as they are, values converted to any
+// other type must be valid for it and are written in their canonical form.
+func ConvertAttributeValues(toType LldapCustomAttributeType, toList bool, values []string) ([]string, error) {
+	if !toList && len(values) > 1 {
+		return nil, fmt.Errorf("%d values cannot be converted to a single value", len(values))
+	}
+	converted := make([]string, 0, len(values))
+	for _, value := range values {
+		if toType == AttributeTypeString {
+			converted = append(converted, value)
+			continue
+		}
+		parsed, parseErr := ParseAttributeValue(toType, value)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		converted = append(converted, parsed.Canonical)
+	}
+	return converted, nil
+}
+
+// convertAttributeSnapshot converts the values of every user or group, keyed by their id, and
+// reports every value that cannot be converted.
+func convertAttributeSnapshot(kind string, name string, toType LldapCustomAttributeType, toList bool, snapshot map[string][]string) (map[string][]string, error) {
+	converted := make(map[string][]string, len(snapshot))
+	var failures []string
+	for _, id := range slices.Sorted(maps.Keys(snapshot)) {
+		values, convertErr := ConvertAttributeValues(toType, toList, snapshot[id])
+		if convertErr != nil {
+			failures = append(failures, fmt.Sprintf("%s '%s': %s", kind, id, convertErr))
+			continue
+		}
+		converted[id] = values
+	}
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("values of %s attribute '%s' cannot be migrated to %s (is_list = %t): %s",
+			kind, name, toType, toList, strings.Join(failures, "; "))
+	}
+	return converted, nil
+}
+
+// attributeSnapshotDetail lists the values from before a migration, so they can be restored by
+// hand if writing them back fails.
+func attributeSnapshotDetail(snapshot map[string][]string) string {
+	content, _ := json.Marshal(snapshot)
+	return fmt.Sprintf("Values before the migration: %s", content)
+}
+
+// UserAttributeValues returns the values of the user attribute, keyed by the ids of the users that have it.
+func (lc *LldapClient) UserAttributeValues(name string) (map[string][]string, diag.Diagnostics) {
+	users, getUsersErr := lc.GetUsersWithAttributes()
+	if getUsersErr != nil {
+		return nil, getUsersErr
+	}
+	snapshot := map[string][]string{}
+	for _, user := range users {
+		for _, attribute := range user.Attributes {
+			if attribute.Name == name {
+				snapshot[user.Id] = attribute.Value
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// GroupAttributeValues returns the values of the group attribute, keyed by the ids of the groups that have it.
+func (lc *LldapClient) GroupAttributeValues(name string) (map[string][]string, diag.Diagnostics) {
+	groups, getGroupsErr := lc.GetGroupsWithAttributes()
+	if getGroupsErr != nil {
+		return nil, getGroupsErr
+	}
+	snapshot := map[string][]string{}
+	for _, group := range groups {
+		for _, attribute := range group.Attributes {
+			if attribute.Name == name {
+				snapshot[strconv.Itoa(group.Id)] = attribute.Value
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// MigrateUserAttribute changes an existing user attribute schema in place. LLDAP cannot change a
+// schema, so the values of all users are converted first, failing before any change if one cannot
+// be, then the schema is recreated and the converted values are written back.
+func (lc *LldapClient) MigrateUserAttribute(target LldapUserAttributeSchema) diag.Diagnostics {
+	current, getSchemaErr := lc.GetUserAttributeSchema(target.Name)
+	if getSchemaErr != nil {
+		return getSchemaErr
+	}
+	if current == nil {
+		return diag.Errorf("user attribute '%s' does not exist", target.Name)
+	}
+	snapshot, snapshotErr := lc.UserAttributeValues(target.Name)
+	if snapshotErr != nil {
+		return snapshotErr
+	}
+	converted, convertErr := convertAttributeSnapshot("user", target.Name, target.AttributeType, target.IsList, snapshot)
+	if convertErr != nil {
+		return diag.FromErr(convertErr)
+	}
+	if deleteErr := lc.DeleteUserAttribute(target.Name); deleteErr != nil {
+		return deleteErr
+	}
+	if createErr := lc.CreateUserAttribute(target.Name, target.AttributeType, target.IsList, target.IsVisible, target.IsEditable); createErr != nil {
+		// Put the old schema and values back, so the failed migration changes nothing
+		if restoreErr := lc.CreateUserAttribute(current.Name, current.AttributeType, current.IsList, current.IsVisible, current.IsEditable); restoreErr != nil {
+			return withSnapshotDetail(append(createErr, restoreErr...), snapshot)
+		}
+		return append(createErr, lc.writeUserAttributeValues(target.Name, snapshot, snapshot)...)
+	}
+	return lc.writeUserAttributeValues(target.Name, converted, snapshot)
+}
+
+// MigrateGroupAttribute changes an existing group attribute schema in place, like MigrateUserAttribute.
+func (lc *LldapClient) MigrateGroupAttribute(target LldapGroupAttributeSchema) diag.Diagnostics {
+	current, getSchemaErr := lc.GetGroupAttributeSchema(target.Name)
+	if getSchemaErr != nil {
+		return getSchemaErr
+	}
+	if current == nil {
+		return diag.Errorf("group attribute '%s' does not exist", target.Name)
+	}
+	snapshot, snapshotErr := lc.GroupAttributeValues(target.Name)
+	if snapshotErr != nil {
+		return snapshotErr
+	}
+	converted, convertErr := convertAttributeSnapshot("group", target.Name, target.AttributeType, target.IsList, snapshot)
+	if convertErr != nil {
+		return diag.FromErr(convertErr)
+	}
+	if deleteErr := lc.DeleteGroupAttribute(target.Name); deleteErr != nil {
+		return deleteErr
+	}
+	if createErr := lc.CreateGroupAttribute(target.Name, target.AttributeType, target.IsList, target.IsVisible); createErr != nil {
+		// Put the old schema and values back, so the failed migration changes nothing
+		if restoreErr := lc.CreateGroupAttribute(current.Name, current.AttributeType, current.IsList, current.IsVisible); restoreErr != nil {
+			return withSnapshotDetail(append(createErr, restoreErr...), snapshot)
+		}
+		return append(createErr, lc.writeGroupAttributeValues(target.Name, snapshot, snapshot)...)
+	}
+	return lc.writeGroupAttributeValues(target.Name, converted, snapshot)
+}
+
+func (lc *LldapClient) writeUserAttributeValues(name string, values map[string][]string, snapshot map[string][]string) diag.Diagnostics {
+	_, writeErr := runConcurrently(lc, slices.Sorted(maps.Keys(values)), func(userId string) diag.Diagnostics {
+		return prefixDiagnostics(fmt.Sprintf("Writing back attribute '%s' of user '%s'", name, userId),
+			lc.AddAttributeToUser(userId, name, values[userId]))
+	})
+	return withSnapshotDetail(writeErr, snapshot)
+}
+
+func (lc *LldapClient) writeGroupAttributeValues(name string, values map[string][]string, snapshot map[string][]string) diag.Diagnostics {
+	_, writeErr := runConcurrently(lc, slices.Sorted(maps.Keys(values)), func(groupId string) diag.Diagnostics {
+		id, _ := strconv.Atoi(groupId)
+		return prefixDiagnostics(fmt.Sprintf("Writing back attribute '%s' of group %d", name, id),
+			lc.AddAttributeToGroup(id, name, values[groupId]))
+	})
+	return withSnapshotDetail(writeErr, snapshot)
+}
+
+// withSnapshotDetail adds the values from before the migration to errors, as they are lost from LLDAP.
+func withSnapshotDetail(diags diag.Diagnostics, snapshot map[string][]string) diag.Diagnostics {
+	for i := range diags {
+		if diags[i].Severity == diag.Error {
+			diags[i].Detail = strings.TrimSpace(diags[i].Detail + "\n" + attributeSnapshotDetail(snapshot))
+		}
+	}
+	return diags
+}
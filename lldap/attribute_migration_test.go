@@ -0,0 +1,63 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertAttributeValuesListness(t *testing.T) {
+	toList, toListErr := ConvertAttributeValues(AttributeTypeString, true, []string{"a"})
+	assert.Nil(t, toListErr)
+	assert.Equal(t, []string{"a"}, toList)
+	toScalar, toScalarErr := ConvertAttributeValues(AttributeTypeString, false, []string{"a"})
+	assert.Nil(t, toScalarErr)
+	assert.Equal(t, []string{"a"}, toScalar)
+	_, tooManyErr := ConvertAttributeValues(AttributeTypeString, false, []string{"a", "b"})
+	assert.ErrorContains(t, tooManyErr, "2 values cannot be converted to a single value")
+}
+
+func TestConvertAttributeValuesType(t *testing.T) {
+	integers, integerErr := ConvertAttributeValues(AttributeTypeInteger, true, []string{"007", " 42"})
+	assert.Nil(t, integerErr)
+	assert.Equal(t, []string{"7", "42"}, integers)
+	_, invalidErr := ConvertAttributeValues(AttributeTypeInteger, true, []string{"7", "seven"})
+	assert.ErrorContains(t, invalidErr, "invalid INTEGER value 'seven'")
+	dates, dateErr := ConvertAttributeValues(AttributeTypeDateTime, false, []string{"2024-01-01T01:00:00+01:00"})
+	assert.Nil(t, dateErr)
+	assert.Equal(t, []string{"2024-01-01T00:00:00Z"}, dates)
+	kept, keptErr := ConvertAttributeValues(AttributeTypeString, false, []string{"007"})
+	assert.Nil(t, keptErr)
+	assert.Equal(t, []string{"007"}, kept)
+}
+
+func TestConvertAttributeSnapshot(t *testing.T) {
+	converted, convertErr := convertAttributeSnapshot("user", "level", AttributeTypeInteger, false, map[string][]string{
+		"alice": {"01"},
+		"bob":   {"2"},
+	})
+	assert.Nil(t, convertErr)
+	assert.Equal(t, map[string][]string{"alice": {"1"}, "bob": {"2"}}, converted)
+	_, failedErr := convertAttributeSnapshot("user", "level", AttributeTypeInteger, false, map[string][]string{
+		"alice": {"high"},
+		"bob":   {"2"},
+		"carol": {"1", "2"},
+	})
+	assert.EqualError(t, failedErr, "values of user attribute 'level' cannot be migrated to INTEGER (is_list = false): "+
+		"user 'alice': invalid INTEGER value 'high': strconv.ParseInt: parsing \"high\": invalid syntax; "+
+		"user 'carol': 2 values cannot be converted to a single value")
+}
+
+func TestWithSnapshotDetail(t *testing.T) {
+	diags := withSnapshotDetail(prefixDiagnostics("Writing back", diag.Errorf("failed")), map[string][]string{"alice": {"1"}})
+	assert.Equal(t, "Writing back: failed", diags[0].Summary)
+	assert.Equal(t, `Values before the migration: {"alice":["1"]}`, diags[0].Detail)
+	assert.Nil(t, withSnapshotDetail(nil, map[string][]string{"alice": {"1"}}))
+}
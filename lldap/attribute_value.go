@@ -0,0 +1,157 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+const (
+	AttributeTypeDateTime  LldapCustomAttributeType = "DATE_TIME"
+	AttributeTypeInteger   LldapCustomAttributeType = "INTEGER"
+	AttributeTypeJpegPhoto LldapCustomAttributeType = "JPEG_PHOTO"
+	AttributeTypeString    LldapCustomAttributeType = "STRING"
+)
+
+// Every JPEG file starts with the SOI marker followed by the first segment marker
+var jpegMagicBytes = []byte{0xFF, 0xD8, 0xFF}
+
+// LldapAttributeValue is a single custom attribute value, parsed according to
+// the type of its attribute schema. Canonical holds the representation that is
+// used to compare values, e.g. `2024-01-01T00:00:00+00:00` and
+// `2024-01-01T00:00:00Z` share the same canonical DATE_TIME value.
+type LldapAttributeValue struct {
+	AttributeType LldapCustomAttributeType
+	Raw           string
+	Canonical     string
+}
+
+func ParseAttributeValue(attributeType LldapCustomAttributeType, raw string) (*LldapAttributeValue, error) {
+	canonical, canonicalErr := canonicalAttributeValue(attributeType, raw)
+	if canonicalErr != nil {
+		return nil, canonicalErr
+	}
+	return &LldapAttributeValue{
+		AttributeType: attributeType,
+		Raw:           raw,
+		Canonical:     canonical,
+	}, nil
+}
+
+func canonicalAttributeValue(attributeType LldapCustomAttributeType, raw string) (string, error) {
+	switch attributeType {
+	case AttributeTypeDateTime:
+		parsed, parseErr := time.Parse(time.RFC3339Nano, strings.TrimSpace(raw))
+		if parseErr != nil {
+			return "", fmt.Errorf("invalid DATE_TIME value '%s', expected RFC 3339 timestamp: %s", raw, parseErr)
+		}
+		return parsed.UTC().Format(time.RFC3339Nano), nil
+	case AttributeTypeInteger:
+		parsed, parseErr := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if parseErr != nil {
+			return "", fmt.Errorf("invalid INTEGER value '%s': %s", raw, parseErr)
+		}
+		return strconv.FormatInt(parsed, 10), nil
+	case AttributeTypeJpegPhoto:
+		decoded, decodeErr := decodeJpegPhoto(raw)
+		if decodeErr != nil {
+			return "", decodeErr
+		}
+		return base64.StdEncoding.EncodeToString(decoded), nil
+	case AttributeTypeString:
+		return raw, nil
+	}
+	return "", fmt.Errorf("invalid value for attribute type: '%s' Valid values are: %s", attributeType, strings.Join(VALID_ATTRIBUTE_TYPES[:], ", "))
+}
+
+func decodeJpegPhoto(raw string) ([]byte, error) {
+	compact := strings.Join(strings.Fields(raw), "")
+	decoded, decodeErr := base64.StdEncoding.DecodeString(compact)
+	if decodeErr != nil {
+		decoded, decodeErr = base64.RawStdEncoding.DecodeString(strings.TrimRight(compact, "="))
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("invalid JPEG_PHOTO value, expected base 64 encoded JPEG image: %s", decodeErr)
+	}
+	if !bytes.HasPrefix(decoded, jpegMagicBytes) {
+		return nil, fmt.Errorf("invalid JPEG_PHOTO value, decoded data is not a JPEG image")
+	}
+	return decoded, nil
+}
+
+// CanonicalAttributeValues validates all values for the given attribute type
+// and returns their canonical representations, sorted and without duplicates.
+func CanonicalAttributeValues(attributeType LldapCustomAttributeType, values []string) ([]string, error) {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		parsed, parseErr := ParseAttributeValue(attributeType, value)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		result = append(result, parsed.Canonical)
+	}
+	slices.Sort(result)
+	return slices.Compact(result), nil
+}
+
+// AttributeValuesEquivalent reports whether both value sets are equal after
+// canonicalization. Values that cannot be parsed are compared as-is.
+func AttributeValuesEquivalent(attributeType LldapCustomAttributeType, a []string, b []string) bool {
+	canonicalA, errA := CanonicalAttributeValues(attributeType, a)
+	canonicalB, errB := CanonicalAttributeValues(attributeType, b)
+	if errA != nil || errB != nil {
+		canonicalA = slices.Compact(slices.Sorted(slices.Values(a)))
+		canonicalB = slices.Compact(slices.Sorted(slices.Values(b)))
+	}
+	return slices.Equal(canonicalA, canonicalB)
+}
+
+// ReadJpegPhotoFile reads a JPEG image from disk and returns it base 64
+// encoded, ready to be used as a JPEG_PHOTO attribute value.
+func ReadJpegPhotoFile(path string) (string, error) {
+	content, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return "", readErr
+	}
+	if !bytes.HasPrefix(content, jpegMagicBytes) {
+		return "", fmt.Errorf("file '%s' is not a JPEG image", path)
+	}
+	return base64.StdEncoding.EncodeToString(content), nil
+}
+
+// GetUserAttributeType returns the type of an existing user attribute schema.
+func (lc *LldapClient) GetUserAttributeType(attributeName string) (LldapCustomAttributeType, diag.Diagnostics) {
+	attributeSchema, getSchemaErr := lc.GetUserAttributeSchema(attributeName)
+	if getSchemaErr != nil {
+		return "", getSchemaErr
+	}
+	if attributeSchema == nil {
+		return "", diag.Errorf("user attribute '%s' does not exist", attributeName)
+	}
+	return attributeSchema.AttributeType, nil
+}
+
+// GetGroupAttributeType returns the type of an existing group attribute schema.
+func (lc *LldapClient) GetGroupAttributeType(attributeName string) (LldapCustomAttributeType, diag.Diagnostics) {
+	attributeSchema, getSchemaErr := lc.GetGroupAttributeSchema(attributeName)
+	if getSchemaErr != nil {
+		return "", getSchemaErr
+	}
+	if attributeSchema == nil {
+		return "", diag.Errorf("group attribute '%s' does not exist", attributeName)
+	}
+	return attributeSchema.AttributeType, nil
+}
@@ -0,0 +1,70 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAttributeValueDateTime(t *testing.T) {
+	a, errA := ParseAttributeValue(AttributeTypeDateTime, "2024-01-01T00:00:00Z")
+	b, errB := ParseAttributeValue(AttributeTypeDateTime, "2024-01-01T00:00:00+00:00")
+	c, errC := ParseAttributeValue(AttributeTypeDateTime, "2024-01-01T01:00:00+01:00")
+	assert.Nil(t, errA)
+	assert.Nil(t, errB)
+	assert.Nil(t, errC)
+	assert.Equal(t, a.Canonical, b.Canonical)
+	assert.Equal(t, a.Canonical, c.Canonical)
+	_, invalidErr := ParseAttributeValue(AttributeTypeDateTime, "yesterday")
+	assert.NotNil(t, invalidErr)
+}
+
+func TestParseAttributeValueInteger(t *testing.T) {
+	value, err := ParseAttributeValue(AttributeTypeInteger, "007")
+	assert.Nil(t, err)
+	assert.Equal(t, "7", value.Canonical)
+	assert.Equal(t, "007", value.Raw)
+	_, invalidErr := ParseAttributeValue(AttributeTypeInteger, "seven")
+	assert.NotNil(t, invalidErr)
+}
+
+func TestParseAttributeValueJpegPhoto(t *testing.T) {
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10}
+	encoded := base64.StdEncoding.EncodeToString(jpeg)
+	value, err := ParseAttributeValue(AttributeTypeJpegPhoto, encoded[:4]+"\n"+encoded[4:])
+	assert.Nil(t, err)
+	assert.Equal(t, encoded, value.Canonical)
+	_, notJpegErr := ParseAttributeValue(AttributeTypeJpegPhoto, base64.StdEncoding.EncodeToString([]byte("not a jpeg")))
+	assert.NotNil(t, notJpegErr)
+	_, notBase64Err := ParseAttributeValue(AttributeTypeJpegPhoto, "!!!")
+	assert.NotNil(t, notBase64Err)
+}
+
+func TestAttributeValuesEquivalent(t *testing.T) {
+	assert.True(t, AttributeValuesEquivalent(AttributeTypeInteger, []string{"1", "02"}, []string{"2", "001"}))
+	assert.False(t, AttributeValuesEquivalent(AttributeTypeInteger, []string{"1"}, []string{"2"}))
+	assert.False(t, AttributeValuesEquivalent(AttributeTypeString, []string{"a"}, []string{"A"}))
+	assert.True(t, AttributeValuesEquivalent(AttributeTypeString, []string{"b", "a"}, []string{"a", "b"}))
+}
+
+func TestReadJpegPhotoFile(t *testing.T) {
+	dir := t.TempDir()
+	jpegPath := filepath.Join(dir, "test.jpeg")
+	textPath := filepath.Join(dir, "test.txt")
+	assert.Nil(t, os.WriteFile(jpegPath, []byte{0xFF, 0xD8, 0xFF, 0xDB}, 0600))
+	assert.Nil(t, os.WriteFile(textPath, []byte("hello"), 0600))
+	encoded, err := ReadJpegPhotoFile(jpegPath)
+	assert.Nil(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0xFF, 0xD8, 0xFF, 0xDB}), encoded)
+	_, textErr := ReadJpegPhotoFile(textPath)
+	assert.NotNil(t, textErr)
+}
@@ -0,0 +1,173 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// tokenRefreshMargin is how long before its expiry a token is refreshed.
+const tokenRefreshMargin = time.Minute
+
+// ResolvePassword returns the password given directly, read from passwordFile, or printed by
+// passwordCommand. At most one of them may be set, an empty result means no password.
+func ResolvePassword(password string, passwordFile string, passwordCommand string) (string, error) {
+	set := 0
+	for _, source := range []string{password, passwordFile, passwordCommand} {
+		if source != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return "", fmt.Errorf("only one of password, password_file and password_command can be set")
+	}
+	switch {
+	case passwordFile != "":
+		content, readErr := os.ReadFile(passwordFile)
+		if readErr != nil {
+			return "", fmt.Errorf("could not read password_file: %s", readErr)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case passwordCommand != "":
+		var stderr bytes.Buffer
+		cmd := exec.Command("sh", "-c", passwordCommand)
+		cmd.Stderr = &stderr
+		output, runErr := cmd.Output()
+		if runErr != nil {
+			return "", fmt.Errorf("password_command failed: %s: %s", runErr, strings.TrimSpace(stderr.String()))
+		}
+		return strings.TrimRight(string(output), "\r\n"), nil
+	default:
+		return password, nil
+	}
+}
+
+// LldapTokenClaims are the claims of the JWTs issued by LLDAP.
+type LldapTokenClaims struct {
+	User   string   `json:"user"`
+	Groups []string `json:"groups"`
+	Exp    int64    `json:"exp"`
+}
+
+// ParseTokenClaims decodes the claims of a JWT. The signature is not verified, the server does that.
+func ParseTokenClaims(token string) (*LldapTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("not a JWT")
+	}
+	payload, decodeErr := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if decodeErr != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %s", decodeErr)
+	}
+	claims := LldapTokenClaims{}
+	if unmarshErr := json.Unmarshal(payload, &claims); unmarshErr != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %s", unmarshErr)
+	}
+	return &claims, nil
+}
+
+// SetToken stores a pre-issued or refreshed token. The token decides the user the client
+// acts as, so without a username it is taken from the claims, and a token for a different
+// user than the configured username is refused.
+func (lc *LldapClient) SetToken(token string) error {
+	claims, parseErr := ParseTokenClaims(token)
+	if parseErr == nil && claims.User != "" {
+		if lc.Config.UserName != "" && !strings.EqualFold(lc.Config.UserName, claims.User) {
+			return fmt.Errorf("the token is issued for user '%s', but username is set to '%s', unset username or use a token for that user", claims.User, lc.Config.UserName)
+		}
+		lc.Config.UserName = claims.User
+	}
+	lc.Token = token
+	return nil
+}
+
+// tokenExpiring reports whether the token is missing or expires soon. Tokens without
+// a readable expiry are used until the server rejects them.
+func (lc *LldapClient) tokenExpiring() bool {
+	if lc.Token == "" {
+		return true
+	}
+	claims, parseErr := ParseTokenClaims(lc.Token)
+	if parseErr != nil || claims.Exp == 0 {
+		return false
+	}
+	return time.Until(time.Unix(claims.Exp, 0)) < tokenRefreshMargin
+}
+
+// bearerToken returns a valid token, logging in or refreshing it first if needed.
+func (lc *LldapClient) bearerToken() (string, diag.Diagnostics) {
+	if lc.authLock != nil {
+		lc.authLock.Lock()
+		defer lc.authLock.Unlock()
+	}
+	if lc.tokenExpiring() {
+		if authErr := lc.Authenticate(); authErr != nil {
+			return "", authErr
+		}
+	}
+	return lc.Token, nil
+}
+
+// refresh gets a new token for the refresh token.
+func (lc *LldapClient) refresh() diag.Diagnostics {
+	lc.ensureHttpClient()
+	ref, _ := url.Parse("/auth/refresh")
+	refreshUrl := lc.Config.HttpUrl.ResolveReference(ref)
+	req, reqErr := http.NewRequest("GET", refreshUrl.String(), nil)
+	if reqErr != nil {
+		return diag.FromErr(reqErr)
+	}
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: lc.RefreshToken})
+	resp, respErr := lc.HttpClient.Do(req)
+	if respErr != nil {
+		return diag.FromErr(respErr)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Println("Error closing refresh response body:", err)
+		}
+	}()
+	bodyBytes, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		return diag.FromErr(readErr)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return diag.Errorf("could not refresh token, unexpected HTTP status code in response: %d - %s", resp.StatusCode, string(bodyBytes))
+	}
+	refreshResponse := struct {
+		Token string `json:"token"`
+	}{}
+	if unmarshErr := json.Unmarshal(bodyBytes, &refreshResponse); unmarshErr != nil {
+		return diag.FromErr(unmarshErr)
+	}
+	if setTokenErr := lc.SetToken(refreshResponse.Token); setTokenErr != nil {
+		return diag.FromErr(setTokenErr)
+	}
+	return nil
+}
+
+// ldapPassword returns the password for LDAP binds as the provider user. LDAP has no
+// token authentication, so these fail if the provider only has a token.
+func (lc *LldapClient) ldapPassword() (string, diag.Diagnostics) {
+	if lc.Config.Password == "" {
+		return "", diag.Errorf("LDAP operations bind as '%s' and require password, password_file or password_command, a token is not enough", lc.Config.UserName)
+	}
+	return lc.Config.Password, nil
+}
@@ -0,0 +1,145 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testToken(user string, exp time.Time) string {
+	claims := fmt.Sprintf(`{"exp":%d,"iat":0,"user":"%s","groups":["lldap_admin"]}`, exp.Unix(), user)
+	return "eyJhbGciOiJIUzUxMiJ9." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+}
+
+func TestResolvePassword(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	assert.Nil(t, os.WriteFile(passwordFile, []byte("from file\n"), 0600))
+
+	password, err := ResolvePassword("direct", "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "direct", password)
+	password, err = ResolvePassword("", passwordFile, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "from file", password)
+	password, err = ResolvePassword("", "", "echo 'from command'")
+	assert.Nil(t, err)
+	assert.Equal(t, "from command", password)
+	password, err = ResolvePassword("", "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "", password)
+
+	_, err = ResolvePassword("direct", passwordFile, "")
+	assert.ErrorContains(t, err, "only one of")
+	_, err = ResolvePassword("", filepath.Join(t.TempDir(), "missing"), "")
+	assert.ErrorContains(t, err, "password_file")
+	_, err = ResolvePassword("", "", "echo denied >&2; exit 1")
+	assert.ErrorContains(t, err, "denied")
+}
+
+func TestParseTokenClaims(t *testing.T) {
+	exp := time.Unix(2000000000, 0)
+	claims, err := ParseTokenClaims(testToken("robot", exp))
+	assert.Nil(t, err)
+	assert.Equal(t, "robot", claims.User)
+	assert.Equal(t, []string{"lldap_admin"}, claims.Groups)
+	assert.Equal(t, exp.Unix(), claims.Exp)
+
+	_, err = ParseTokenClaims("not a token")
+	assert.NotNil(t, err)
+	_, err = ParseTokenClaims("a.!!!.c")
+	assert.NotNil(t, err)
+}
+
+func TestSetToken(t *testing.T) {
+	lc := &LldapClient{}
+	assert.Nil(t, lc.SetToken(testToken("robot", time.Now().Add(time.Hour))))
+	assert.Equal(t, "robot", lc.Config.UserName)
+	assert.False(t, lc.tokenExpiring())
+
+	assert.Nil(t, lc.SetToken(testToken("robot", time.Now().Add(30*time.Second))))
+	assert.True(t, lc.tokenExpiring())
+
+	// Tokens that cannot be parsed are used as they are
+	assert.Nil(t, lc.SetToken("opaque"))
+	assert.Equal(t, "robot", lc.Config.UserName)
+	assert.False(t, lc.tokenExpiring())
+
+	// A token for another user than the configured username is refused
+	lc = &LldapClient{Config: Config{UserName: "admin"}}
+	assert.ErrorContains(t, lc.SetToken(testToken("robot", time.Now().Add(time.Hour))), "issued for user 'robot'")
+	assert.Equal(t, "admin", lc.Config.UserName)
+	assert.Empty(t, lc.Token)
+	assert.Nil(t, lc.SetToken(testToken("Admin", time.Now().Add(time.Hour))))
+}
+
+func TestBearerTokenRefresh(t *testing.T) {
+	refreshed := testToken("robot", time.Now().Add(time.Hour))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, cookieErr := r.Cookie("refresh_token")
+		if r.URL.Path != "/auth/refresh" || cookieErr != nil || cookie.Value != "refresh+robot" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"token":"%s"}`, refreshed)
+	}))
+	defer server.Close()
+	httpUrl, _ := url.Parse(server.URL)
+
+	// An expired token is refreshed before it is used
+	lc := &LldapClient{Config: Config{HttpUrl: httpUrl}, RefreshToken: "refresh+robot"}
+	assert.Nil(t, lc.SetToken(testToken("robot", time.Now().Add(-time.Hour))))
+	token, diags := lc.bearerToken()
+	assert.Nil(t, diags)
+	assert.Equal(t, refreshed, token)
+
+	lc = &LldapClient{Config: Config{HttpUrl: httpUrl}, RefreshToken: "invalid"}
+	_, diags = lc.bearerToken()
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "could not refresh token")
+
+	// Without a password, LDAP operations cannot bind
+	_, diags = lc.ldapPassword()
+	assert.True(t, diags.HasError())
+}
+
+func TestNewLldapClientCredentials(t *testing.T) {
+	settings := providerSettings{
+		HttpUrl:               "http://localhost:17170",
+		LdapUrl:               "ldap://localhost:3890",
+		MaxConcurrentRequests: 1,
+	}
+	_, err := newLldapClient(t.Context(), settings)
+	assert.ErrorContains(t, err, "one of password, password_file, password_command, token or refresh_token must be set")
+
+	settings.Token = testToken("robot", time.Now().Add(time.Hour))
+	client, err := newLldapClient(t.Context(), settings)
+	assert.Nil(t, err)
+	assert.Equal(t, "robot", client.Config.UserName)
+	assert.Equal(t, settings.Token, client.Token)
+
+	settings.UserName = "admin"
+	_, err = newLldapClient(t.Context(), settings)
+	assert.ErrorContains(t, err, "username is set to 'admin'")
+
+	settings.UserName = ""
+	settings.Token = ""
+	settings.PasswordCommand = "echo secret"
+	client, err = newLldapClient(t.Context(), settings)
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", client.Config.Password)
+	assert.Equal(t, "admin", client.Config.UserName)
+}
@@ -0,0 +1,109 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// builtinAttributes caches the names of the attributes LLDAP manages itself, i.e. those marked
+// hardcoded or read-only in the server schema. They only change with the LLDAP version, so they
+// are looked up once per provider instance, while custom attributes come and go.
+type builtinAttributes struct {
+	mutex sync.Mutex
+	user  map[string]bool
+	group map[string]bool
+}
+
+func newBuiltinAttributes() *builtinAttributes {
+	return &builtinAttributes{}
+}
+
+// customAttributes returns the attributes that are not built in, in their original order.
+func customAttributes(attributes []LldapCustomAttribute, builtins map[string]bool) []LldapCustomAttribute {
+	result := make([]LldapCustomAttribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		if !builtins[attribute.Name] {
+			result = append(result, attribute)
+		}
+	}
+	return result
+}
+
+// builtinUserAttributes returns the names of the built-in user attributes.
+func (lc *LldapClient) builtinUserAttributes() (map[string]bool, diag.Diagnostics) {
+	cache := lc.builtinAttributes
+	if cache != nil {
+		cache.mutex.Lock()
+		defer cache.mutex.Unlock()
+		if cache.user != nil {
+			return cache.user, nil
+		}
+	}
+	attributes, getSchemaErr := lc.GetUserAttributesSchema()
+	if getSchemaErr != nil {
+		return nil, getSchemaErr
+	}
+	builtins := map[string]bool{}
+	for _, attribute := range attributes {
+		if attribute.IsHardcoded || attribute.IsReadonly {
+			builtins[attribute.Name] = true
+		}
+	}
+	if cache != nil {
+		cache.user = builtins
+	}
+	return builtins, nil
+}
+
+// builtinGroupAttributes returns the names of the built-in group attributes.
+func (lc *LldapClient) builtinGroupAttributes() (map[string]bool, diag.Diagnostics) {
+	cache := lc.builtinAttributes
+	if cache != nil {
+		cache.mutex.Lock()
+		defer cache.mutex.Unlock()
+		if cache.group != nil {
+			return cache.group, nil
+		}
+	}
+	attributes, getSchemaErr := lc.GetGroupAttributesSchema()
+	if getSchemaErr != nil {
+		return nil, getSchemaErr
+	}
+	builtins := map[string]bool{}
+	for _, attribute := range attributes {
+		if attribute.IsHardcoded || attribute.IsReadonly {
+			builtins[attribute.Name] = true
+		}
+	}
+	if cache != nil {
+		cache.group = builtins
+	}
+	return builtins, nil
+}
+
+// UserCustomAttributes returns the custom attributes of the user, without the built-in ones
+// such as mail or first_name, which have their own fields.
+func (lc *LldapClient) UserCustomAttributes(user *LldapUser) ([]LldapCustomAttribute, diag.Diagnostics) {
+	builtins, builtinsErr := lc.builtinUserAttributes()
+	if builtinsErr != nil {
+		return nil, builtinsErr
+	}
+	return customAttributes(user.Attributes, builtins), nil
+}
+
+// GroupCustomAttributes returns the custom attributes of the group, without the built-in ones
+// such as display_name or uuid, which have their own fields.
+func (lc *LldapClient) GroupCustomAttributes(group *LldapGroup) ([]LldapCustomAttribute, diag.Diagnostics) {
+	builtins, builtinsErr := lc.builtinGroupAttributes()
+	if builtinsErr != nil {
+		return nil, builtinsErr
+	}
+	return customAttributes(group.Attributes, builtins), nil
+}
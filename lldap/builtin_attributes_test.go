@@ -0,0 +1,59 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomAttributes(t *testing.T) {
+	attributes := []LldapCustomAttribute{
+		{Name: "first_name"},
+		{Name: "custom"},
+		{Name: "avatar"},
+		{Name: "other"},
+	}
+	builtins := map[string]bool{"first_name": true, "avatar": true}
+	assert.Equal(t, []LldapCustomAttribute{{Name: "custom"}, {Name: "other"}}, customAttributes(attributes, builtins))
+	assert.Equal(t, attributes, customAttributes(attributes, nil))
+}
+
+func TestBuiltinAttributesFromSchema(t *testing.T) {
+	schemaQueries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		schemaQueries++
+		_, _ = fmt.Fprint(w, `{"data":{"schema":{"userSchema":{"attributes":[
+			{"name":"mail","attributeType":"STRING","isHardcoded":true},
+			{"name":"uuid","attributeType":"STRING","isReadonly":true},
+			{"name":"new_builtin","attributeType":"STRING","isHardcoded":true},
+			{"name":"nickname","attributeType":"STRING"}
+		]}}}}`)
+	}))
+	defer server.Close()
+	httpUrl, _ := url.Parse(server.URL)
+	lc := &LldapClient{Config: Config{HttpUrl: httpUrl}, HttpClient: server.Client(), builtinAttributes: newBuiltinAttributes()}
+	lc.SetToken(testToken("admin", time.Now().Add(time.Hour)))
+
+	user := &LldapUser{Attributes: []LldapCustomAttribute{
+		{Name: "mail"}, {Name: "uuid"}, {Name: "new_builtin"}, {Name: "nickname"},
+	}}
+	attributes, diags := lc.UserCustomAttributes(user)
+	assert.Nil(t, diags)
+	assert.Equal(t, []LldapCustomAttribute{{Name: "nickname"}}, attributes)
+
+	// The built-in attributes are only looked up once
+	_, diags = lc.UserCustomAttributes(user)
+	assert.Nil(t, diags)
+	assert.Equal(t, 1, schemaQueries)
+}
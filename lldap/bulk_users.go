@@ -0,0 +1,238 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// Bulk user changes are sent as GraphQL documents with one aliased mutation
+// per user, so a batch costs a single request. A failing mutation does not
+// abort the others, its error is reported for that user only.
+const BulkUserBatchSize = 50
+
+type BulkUserAction string
+
+const (
+	BulkUserCreate BulkUserAction = "create"
+	BulkUserUpdate BulkUserAction = "update"
+	BulkUserDelete BulkUserAction = "delete"
+)
+
+// bulkUserActionOrder applies deletions first, so that e.g. an email address
+// of a deleted user can be reused by another user in the same apply.
+var bulkUserActionOrder = []BulkUserAction{BulkUserDelete, BulkUserUpdate, BulkUserCreate}
+
+type BulkUserChange struct {
+	Action BulkUserAction
+	User   LldapUser
+	// Attributes are inserted on update, e.g. the managed marker
+	InsertAttributes []LldapCustomAttribute
+}
+
+type BulkUserResult struct {
+	UserId string
+	Action BulkUserAction
+	Err    diag.Diagnostics
+}
+
+// bulkUserFieldsEqual reports whether the fields managed by lldap_users are equal.
+func bulkUserFieldsEqual(a *LldapUser, b *LldapUser) bool {
+	return a.Email == b.Email &&
+		a.DisplayName == b.DisplayName &&
+		a.FirstName == b.FirstName &&
+		a.LastName == b.LastName &&
+		a.Avatar == b.Avatar
+}
+
+// DiffBulkUsers returns the changes turning the current users into the desired users.
+// Only users in previous that are no longer desired are deleted, all other current users are left untouched.
+// The changes are ordered by action, then by user id.
+func DiffBulkUsers(desired map[string]LldapUser, previous []string, current []LldapUser) []BulkUserChange {
+	currentById := map[string]*LldapUser{}
+	for i := range current {
+		currentById[current[i].Id] = &current[i]
+	}
+	changes := []BulkUserChange{}
+	for userId, user := range desired {
+		user.Id = userId
+		currentUser, exists := currentById[userId]
+		if !exists {
+			changes = append(changes, BulkUserChange{Action: BulkUserCreate, User: user})
+		} else if !bulkUserFieldsEqual(&user, currentUser) {
+			changes = append(changes, BulkUserChange{Action: BulkUserUpdate, User: user})
+		}
+	}
+	for _, userId := range previous {
+		if _, isDesired := desired[userId]; isDesired {
+			continue
+		}
+		if currentUser, exists := currentById[userId]; exists {
+			changes = append(changes, BulkUserChange{Action: BulkUserDelete, User: *currentUser})
+		}
+	}
+	slices.SortFunc(changes, func(a BulkUserChange, b BulkUserChange) int {
+		if a.Action != b.Action {
+			return slices.Index(bulkUserActionOrder, a.Action) - slices.Index(bulkUserActionOrder, b.Action)
+		}
+		return strings.Compare(a.User.Id, b.User.Id)
+	})
+	return changes
+}
+
+// bulkUserQuery builds one GraphQL document with an aliased mutation per change.
+func bulkUserQuery(changes []BulkUserChange) LldapClientQuery {
+	type CreateUserInput struct {
+		Id          string `json:"id"`
+		DisplayName string `json:"displayName"`
+		Email       string `json:"email"`
+		FirstName   string `json:"firstName"`
+		LastName    string `json:"lastName"`
+		Avatar      string `json:"avatar"`
+	}
+	type UpdateUserInput struct {
+		Id               string                 `json:"id"`
+		Email            string                 `json:"email"`
+		DisplayName      string                 `json:"displayName"`
+		FirstName        string                 `json:"firstName"`
+		LastName         string                 `json:"lastName"`
+		Avatar           string                 `json:"avatar"`
+		InsertAttributes []LldapCustomAttribute `json:"insertAttributes"`
+	}
+	variableDefinitions := make([]string, len(changes))
+	mutations := make([]string, len(changes))
+	variables := map[string]any{}
+	for i, change := range changes {
+		alias := fmt.Sprintf("u%d", i)
+		switch change.Action {
+		case BulkUserCreate:
+			variableDefinitions[i] = fmt.Sprintf("$%s: CreateUserInput!", alias)
+			mutations[i] = fmt.Sprintf("%s: createUser(user: $%s) {id}", alias, alias)
+			variables[alias] = CreateUserInput{
+				Id:          change.User.Id,
+				DisplayName: change.User.DisplayName,
+				Email:       change.User.Email,
+				FirstName:   change.User.FirstName,
+				LastName:    change.User.LastName,
+				Avatar:      change.User.Avatar,
+			}
+		case BulkUserUpdate:
+			variableDefinitions[i] = fmt.Sprintf("$%s: UpdateUserInput!", alias)
+			mutations[i] = fmt.Sprintf("%s: updateUser(user: $%s) {ok}", alias, alias)
+			variables[alias] = UpdateUserInput{
+				Id:               change.User.Id,
+				Email:            change.User.Email,
+				DisplayName:      change.User.DisplayName,
+				FirstName:        change.User.FirstName,
+				LastName:         change.User.LastName,
+				Avatar:           change.User.Avatar,
+				InsertAttributes: change.InsertAttributes,
+			}
+		case BulkUserDelete:
+			variableDefinitions[i] = fmt.Sprintf("$%s: String!", alias)
+			mutations[i] = fmt.Sprintf("%s: deleteUser(userId: $%s) {ok}", alias, alias)
+			variables[alias] = change.User.Id
+		}
+	}
+	return LldapClientQuery{
+		Query:         fmt.Sprintf("mutation BulkUsers(%s) {%s}", strings.Join(variableDefinitions, ", "), strings.Join(mutations, " ")),
+		OperationName: "BulkUsers",
+		Variables:     variables,
+	}
+}
+
+// bulkUserResults maps the response of a bulk query to one result per change.
+func bulkUserResults(changes []BulkUserChange, response []byte) []BulkUserResult {
+	parsed := LldapClientResponse[map[string]json.RawMessage]{}
+	unmarshErr := json.Unmarshal(response, &parsed)
+	results := make([]BulkUserResult, len(changes))
+	for i, change := range changes {
+		results[i] = BulkUserResult{UserId: change.User.Id, Action: change.Action}
+		if unmarshErr != nil {
+			results[i].Err = diag.FromErr(unmarshErr)
+			continue
+		}
+		alias := fmt.Sprintf("u%d", i)
+		for _, e := range parsed.Errors {
+			if len(e.Path) > 0 && e.Path[0] == alias {
+				results[i].Err = append(results[i].Err, diag.Diagnostic{Severity: diag.Error, Summary: e.Message})
+			}
+		}
+		if results[i].Err != nil {
+			continue
+		}
+		var data json.RawMessage
+		if parsed.Data != nil {
+			data = (*parsed.Data)[alias]
+		}
+		if len(data) == 0 || string(data) == "null" {
+			results[i].Err = diag.Errorf("GraphQL query returned no result for user '%s': %s", change.User.Id, string(response))
+			continue
+		}
+		if change.Action != BulkUserCreate {
+			ok := LldapMutateOk{}
+			if okErr := json.Unmarshal(data, &ok); okErr != nil || !ok.OK {
+				results[i].Err = diag.Errorf("Failed to %s user '%s': %s", change.Action, change.User.Id, string(data))
+			}
+		}
+	}
+	return results
+}
+
+// ApplyUserChanges applies the changes in batches of BulkUserBatchSize and returns one result per change.
+// Created users are tagged with the managed marker, if one is configured.
+func (lc *LldapClient) ApplyUserChanges(changes []BulkUserChange) []BulkUserResult {
+	results := lc.applyUserChangeBatches(changes)
+	if lc.Config.ManagedMarker == "" {
+		return results
+	}
+	markChanges := []BulkUserChange{}
+	markIndexes := []int{}
+	for i, result := range results {
+		if result.Action == BulkUserCreate && result.Err == nil {
+			markChanges = append(markChanges, BulkUserChange{
+				Action:           BulkUserUpdate,
+				User:             changes[i].User,
+				InsertAttributes: []LldapCustomAttribute{lc.managedMarkerAttribute()},
+			})
+			markIndexes = append(markIndexes, i)
+		}
+	}
+	if len(markChanges) == 0 {
+		return results
+	}
+	if schemaErr := lc.ensureManagedMarkerUserAttribute(); schemaErr != nil {
+		for _, i := range markIndexes {
+			results[i].Err = schemaErr
+		}
+		return results
+	}
+	for j, markResult := range lc.applyUserChangeBatches(markChanges) {
+		results[markIndexes[j]].Err = markResult.Err
+	}
+	return results
+}
+
+func (lc *LldapClient) applyUserChangeBatches(changes []BulkUserChange) []BulkUserResult {
+	results := make([]BulkUserResult, 0, len(changes))
+	for batch := range slices.Chunk(changes, BulkUserBatchSize) {
+		response, responseDiagErr := lc.query(bulkUserQuery(batch))
+		if responseDiagErr != nil {
+			for _, change := range batch {
+				results = append(results, BulkUserResult{UserId: change.User.Id, Action: change.Action, Err: responseDiagErr})
+			}
+			continue
+		}
+		results = append(results, bulkUserResults(batch, response)...)
+	}
+	return results
+}
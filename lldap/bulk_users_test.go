@@ -0,0 +1,71 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffBulkUsers(t *testing.T) {
+	desired := map[string]LldapUser{
+		"alice": {Email: "alice@this.test"},
+		"bob":   {Email: "bob@this.test", DisplayName: "Bob"},
+		"carol": {Email: "carol@this.test"},
+	}
+	current := []LldapUser{
+		{Id: "admin", Email: "admin@this.test"},
+		{Id: "alice", Email: "alice@this.test"},
+		{Id: "bob", Email: "bob@this.test"},
+		{Id: "dave", Email: "dave@this.test"},
+	}
+	changes := DiffBulkUsers(desired, []string{"alice", "bob", "dave", "eve"}, current)
+	actions := []string{}
+	for _, change := range changes {
+		actions = append(actions, fmt.Sprintf("%s %s", change.Action, change.User.Id))
+	}
+	// admin is not part of previous, eve no longer exists
+	assert.Equal(t, []string{"delete dave", "update bob", "create carol"}, actions)
+	assert.Equal(t, "Bob", changes[1].User.DisplayName)
+	unchanged := map[string]LldapUser{"alice": {Email: "alice@this.test"}}
+	assert.Empty(t, DiffBulkUsers(unchanged, []string{"alice"}, current))
+}
+
+func TestBulkUserQuery(t *testing.T) {
+	query := bulkUserQuery([]BulkUserChange{
+		{Action: BulkUserDelete, User: LldapUser{Id: "dave"}},
+		{Action: BulkUserUpdate, User: LldapUser{Id: "bob"}},
+		{Action: BulkUserCreate, User: LldapUser{Id: "carol"}},
+	})
+	assert.Equal(t,
+		"mutation BulkUsers($u0: String!, $u1: UpdateUserInput!, $u2: CreateUserInput!) {u0: deleteUser(userId: $u0) {ok} u1: updateUser(user: $u1) {ok} u2: createUser(user: $u2) {id}}",
+		query.Query)
+	assert.Equal(t, "dave", query.Variables.(map[string]any)["u0"])
+}
+
+func TestBulkUserResults(t *testing.T) {
+	changes := []BulkUserChange{
+		{Action: BulkUserDelete, User: LldapUser{Id: "dave"}},
+		{Action: BulkUserUpdate, User: LldapUser{Id: "bob"}},
+		{Action: BulkUserCreate, User: LldapUser{Id: "carol"}},
+	}
+	results := bulkUserResults(changes, []byte(`{
+		"data": {"u0": {"ok": true}, "u1": {"ok": false}, "u2": null},
+		"errors": [{"message": "Email already in use", "path": ["u2"]}]
+	}`))
+	assert.Len(t, results, 3)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "dave", results[0].UserId)
+	assert.Contains(t, results[1].Err[0].Summary, "Failed to update user 'bob'")
+	assert.Equal(t, "Email already in use", results[2].Err[0].Summary)
+	// Without data, every change without its own error fails
+	for _, result := range bulkUserResults(changes, []byte(`{"data": null, "errors": [{"message": "boom", "path": ["u1"]}]}`)) {
+		assert.NotNil(t, result.Err, result.UserId)
+	}
+}
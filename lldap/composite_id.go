@@ -0,0 +1,46 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Composite IDs join their parts with ':'. Parts are escaped, so that user ids
+// and attribute names containing the separator can be told apart. IDs whose
+// parts contain neither ':' nor '%' are the same as before escaping was added.
+const compositeIdSeparator = ":"
+
+var (
+	compositeIdEscaper   = strings.NewReplacer("%", "%25", ":", "%3A")
+	compositeIdUnescaper = strings.NewReplacer("%25", "%", "%3A", ":", "%3a", ":")
+)
+
+// FormatCompositeId joins the escaped parts into a composite ID.
+func FormatCompositeId(parts ...string) string {
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = compositeIdEscaper.Replace(part)
+	}
+	return strings.Join(escaped, compositeIdSeparator)
+}
+
+// ParseCompositeId splits a composite ID into exactly count unescaped parts.
+func ParseCompositeId(id string, count int) ([]string, error) {
+	parts := strings.Split(id, compositeIdSeparator)
+	if len(parts) != count {
+		return nil, fmt.Errorf("expected %d parts separated by '%s', got %d in id '%s' (escape '%s' as '%%3A' and '%%' as '%%25')", count, compositeIdSeparator, len(parts), id, compositeIdSeparator)
+	}
+	for i, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("empty part %d in id '%s'", i+1, id)
+		}
+		parts[i] = compositeIdUnescaper.Replace(part)
+	}
+	return parts, nil
+}
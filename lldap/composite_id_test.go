@@ -0,0 +1,42 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompositeId(t *testing.T) {
+	// IDs without ':' or '%' are unchanged
+	assert.Equal(t, "1:alice", FormatCompositeId("1", "alice"))
+	assert.Equal(t, "we%3Aird:50%25", FormatCompositeId("we:ird", "50%"))
+	assert.Equal(t, "a%253Ab", FormatCompositeId("a%3Ab"))
+
+	for _, parts := range [][]string{
+		{"1", "alice"},
+		{"we:ird", "attr"},
+		{"50%", "a:b:c"},
+		{"a%3Ab", "%25"},
+	} {
+		parsed, parseErr := ParseCompositeId(FormatCompositeId(parts...), len(parts))
+		assert.Nil(t, parseErr)
+		assert.Equal(t, parts, parsed)
+	}
+
+	parsed, parseErr := ParseCompositeId("we%3aird:attr", 2)
+	assert.Nil(t, parseErr)
+	assert.Equal(t, []string{"we:ird", "attr"}, parsed)
+
+	_, parseErr = ParseCompositeId("we:ird:attr", 2)
+	assert.ErrorContains(t, parseErr, "expected 2 parts")
+	_, parseErr = ParseCompositeId("alice", 2)
+	assert.NotNil(t, parseErr)
+	_, parseErr = ParseCompositeId("1:", 2)
+	assert.ErrorContains(t, parseErr, "empty part 2")
+}
@@ -0,0 +1,88 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// DefaultMaxConcurrentRequests is the default of the max_concurrent_requests provider argument.
+const DefaultMaxConcurrentRequests = 4
+
+// runConcurrently calls mutate for every item, with at most max_concurrent_requests calls in flight.
+// Unlike a loop that stops at the first error, every item is attempted: it returns the items that
+// succeeded, in their original order, and the diagnostics of all items that failed.
+func runConcurrently[T any](lc *LldapClient, items []T, mutate func(item T) diag.Diagnostics) ([]T, diag.Diagnostics) {
+	succeeded := make([]T, 0, len(items))
+	if len(items) == 0 {
+		return succeeded, nil
+	}
+	// Authenticate up front, so the workers do not all race to do it
+	if _, authErr := lc.bearerToken(); authErr != nil {
+		return succeeded, authErr
+	}
+	itemErrs := make([]diag.Diagnostics, len(items))
+	slots := make(chan struct{}, max(lc.Config.MaxConcurrentRequests, 1))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			itemErrs[i] = mutate(item)
+		}()
+	}
+	wg.Wait()
+	var diags diag.Diagnostics
+	for i, item := range items {
+		diags = append(diags, itemErrs[i]...)
+		if !itemErrs[i].HasError() {
+			succeeded = append(succeeded, item)
+		}
+	}
+	return succeeded, diags
+}
+
+// prefixDiagnostics prefixes the summaries, so errors of concurrent mutations can be told apart.
+func prefixDiagnostics(prefix string, diags diag.Diagnostics) diag.Diagnostics {
+	for i := range diags {
+		diags[i].Summary = fmt.Sprintf("%s: %s", prefix, diags[i].Summary)
+	}
+	return diags
+}
+
+// AddUsersToGroup adds the users to the group concurrently and returns the user ids that were added.
+func (lc *LldapClient) AddUsersToGroup(groupId int, userIds []string) ([]string, diag.Diagnostics) {
+	return runConcurrently(lc, userIds, func(userId string) diag.Diagnostics {
+		return prefixDiagnostics(fmt.Sprintf("Adding user '%s' to group %d", userId, groupId), lc.AddUserToGroup(groupId, userId))
+	})
+}
+
+// RemoveUsersFromGroup removes the users from the group concurrently and returns the user ids that were removed.
+func (lc *LldapClient) RemoveUsersFromGroup(groupId int, userIds []string) ([]string, diag.Diagnostics) {
+	return runConcurrently(lc, userIds, func(userId string) diag.Diagnostics {
+		return prefixDiagnostics(fmt.Sprintf("Removing user '%s' from group %d", userId, groupId), lc.RemoveUserFromGroup(groupId, userId))
+	})
+}
+
+// AddUserToGroups adds the user to the groups concurrently and returns the group ids the user was added to.
+func (lc *LldapClient) AddUserToGroups(groupIds []int, userId string) ([]int, diag.Diagnostics) {
+	return runConcurrently(lc, groupIds, func(groupId int) diag.Diagnostics {
+		return prefixDiagnostics(fmt.Sprintf("Adding user '%s' to group %d", userId, groupId), lc.AddUserToGroup(groupId, userId))
+	})
+}
+
+// RemoveUserFromGroups removes the user from the groups concurrently and returns the group ids the user was removed from.
+func (lc *LldapClient) RemoveUserFromGroups(groupIds []int, userId string) ([]int, diag.Diagnostics) {
+	return runConcurrently(lc, groupIds, func(groupId int) diag.Diagnostics {
+		return prefixDiagnostics(fmt.Sprintf("Removing user '%s' from group %d", userId, groupId), lc.RemoveUserFromGroup(groupId, userId))
+	})
+}
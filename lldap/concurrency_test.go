@@ -0,0 +1,53 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunConcurrently(t *testing.T) {
+	lc := &LldapClient{Token: "test", Config: Config{MaxConcurrentRequests: 3}}
+	var inFlight, maxInFlight, calls atomic.Int32
+	succeeded, diags := runConcurrently(lc, []int{1, 2, 3, 4, 5, 6, 7, 8}, func(item int) diag.Diagnostics {
+		calls.Add(1)
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := maxInFlight.Load()
+			if current <= previous || maxInFlight.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if item%3 == 0 {
+			return prefixDiagnostics("item", diag.Errorf("failed %d", item))
+		}
+		return nil
+	})
+	// Every item is attempted, even after the first failure
+	assert.Equal(t, int32(8), calls.Load())
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	assert.Equal(t, []int{1, 2, 4, 5, 7, 8}, succeeded)
+	assert.Len(t, diags, 2)
+	assert.Equal(t, "item: failed 3", diags[0].Summary)
+	assert.Equal(t, "item: failed 6", diags[1].Summary)
+}
+
+func TestRunConcurrentlyEmpty(t *testing.T) {
+	// No items means no requests, not even to authenticate
+	succeeded, diags := runConcurrently(&LldapClient{}, []string{}, func(string) diag.Diagnostics {
+		return diag.Errorf("unexpected call")
+	})
+	assert.Empty(t, succeeded)
+	assert.Nil(t, diags)
+}
@@ -19,4 +19,11 @@ type Config struct {
 	Password              string
 	InsecureSkipCertCheck bool
 	BaseDn                string
+	DeletionProtection    bool
+	AllowAdminLockout     bool
+	ManagedMarker         string
+	MaxConcurrentRequests int
+	PasswordPolicy        PasswordPolicy
+	ReadOnly              bool
+	ReconcileManagedOnly  bool
 }
@@ -22,7 +22,7 @@ func dataSourceGroup() *schema.Resource {
 			"attributes": {
 				Type:        schema.TypeSet,
 				Computed:    true,
-				Description: "Attributes for this group",
+				Description: "Custom attributes for this group, without the built-in attributes LLDAP manages itself",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"name": {
@@ -47,14 +47,18 @@ func dataSourceGroup() *schema.Resource {
 				Description: "Metadata of group object creation",
 			},
 			"display_name": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "Display name of this group",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "display_name"},
+				Description:  "Display name of this group, can be used instead of `id` to look up the group",
 			},
 			"id": {
-				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "The unique group ID",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "display_name"},
+				Description:  "The unique group ID",
 			},
 			"users": {
 				Type:        schema.TypeSet,
@@ -92,18 +96,28 @@ func dataSourceGroupUsersParser(llusers []LldapUser) []map[string]any {
 }
 
 func dataSourceGroupRead(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	id := d.Get("id").(int)
 	lc := m.(*LldapClient)
-	llgroup, getGroupErr := lc.GetGroup(id)
+	var llgroup *LldapGroup
+	var getGroupErr diag.Diagnostics
+	if id, ok := d.GetOk("id"); ok {
+		llgroup, getGroupErr = lc.GetGroup(id.(int))
+	} else {
+		llgroup, getGroupErr = lc.GetGroupByDisplayName(d.Get("display_name").(string))
+	}
 	if getGroupErr != nil {
 		return getGroupErr
 	}
+	attributes, attributesErr := lc.GroupCustomAttributes(llgroup)
+	if attributesErr != nil {
+		return attributesErr
+	}
 	d.SetId(strconv.Itoa(llgroup.Id))
 	for k, v := range map[string]any{
+		"id":            llgroup.Id,
 		"display_name":  llgroup.DisplayName,
 		"creation_date": llgroup.CreationDate,
 		"users":         dataSourceGroupUsersParser(llgroup.Users),
-		"attributes":    attributesParser(llgroup.Attributes),
+		"attributes":    attributesParser(attributes),
 	} {
 		if setErr := d.Set(k, v); setErr != nil {
 			return diag.FromErr(setErr)
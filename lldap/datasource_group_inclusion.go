@@ -0,0 +1,88 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceGroupInclusion() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceGroupInclusionRead,
+		Description: "Reads the included groups and effective memberships of a LLDAP group",
+		Schema: map[string]*schema.Schema{
+			"child_group_ids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "Ids of the groups directly included by this group",
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+			},
+			"descendant_group_ids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "Ids of all groups included by this group, directly or transitively",
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+			},
+			"effective_members": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "User ids that are members of this group, directly or through any included group",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"group_id": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				Description: "The unique group id",
+			},
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The unique group ID",
+			},
+		},
+	}
+}
+
+func dataSourceGroupInclusionRead(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	groupId := d.Get("group_id").(int)
+	lc := m.(*LldapClient)
+	groups, getGroupsErr := lc.GetGroupsWithMembers()
+	if getGroupsErr != nil {
+		return getGroupsErr
+	}
+	groupIndex := slices.IndexFunc(groups, func(group LldapGroup) bool {
+		return group.Id == groupId
+	})
+	if groupIndex < 0 {
+		return diag.Errorf("Entity not found: no group with id %d", groupId)
+	}
+	inclusions := GroupInclusionsFromGroups(groups)
+	effectiveMembers := append(groups[groupIndex].GetUserIds(), inclusions.InheritedUserIds(groups, groupId)...)
+	slices.Sort(effectiveMembers)
+	d.SetId(strconv.Itoa(groupId))
+	for k, v := range map[string]any{
+		"child_group_ids":      inclusions[groupId],
+		"descendant_group_ids": inclusions.Descendants(groupId),
+		"effective_members":    slices.Compact(effectiveMembers),
+	} {
+		if setErr := d.Set(k, v); setErr != nil {
+			return diag.FromErr(setErr)
+		}
+	}
+	return nil
+}
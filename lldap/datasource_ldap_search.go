@@ -0,0 +1,153 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type ldapSearchDataSource struct {
+	client *LldapClient
+}
+
+type ldapSearchDataSourceModel struct {
+	Attributes types.List             `tfsdk:"attributes"`
+	BaseDn     types.String           `tfsdk:"base_dn"`
+	Entries    []ldapSearchEntryModel `tfsdk:"entries"`
+	Filter     types.String           `tfsdk:"filter"`
+	Scope      types.String           `tfsdk:"scope"`
+	SizeLimit  types.Int64            `tfsdk:"size_limit"`
+}
+
+type ldapSearchEntryModel struct {
+	Attributes map[string][]string `tfsdk:"attributes"`
+	Dn         string              `tfsdk:"dn"`
+}
+
+var (
+	_ datasource.DataSourceWithConfigure      = &ldapSearchDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &ldapSearchDataSource{}
+)
+
+func NewLdapSearchDataSource() datasource.DataSource {
+	return &ldapSearchDataSource{}
+}
+
+func (d *ldapSearchDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ldap_search"
+}
+
+func (d *ldapSearchDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Runs a search over the LDAP interface of LLDAP, bound as the provider's `username`",
+		Attributes: map[string]schema.Attribute{
+			"attributes": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Attributes to return for each entry, all attributes if not set",
+			},
+			"base_dn": schema.StringAttribute{
+				Optional:    true,
+				Description: "DN to search below, defaults to the provider's `base_dn`",
+			},
+			"entries": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Entries found by the search, sorted by DN",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"attributes": schema.MapAttribute{
+							Computed:    true,
+							ElementType: types.ListType{ElemType: types.StringType},
+							Description: "Attribute values of the entry by attribute name, values that are not valid UTF-8 are base64 encoded",
+						},
+						"dn": schema.StringAttribute{
+							Computed:    true,
+							Description: "DN of the entry",
+						},
+					},
+				},
+			},
+			"filter": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("LDAP filter, defaults to `%s`", LdapSearchDefaultFilter),
+			},
+			"scope": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("Search scope, one of `%s`, defaults to `%s`", strings.Join(LdapSearchScopes, "`, `"), LdapSearchScopeSub),
+			},
+			"size_limit": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Maximum number of entries to return, no limit if not set",
+			},
+		},
+	}
+}
+
+func (d *ldapSearchDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	client, clientDiags := frameworkClient(req.ProviderData)
+	resp.Diagnostics.Append(clientDiags...)
+	d.client = client
+}
+
+func (d *ldapSearchDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config ldapSearchDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.Scope.IsUnknown() || config.Filter.IsUnknown() {
+		return
+	}
+	validateErr := ValidateLdapSearch(
+		stringValueOrDefault(config.Scope, LdapSearchScopeSub),
+		stringValueOrDefault(config.Filter, LdapSearchDefaultFilter),
+	)
+	if validateErr != nil {
+		resp.Diagnostics.AddError("Invalid LDAP search", validateErr.Error())
+	}
+	if !config.SizeLimit.IsUnknown() && config.SizeLimit.ValueInt64() < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("size_limit"), "Invalid size limit", "size_limit must not be negative")
+	}
+}
+
+func (d *ldapSearchDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config ldapSearchDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	attributes := []string{}
+	if !config.Attributes.IsNull() {
+		resp.Diagnostics.Append(config.Attributes.ElementsAs(ctx, &attributes, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	entries, searchErr := d.client.LdapSearch(
+		stringValueOrDefault(config.BaseDn, d.client.Config.BaseDn),
+		stringValueOrDefault(config.Scope, LdapSearchScopeSub),
+		stringValueOrDefault(config.Filter, LdapSearchDefaultFilter),
+		attributes,
+		int(config.SizeLimit.ValueInt64()),
+	)
+	resp.Diagnostics.Append(frameworkDiagnostics(searchErr)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	config.Entries = make([]ldapSearchEntryModel, len(entries))
+	for i, entry := range entries {
+		config.Entries[i] = ldapSearchEntryModel{
+			Attributes: entry.Attributes,
+			Dn:         entry.Dn,
+		}
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, config)...)
+}
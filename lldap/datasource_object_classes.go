@@ -0,0 +1,66 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceObjectClasses() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceObjectClassesRead,
+		Description: "Extra LDAP object classes configured for users and groups",
+		Schema: map[string]*schema.Schema{
+			"group_object_classes": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "Extra LDAP object classes for groups",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Generated ID representing the object classes",
+			},
+			"user_object_classes": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "Extra LDAP object classes for users",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceObjectClassesRead(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	lc := m.(*LldapClient)
+	userObjectClasses, getUserErr := lc.GetUserObjectClasses()
+	if getUserErr != nil {
+		return getUserErr
+	}
+	groupObjectClasses, getGroupErr := lc.GetGroupObjectClasses()
+	if getGroupErr != nil {
+		return getGroupErr
+	}
+	dataSourceSetHashId(d, [][]string{userObjectClasses, groupObjectClasses})
+	for k, v := range map[string]any{
+		"user_object_classes":  userObjectClasses,
+		"group_object_classes": groupObjectClasses,
+	} {
+		if setErr := d.Set(k, v); setErr != nil {
+			return diag.FromErr(setErr)
+		}
+	}
+	return nil
+}
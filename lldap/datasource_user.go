@@ -22,7 +22,7 @@ func dataSourceUser() *schema.Resource {
 			"attributes": {
 				Type:        schema.TypeSet,
 				Computed:    true,
-				Description: "Custom attributes for this user",
+				Description: "Custom attributes for this user, without the built-in attributes LLDAP manages itself",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"name": {
@@ -57,9 +57,11 @@ func dataSourceUser() *schema.Resource {
 				Description: "Display name of this user",
 			},
 			"email": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "The unique user email",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "email", "uuid"},
+				Description:  "The unique user email, can be used instead of `id` to look up the user",
 			},
 			"first_name": {
 				Type:        schema.TypeString,
@@ -68,9 +70,11 @@ func dataSourceUser() *schema.Resource {
 			},
 			"groups": &dataSourceGroupsSchema,
 			"id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The unique user ID",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "email", "uuid"},
+				Description:  "The unique user ID",
 				StateFunc: func(val any) string {
 					return strings.ToLower(val.(string))
 				},
@@ -86,30 +90,44 @@ func dataSourceUser() *schema.Resource {
 				Description: "The unique username",
 			},
 			"uuid": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "UUID of user",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "email", "uuid"},
+				Description:  "UUID of user, can be used instead of `id` to look up the user",
 			},
 		},
 	}
 }
 
 func dataSourceUserRead(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	id := d.Get("id").(string)
 	lc := m.(*LldapClient)
-	user, getUserErr := lc.GetUser(id)
+	var user *LldapUser
+	var getUserErr diag.Diagnostics
+	if id, ok := d.GetOk("id"); ok {
+		user, getUserErr = lc.GetUser(id.(string))
+	} else if email, ok := d.GetOk("email"); ok {
+		user, getUserErr = lc.GetUserByEmail(email.(string))
+	} else {
+		user, getUserErr = lc.GetUserByUuid(d.Get("uuid").(string))
+	}
 	if getUserErr != nil {
 		return getUserErr
 	}
+	attributes, attributesErr := lc.UserCustomAttributes(user)
+	if attributesErr != nil {
+		return attributesErr
+	}
 	d.SetId(user.Id)
 	for k, v := range map[string]any{
-		"attributes":    attributesParser(user.Attributes),
+		"attributes":    attributesParser(attributes),
 		"avatar":        user.Avatar,
 		"creation_date": user.CreationDate,
 		"display_name":  user.DisplayName,
 		"email":         user.Email,
 		"first_name":    user.FirstName,
 		"groups":        dataSourceGroupsParser(user.Groups),
+		"id":            user.Id,
 		"last_name":     user.LastName,
 		"username":      user.Id,
 		"uuid":          user.Uuid,
@@ -0,0 +1,80 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var deletionProtectionSchema = schema.Schema{
+	Type:        schema.TypeBool,
+	Optional:    true,
+	Computed:    true,
+	Description: "Prevents Terraform from deleting this object while `true`, defaults to the provider's `deletion_protection` setting",
+}
+
+// deletionProtectionCustomizeDiff applies the provider default if deletion_protection is not configured.
+// The effective value is kept in state, because the configuration is no longer available on delete.
+func deletionProtectionCustomizeDiff(_ context.Context, d *schema.ResourceDiff, m any) error {
+	lc, ok := m.(*LldapClient)
+	if !ok {
+		return nil
+	}
+	if !d.GetRawConfig().IsNull() && d.GetRawConfig().GetAttr("deletion_protection").IsNull() {
+		if d.Id() == "" || d.Get("deletion_protection").(bool) != lc.Config.DeletionProtection {
+			return d.SetNew("deletion_protection", lc.Config.DeletionProtection)
+		}
+	}
+	return nil
+}
+
+// planDeletionProtection is deletionProtectionCustomizeDiff for framework resources. State written
+// without deletion_protection counts as false, as it did in the SDK implementations.
+func planDeletionProtection(ctx context.Context, lc *LldapClient, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var configured types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("deletion_protection"), &configured)...)
+	if resp.Diagnostics.HasError() || !configured.IsNull() {
+		return
+	}
+	if !req.State.Raw.IsNull() {
+		var prior types.Bool
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("deletion_protection"), &prior)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if prior.ValueBool() == lc.Config.DeletionProtection {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("deletion_protection"), prior)...)
+			return
+		}
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("deletion_protection"), lc.Config.DeletionProtection)...)
+}
+
+// deletionProtectionCheck returns an error diagnostic if the object must not be deleted.
+func deletionProtectionCheck(d *schema.ResourceData, kind string, name string) diag.Diagnostics {
+	if d.Get("deletion_protection").(bool) {
+		return deletionProtectionError(kind, name)
+	}
+	return nil
+}
+
+func deletionProtectionError(kind string, name string) diag.Diagnostics {
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Deletion protection is enabled",
+			Detail:   fmt.Sprintf("Cannot delete %s '%s' while deletion_protection is enabled. Set deletion_protection = false and apply before deleting it.", kind, name),
+		},
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletionProtectionCheck(t *testing.T) {
+	protected := schema.TestResourceDataRaw(t, resourceGroupAttribute().Schema, map[string]any{
+		"name":                "protected",
+		"deletion_protection": true,
+	})
+	diags := deletionProtectionCheck(protected, "group attribute", "protected")
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Detail, "group attribute 'protected'")
+
+	unprotected := schema.TestResourceDataRaw(t, resourceGroupAttribute().Schema, map[string]any{
+		"name": "unprotected",
+	})
+	assert.Nil(t, deletionProtectionCheck(unprotected, "group attribute", "unprotected"))
+}
+
+func TestDeletionProtectionDelete(t *testing.T) {
+	// Delete must fail before any API call is made
+	for name, resource := range map[string]*schema.Resource{
+		"lldap_group_attribute": resourceGroupAttribute(),
+		"lldap_user_attribute":  resourceUserAttribute(),
+	} {
+		d := resource.TestResourceData()
+		d.SetId("1")
+		assert.Nil(t, d.Set("deletion_protection", true))
+		diags := resource.DeleteContext(context.Background(), d, nil)
+		assert.True(t, diags.HasError(), name)
+	}
+	// Lockout protection is disabled, so only deletion protection can refuse these
+	lc := &LldapClient{Config: Config{AllowAdminLockout: true}}
+	for name, r := range map[string]fwresource.Resource{
+		"lldap_group": &groupResource{client: lc},
+		"lldap_user":  &userResource{client: lc},
+	} {
+		state := testResourceState(t, r, map[string]any{"id": "1", "deletion_protection": true})
+		resp := &fwresource.DeleteResponse{State: state}
+		r.Delete(t.Context(), fwresource.DeleteRequest{State: state}, resp)
+		assert.True(t, resp.Diagnostics.HasError(), name)
+		assert.Contains(t, resp.Diagnostics[0].Summary(), "Deletion protection is enabled", name)
+	}
+}
+
+func TestDeletionProtectionUserMigrate(t *testing.T) {
+	server := testProviderServer(t)
+	rename := func(strategy string, deletionProtection bool) *tfprotov6.PlanResourceChangeResponse {
+		r := NewUserResource()
+		prior := testResourceState(t, r, map[string]any{
+			"id":                  "before",
+			"username":            "before",
+			"email":               "user@example.com",
+			"rename_strategy":     strategy,
+			"deletion_protection": deletionProtection,
+		})
+		proposed := testResourceState(t, r, map[string]any{
+			"id":                  "before",
+			"username":            "after",
+			"email":               "user@example.com",
+			"rename_strategy":     strategy,
+			"deletion_protection": deletionProtection,
+		})
+		config := testResourceState(t, r, map[string]any{
+			"username":            "after",
+			"email":               "user@example.com",
+			"rename_strategy":     strategy,
+			"deletion_protection": deletionProtection,
+		})
+		resp, err := server.PlanResourceChange(t.Context(), &tfprotov6.PlanResourceChangeRequest{
+			TypeName:         "lldap_user",
+			PriorState:       dynamicValue(t, prior.Raw),
+			ProposedNewState: dynamicValue(t, proposed.Raw),
+			Config:           dynamicValue(t, config.Raw),
+		})
+		require.NoError(t, err)
+		return resp
+	}
+	// Migrating deletes the old user, so it must not bypass deletion protection
+	protected := rename(RenameStrategyMigrate, true)
+	assert.Len(t, protected.Diagnostics, 1)
+	assert.Contains(t, protected.Diagnostics[0].Detail, "deletion_protection")
+
+	migrated := rename(RenameStrategyMigrate, false)
+	assert.Empty(t, migrated.Diagnostics)
+	assert.Empty(t, migrated.RequiresReplace)
+
+	// Replacing fails on delete, like any other protected user
+	replaced := rename(RenameStrategyRecreate, true)
+	assert.Empty(t, replaced.Diagnostics)
+	assert.Len(t, replaced.RequiresReplace, 1)
+}
+
+func TestDeletionProtectionAttributeMigrate(t *testing.T) {
+	for name, resource := range map[string]*schema.Resource{
+		"lldap_group_attribute": resourceGroupAttribute(),
+		"lldap_user_attribute":  resourceUserAttribute(),
+	} {
+		migrate := func(deletionProtection string) (*terraform.InstanceDiff, error) {
+			state := &terraform.InstanceState{
+				ID: "level",
+				Attributes: map[string]string{
+					"id":                  "level",
+					"name":                "level",
+					"attribute_type":      "STRING",
+					"is_list":             "false",
+					"is_visible":          "true",
+					"migrate_values":      "true",
+					"deletion_protection": deletionProtection,
+				},
+			}
+			// Only is_visible changes, which still recreates the schema
+			config := terraform.NewResourceConfigRaw(map[string]any{
+				"name":                "level",
+				"attribute_type":      "STRING",
+				"is_list":             false,
+				"is_visible":          false,
+				"migrate_values":      true,
+				"deletion_protection": deletionProtection == "true",
+			})
+			return resource.Diff(t.Context(), state, config, nil)
+		}
+		// Migrating deletes the schema, so it must not bypass deletion protection
+		_, protectedErr := migrate("true")
+		assert.NotNil(t, protectedErr, name)
+		assert.Contains(t, protectedErr.Error(), "deletion_protection", name)
+
+		migrated, migratedErr := migrate("false")
+		assert.Nil(t, migratedErr, name)
+		assert.False(t, migrated.RequiresNew(), name)
+	}
+}
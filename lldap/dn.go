@@ -0,0 +1,39 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LLDAP places users below ou=people and groups below ou=groups of the base DN.
+// Users are named by their user id, groups by their display name.
+
+// UserDn returns the DN of the user, e.g. uid=admin,ou=people,dc=example,dc=com.
+func UserDn(userId string, baseDn string) string {
+	return fmt.Sprintf("uid=%s,ou=people,%s", ldap.EscapeDN(userId), baseDn)
+}
+
+// GroupDn returns the DN of the group, e.g. cn=lldap_admin,ou=groups,dc=example,dc=com.
+func GroupDn(displayName string, baseDn string) string {
+	return fmt.Sprintf("cn=%s,ou=groups,%s", ldap.EscapeDN(displayName), baseDn)
+}
+
+// MemberOfFilter returns an LDAP filter matching members of any of the groups.
+func MemberOfFilter(groupDisplayNames []string, baseDn string) string {
+	filters := make([]string, len(groupDisplayNames))
+	for i, displayName := range groupDisplayNames {
+		filters[i] = fmt.Sprintf("(memberOf=%s)", ldap.EscapeFilter(GroupDn(displayName, baseDn)))
+	}
+	if len(filters) == 1 {
+		return filters[0]
+	}
+	return "(|" + strings.Join(filters, "") + ")"
+}
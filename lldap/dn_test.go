@@ -0,0 +1,31 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserDn(t *testing.T) {
+	assert.Equal(t, "uid=admin,ou=people,dc=example,dc=com", UserDn("admin", "dc=example,dc=com"))
+	assert.Equal(t, `uid=doe\, john\+1,ou=people,dc=example,dc=com`, UserDn("doe, john+1", "dc=example,dc=com"))
+	assert.Equal(t, `uid=\#x\ ,ou=people,dc=example,dc=com`, UserDn("#x ", "dc=example,dc=com"))
+}
+
+func TestGroupDn(t *testing.T) {
+	assert.Equal(t, "cn=lldap_admin,ou=groups,dc=example,dc=com", GroupDn("lldap_admin", "dc=example,dc=com"))
+	assert.Equal(t, `cn=R&D \<EU\>,ou=groups,dc=example,dc=com`, GroupDn("R&D <EU>", "dc=example,dc=com"))
+}
+
+func TestMemberOfFilter(t *testing.T) {
+	assert.Equal(t, "(memberOf=cn=admins,ou=groups,dc=example,dc=com)", MemberOfFilter([]string{"admins"}, "dc=example,dc=com"))
+	assert.Equal(t,
+		`(|(memberOf=cn=admins,ou=groups,dc=example,dc=com)(memberOf=cn=ops \5c, \2a\28eu\29,ou=groups,dc=example,dc=com))`,
+		MemberOfFilter([]string{"admins", "ops , *(eu)"}, "dc=example,dc=com"))
+}
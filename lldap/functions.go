@@ -0,0 +1,198 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Terraform calls provider functions without configuring the provider, so
+// functions building DNs take the base DN as an argument.
+
+var baseDnParameter = function.StringParameter{
+	Name:        "base_dn",
+	Description: "The base DN of the LLDAP server, i.e. the provider's `base_dn`, e.g. `dc=example,dc=com`",
+}
+
+// stringFunction is a provider function that maps string arguments to a string.
+type stringFunction struct {
+	name        string
+	summary     string
+	description string
+	parameters  []function.Parameter
+	run         func(args []string) (string, *function.FuncError)
+}
+
+func (f *stringFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = f.name
+}
+
+func (f *stringFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     f.summary,
+		Description: f.description,
+		Parameters:  f.parameters,
+		Return:      function.StringReturn{},
+	}
+}
+
+func (f *stringFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	args := make([]string, len(f.parameters))
+	targets := make([]any, len(args))
+	for i := range args {
+		targets[i] = &args[i]
+	}
+	resp.Error = req.Arguments.Get(ctx, targets...)
+	if resp.Error != nil {
+		return
+	}
+	result, runErr := f.run(args)
+	if runErr != nil {
+		resp.Error = runErr
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, result)
+}
+
+func requireNonEmpty(args []string, names ...string) *function.FuncError {
+	for i, name := range names {
+		if args[i] == "" {
+			return function.NewArgumentFuncError(int64(i), name+" must not be empty")
+		}
+	}
+	return nil
+}
+
+func NewUserDnFunction() function.Function {
+	return &stringFunction{
+		name:        "user_dn",
+		summary:     "Returns the DN of an LLDAP user",
+		description: "Returns the DN of an LLDAP user, e.g. `uid=admin,ou=people,dc=example,dc=com`, escaping special characters in the user id.",
+		parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "user_id",
+				Description: "The user id",
+			},
+			baseDnParameter,
+		},
+		run: func(args []string) (string, *function.FuncError) {
+			if err := requireNonEmpty(args, "user_id", "base_dn"); err != nil {
+				return "", err
+			}
+			return UserDn(args[0], args[1]), nil
+		},
+	}
+}
+
+func NewGroupDnFunction() function.Function {
+	return &stringFunction{
+		name:        "group_dn",
+		summary:     "Returns the DN of an LLDAP group",
+		description: "Returns the DN of an LLDAP group, e.g. `cn=lldap_admin,ou=groups,dc=example,dc=com`, escaping special characters in the display name.",
+		parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "display_name",
+				Description: "The display name of the group",
+			},
+			baseDnParameter,
+		},
+		run: func(args []string) (string, *function.FuncError) {
+			if err := requireNonEmpty(args, "display_name", "base_dn"); err != nil {
+				return "", err
+			}
+			return GroupDn(args[0], args[1]), nil
+		},
+	}
+}
+
+func NewEscapeDnValueFunction() function.Function {
+	return &stringFunction{
+		name:        "escape_dn_value",
+		summary:     "Escapes a value for use in a DN",
+		description: "Escapes the special characters of a DN attribute value as defined in RFC 4514, e.g. `Doe, John` becomes `Doe\\, John`.",
+		parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "value",
+				Description: "The attribute value to escape",
+			},
+		},
+		run: func(args []string) (string, *function.FuncError) {
+			return ldap.EscapeDN(args[0]), nil
+		},
+	}
+}
+
+func NewEscapeFilterFunction() function.Function {
+	return &stringFunction{
+		name:        "escape_filter",
+		summary:     "Escapes a value for use in an LDAP filter",
+		description: "Escapes the special characters of an LDAP filter value as defined in RFC 4515, e.g. `a*b` becomes `a\\2ab`.",
+		parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "value",
+				Description: "The filter value to escape",
+			},
+		},
+		run: func(args []string) (string, *function.FuncError) {
+			return ldap.EscapeFilter(args[0]), nil
+		},
+	}
+}
+
+type memberOfFilterFunction struct{}
+
+func NewMemberOfFilterFunction() function.Function {
+	return &memberOfFilterFunction{}
+}
+
+func (f *memberOfFilterFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "member_of_filter"
+}
+
+func (f *memberOfFilterFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Returns an LDAP filter matching members of any of the groups",
+		Description: "Returns an LDAP filter matching members of any of the groups, e.g. `(|(memberOf=cn=admins,ou=groups,dc=example,dc=com)(memberOf=cn=ops,ou=groups,dc=example,dc=com))`, escaping special characters in the display names.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:        "groups",
+				Description: "The display names of the groups",
+				ElementType: types.StringType,
+			},
+			baseDnParameter,
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *memberOfFilterFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var groups []string
+	var baseDn string
+	resp.Error = req.Arguments.Get(ctx, &groups, &baseDn)
+	if resp.Error != nil {
+		return
+	}
+	if len(groups) == 0 {
+		resp.Error = function.NewArgumentFuncError(0, "groups must not be empty")
+		return
+	}
+	for _, group := range groups {
+		if group == "" {
+			resp.Error = function.NewArgumentFuncError(0, "groups must not contain empty display names")
+			return
+		}
+	}
+	if baseDn == "" {
+		resp.Error = function.NewArgumentFuncError(1, "base_dn must not be empty")
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, MemberOfFilter(groups, baseDn))
+}
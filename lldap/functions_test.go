@@ -0,0 +1,83 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callFunction(t *testing.T, name string, args ...tftypes.Value) (string, *tfprotov6.FunctionError) {
+	arguments := make([]*tfprotov6.DynamicValue, len(args))
+	for i, arg := range args {
+		arguments[i] = dynamicValue(t, arg)
+	}
+	resp, err := testProviderServer(t).CallFunction(context.Background(), &tfprotov6.CallFunctionRequest{
+		Name:      name,
+		Arguments: arguments,
+	})
+	require.NoError(t, err)
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+	result, unmarshalErr := resp.Result.Unmarshal(tftypes.String)
+	require.NoError(t, unmarshalErr)
+	var value string
+	require.NoError(t, result.As(&value))
+	return value, nil
+}
+
+func stringValue(value string) tftypes.Value {
+	return tftypes.NewValue(tftypes.String, value)
+}
+
+func stringListValue(values ...string) tftypes.Value {
+	elements := make([]tftypes.Value, len(values))
+	for i, value := range values {
+		elements[i] = stringValue(value)
+	}
+	return tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, elements)
+}
+
+func TestFunctions(t *testing.T) {
+	baseDn := stringValue("dc=example,dc=com")
+	tests := []struct {
+		name     string
+		args     []tftypes.Value
+		expected string
+	}{
+		{"user_dn", []tftypes.Value{stringValue("doe,john"), baseDn}, `uid=doe\,john,ou=people,dc=example,dc=com`},
+		{"group_dn", []tftypes.Value{stringValue("lldap_admin"), baseDn}, "cn=lldap_admin,ou=groups,dc=example,dc=com"},
+		{"escape_dn_value", []tftypes.Value{stringValue(" a+b ")}, `\ a\+b\ `},
+		{"escape_filter", []tftypes.Value{stringValue("a*(b)")}, `a\2a\28b\29`},
+		{"member_of_filter", []tftypes.Value{stringListValue("a", "b"), baseDn},
+			"(|(memberOf=cn=a,ou=groups,dc=example,dc=com)(memberOf=cn=b,ou=groups,dc=example,dc=com))"},
+	}
+	for _, test := range tests {
+		result, funcErr := callFunction(t, test.name, test.args...)
+		assert.Nil(t, funcErr, test.name)
+		assert.Equal(t, test.expected, result, test.name)
+	}
+}
+
+func TestFunctionErrors(t *testing.T) {
+	baseDn := stringValue("dc=example,dc=com")
+	_, userErr := callFunction(t, "user_dn", stringValue(""), baseDn)
+	require.NotNil(t, userErr)
+	assert.Equal(t, int64(0), *userErr.FunctionArgument)
+	_, groupErr := callFunction(t, "group_dn", stringValue("admins"), stringValue(""))
+	require.NotNil(t, groupErr)
+	assert.Equal(t, int64(1), *groupErr.FunctionArgument)
+	_, filterErr := callFunction(t, "member_of_filter", stringListValue(), baseDn)
+	require.NotNil(t, filterErr)
+	assert.Contains(t, filterErr.Text, "must not be empty")
+}
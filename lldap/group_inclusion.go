@@ -0,0 +1,186 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// LLDAP has no nested groups, so inclusions are recorded as a custom attribute
+// of the parent group. The attribute schema is created on first use.
+const GroupInclusionAttributeName = "included_groups"
+
+// LldapGroupInclusions maps parent group ids to the ids of their direct child groups.
+type LldapGroupInclusions map[int][]int
+
+// GroupInclusionsFromGroups reads the inclusions recorded in the group attributes.
+func GroupInclusionsFromGroups(groups []LldapGroup) LldapGroupInclusions {
+	inclusions := LldapGroupInclusions{}
+	for _, group := range groups {
+		for _, attr := range group.Attributes {
+			if attr.Name != GroupInclusionAttributeName {
+				continue
+			}
+			for _, value := range attr.Value {
+				childId, parseErr := strconv.Atoi(value)
+				if parseErr == nil {
+					inclusions[group.Id] = append(inclusions[group.Id], childId)
+				}
+			}
+		}
+	}
+	return inclusions
+}
+
+// FindCycle returns a cycle reachable from the given group as list of group ids,
+// e.g. [1 2 3 1], or nil if there is none.
+func (inclusions LldapGroupInclusions) FindCycle(groupId int) []int {
+	path := make([]int, 0)
+	done := map[int]bool{}
+	var visit func(id int) []int
+	visit = func(id int) []int {
+		if index := slices.Index(path, id); index >= 0 {
+			return append(slices.Clone(path[index:]), id)
+		}
+		if done[id] {
+			return nil
+		}
+		path = append(path, id)
+		for _, childId := range inclusions[id] {
+			if cycle := visit(childId); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		done[id] = true
+		return nil
+	}
+	return visit(groupId)
+}
+
+// Descendants returns the sorted ids of all groups included by the given
+// group, directly or transitively.
+func (inclusions LldapGroupInclusions) Descendants(groupId int) []int {
+	result := make([]int, 0)
+	queue := slices.Clone(inclusions[groupId])
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == groupId || slices.Contains(result, id) {
+			continue
+		}
+		result = append(result, id)
+		queue = append(queue, inclusions[id]...)
+	}
+	slices.Sort(result)
+	return result
+}
+
+// InheritedUserIds returns the sorted ids of all users that are direct members
+// of any group included by the given group, directly or transitively.
+func (inclusions LldapGroupInclusions) InheritedUserIds(groups []LldapGroup, groupId int) []string {
+	descendants := inclusions.Descendants(groupId)
+	result := make([]string, 0)
+	for _, group := range groups {
+		if slices.Contains(descendants, group.Id) {
+			result = append(result, group.GetUserIds()...)
+		}
+	}
+	slices.Sort(result)
+	return slices.Compact(result)
+}
+
+func formatGroupInclusionCycle(cycle []int) string {
+	ids := make([]string, len(cycle))
+	for i, id := range cycle {
+		ids[i] = strconv.Itoa(id)
+	}
+	return strings.Join(ids, " -> ")
+}
+
+// GetGroupsWithMembers returns all groups including their users and attributes.
+func (lc *LldapClient) GetGroupsWithMembers() ([]LldapGroup, diag.Diagnostics) {
+	type LldapGroupListResponseData struct {
+		Groups []LldapGroup `json:"groups"`
+	}
+	query := LldapClientQuery{
+		Query:         "query GetGroupListWithMembers {groups {id displayName creationDate uuid users {id displayName} attributes {name value}}}",
+		OperationName: "GetGroupListWithMembers",
+	}
+	response, responseDiagErr := lc.query(query)
+	if responseDiagErr != nil {
+		return nil, responseDiagErr
+	}
+	groups := LldapClientResponse[LldapGroupListResponseData]{}
+	unmarshErr := json.Unmarshal(response, &groups)
+	if unmarshErr != nil {
+		return nil, diag.FromErr(unmarshErr)
+	}
+	if groups.Errors != nil {
+		return nil, diag.Errorf("GraphQL query returned error: %s", string(response))
+	}
+	return groups.Data.Groups, nil
+}
+
+// CheckGroupInclusions validates that including the child groups in the parent
+// group does not introduce a cycle.
+func CheckGroupInclusions(inclusions LldapGroupInclusions, parentGroupId int, childGroupIds []int) error {
+	if slices.Contains(childGroupIds, parentGroupId) {
+		return fmt.Errorf("group %d cannot include itself", parentGroupId)
+	}
+	planned := LldapGroupInclusions{}
+	for id, children := range inclusions {
+		planned[id] = children
+	}
+	planned[parentGroupId] = childGroupIds
+	if cycle := planned.FindCycle(parentGroupId); cycle != nil {
+		return fmt.Errorf("group inclusion cycle detected: %s", formatGroupInclusionCycle(cycle))
+	}
+	return nil
+}
+
+// SetGroupInclusions records the child groups of a parent group, an empty list
+// removes the record.
+func (lc *LldapClient) SetGroupInclusions(parentGroupId int, childGroupIds []int) diag.Diagnostics {
+	parentGroup, getGroupErr := lc.GetGroup(parentGroupId)
+	if getGroupErr != nil {
+		return getGroupErr
+	}
+	hasInclusions := slices.ContainsFunc(parentGroup.Attributes, func(attr LldapCustomAttribute) bool {
+		return attr.Name == GroupInclusionAttributeName
+	})
+	if hasInclusions {
+		removeErr := lc.RemoveAttributeFromGroup(parentGroupId, GroupInclusionAttributeName)
+		if removeErr != nil {
+			return removeErr
+		}
+	}
+	if len(childGroupIds) == 0 {
+		return nil
+	}
+	attributeSchema, getSchemaErr := lc.GetGroupAttributeSchema(GroupInclusionAttributeName)
+	if getSchemaErr != nil {
+		return getSchemaErr
+	}
+	if attributeSchema == nil {
+		createErr := lc.CreateGroupAttribute(GroupInclusionAttributeName, AttributeTypeInteger, true, false)
+		if createErr != nil {
+			return createErr
+		}
+	}
+	value := make([]string, len(childGroupIds))
+	for i, childGroupId := range childGroupIds {
+		value[i] = strconv.Itoa(childGroupId)
+	}
+	return lc.AddAttributeToGroup(parentGroupId, GroupInclusionAttributeName, value)
+}
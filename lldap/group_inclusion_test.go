@@ -0,0 +1,111 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testGroupInclusionGroups() []LldapGroup {
+	return []LldapGroup{
+		{
+			Id:         1,
+			Users:      []LldapUser{{Id: "alice"}},
+			Attributes: []LldapCustomAttribute{{Name: GroupInclusionAttributeName, Value: []string{"2", "3"}}},
+		},
+		{
+			Id:         2,
+			Users:      []LldapUser{{Id: "bob"}},
+			Attributes: []LldapCustomAttribute{{Name: GroupInclusionAttributeName, Value: []string{"4"}}},
+		},
+		{
+			Id:    3,
+			Users: []LldapUser{{Id: "carol"}, {Id: "bob"}},
+		},
+		{
+			Id:    4,
+			Users: []LldapUser{{Id: "dave"}},
+		},
+	}
+}
+
+func TestGroupInclusionsFromGroups(t *testing.T) {
+	inclusions := GroupInclusionsFromGroups(testGroupInclusionGroups())
+	assert.Equal(t, LldapGroupInclusions{1: {2, 3}, 2: {4}}, inclusions)
+	assert.Equal(t, []int{2, 3, 4}, inclusions.Descendants(1))
+	assert.Equal(t, []int{}, inclusions.Descendants(3))
+}
+
+func TestGroupInclusionsInheritedUserIds(t *testing.T) {
+	groups := testGroupInclusionGroups()
+	inclusions := GroupInclusionsFromGroups(groups)
+	assert.Equal(t, []string{"bob", "carol", "dave"}, inclusions.InheritedUserIds(groups, 1))
+	assert.Equal(t, []string{"dave"}, inclusions.InheritedUserIds(groups, 2))
+}
+
+func TestCheckGroupInclusions(t *testing.T) {
+	inclusions := GroupInclusionsFromGroups(testGroupInclusionGroups())
+	assert.Nil(t, CheckGroupInclusions(inclusions, 3, []int{4}))
+	assert.Nil(t, CheckGroupInclusions(inclusions, 1, []int{4}))
+	selfErr := CheckGroupInclusions(inclusions, 3, []int{3})
+	assert.NotNil(t, selfErr)
+	cycleErr := CheckGroupInclusions(inclusions, 4, []int{1})
+	assert.NotNil(t, cycleErr)
+	assert.Contains(t, cycleErr.Error(), "4 -> 1 -> 2 -> 4")
+}
+
+func TestGroupInclusionMembers(t *testing.T) {
+	// alice was a direct member of the parent group before the inclusion existed
+	assert.Equal(t, []string{"bob"}, groupInclusionMembers([]string{"alice", "bob"}, []string{"alice"}, nil))
+	// bob was added by the inclusion before, carol is not a member yet
+	assert.Equal(t, []string{"bob", "carol"}, groupInclusionMembers([]string{"alice", "bob", "carol"}, []string{"alice", "bob"}, []string{"bob"}))
+	// dave is no longer inherited
+	assert.Equal(t, []string{}, groupInclusionMembers([]string{}, []string{"dave"}, []string{"dave"}))
+}
+
+func TestGroupInclusionDeleteKeepsDirectMembers(t *testing.T) {
+	removedUserIds := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query struct {
+			OperationName string         `json:"operationName"`
+			Variables     map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&query)
+		switch query.OperationName {
+		case "GetGroupDetails":
+			_, _ = fmt.Fprintf(w, `{"data":{"group":{"id":1,"displayName":"parent","users":[{"id":"alice"},{"id":"bob"}],"attributes":[{"name":"%s","value":["2"]}]}}}`, GroupInclusionAttributeName)
+		case "RemoveUserFromGroup":
+			removedUserIds = append(removedUserIds, query.Variables["user"].(string))
+			_, _ = fmt.Fprint(w, `{"data":{"removeUserFromGroup":{"ok":true}}}`)
+		default:
+			_, _ = fmt.Fprint(w, `{"data":{"updateGroup":{"ok":true}}}`)
+		}
+	}))
+	defer server.Close()
+	httpUrl, _ := url.Parse(server.URL)
+	lc := &LldapClient{Config: Config{HttpUrl: httpUrl}, HttpClient: server.Client()}
+	lc.SetToken(testToken("admin", time.Now().Add(time.Hour)))
+
+	// alice is a member of the child group and was a direct member of the parent group already
+	d := resourceGroupInclusion().TestResourceData()
+	d.SetId("1")
+	assert.Nil(t, d.Set("parent_group_id", 1))
+	assert.Nil(t, d.Set("child_group_ids", []int{2}))
+	assert.Nil(t, d.Set("members", groupInclusionMembers([]string{"alice", "bob"}, []string{"alice"}, nil)))
+
+	diags := resourceGroupInclusion().DeleteContext(t.Context(), d, lc)
+	assert.Nil(t, diags)
+	assert.Equal(t, []string{"bob"}, removedUserIds)
+}
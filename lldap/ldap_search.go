@@ -0,0 +1,112 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+const (
+	LdapSearchScopeBase = "base"
+	LdapSearchScopeOne  = "one"
+	LdapSearchScopeSub  = "sub"
+
+	LdapSearchDefaultFilter = "(objectClass=*)"
+)
+
+var LdapSearchScopes = []string{LdapSearchScopeBase, LdapSearchScopeOne, LdapSearchScopeSub}
+
+var ldapSearchScopeValues = map[string]int{
+	LdapSearchScopeBase: ldap.ScopeBaseObject,
+	LdapSearchScopeOne:  ldap.ScopeSingleLevel,
+	LdapSearchScopeSub:  ldap.ScopeWholeSubtree,
+}
+
+// LdapSearchEntry is an entry found by an LDAP search. Values that are not
+// valid UTF-8, such as JPEG photos, are base64 encoded.
+type LdapSearchEntry struct {
+	Dn         string              `json:"dn"`
+	Attributes map[string][]string `json:"attributes"`
+}
+
+// ValidateLdapSearch checks the scope and filter of an LDAP search without contacting the server.
+func ValidateLdapSearch(scope string, filter string) error {
+	if _, found := ldapSearchScopeValues[scope]; !found {
+		return fmt.Errorf("invalid scope '%s', must be one of: %s", scope, strings.Join(LdapSearchScopes, ", "))
+	}
+	if _, compileErr := ldap.CompileFilter(filter); compileErr != nil {
+		return fmt.Errorf("invalid filter '%s': %s", filter, compileErr)
+	}
+	return nil
+}
+
+// LdapSearch runs a search over the LDAP interface, bound as the configured user.
+// An empty list of attributes returns all attributes, a size limit of 0 means no limit.
+func (lc *LldapClient) LdapSearch(baseDn string, scope string, filter string, attributes []string, sizeLimit int) ([]LdapSearchEntry, diag.Diagnostics) {
+	validateErr := ValidateLdapSearch(scope, filter)
+	if validateErr != nil {
+		return nil, diag.FromErr(validateErr)
+	}
+	password, passwordErr := lc.ldapPassword()
+	if passwordErr != nil {
+		return nil, passwordErr
+	}
+	bind, bindErr := getLdapBindConnection(lc.Config.LdapUrl.String(), lc.Config.BaseDn, lc.Config.UserName, password)
+	if bindErr != nil {
+		return nil, bindErr
+	}
+	defer func() {
+		if err := bind.Close(); err != nil {
+			log.Println("Error closing ldap bind connection:", err)
+		}
+	}()
+	result, searchErr := bind.Search(ldap.NewSearchRequest(
+		baseDn,
+		ldapSearchScopeValues[scope],
+		ldap.NeverDerefAliases,
+		sizeLimit,
+		0,
+		false,
+		filter,
+		attributes,
+		nil,
+	))
+	// Hitting the size limit still returns the entries found so far
+	if searchErr != nil && !(ldap.IsErrorWithCode(searchErr, ldap.LDAPResultSizeLimitExceeded) && result != nil) {
+		return nil, diag.Errorf("ldap search for '%s' below '%s' failed: %s", filter, baseDn, searchErr)
+	}
+	entries := make([]LdapSearchEntry, len(result.Entries))
+	for i, entry := range result.Entries {
+		entries[i] = LdapSearchEntry{
+			Dn:         entry.DN,
+			Attributes: map[string][]string{},
+		}
+		for _, attribute := range entry.Attributes {
+			values := make([]string, len(attribute.ByteValues))
+			for j, value := range attribute.ByteValues {
+				if utf8.Valid(value) {
+					values[j] = string(value)
+				} else {
+					values[j] = base64.StdEncoding.EncodeToString(value)
+				}
+			}
+			entries[i].Attributes[attribute.Name] = values
+		}
+	}
+	slices.SortFunc(entries, func(a, b LdapSearchEntry) int {
+		return strings.Compare(a.Dn, b.Dn)
+	})
+	return entries, nil
+}
@@ -0,0 +1,60 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateLdapSearch(t *testing.T) {
+	assert.Nil(t, ValidateLdapSearch(LdapSearchScopeSub, LdapSearchDefaultFilter))
+	assert.Nil(t, ValidateLdapSearch(LdapSearchScopeOne, "(&(objectClass=person)(memberOf=cn=admins,ou=groups,dc=example,dc=com))"))
+	assert.ErrorContains(t, ValidateLdapSearch("subtree", LdapSearchDefaultFilter), "invalid scope")
+	assert.ErrorContains(t, ValidateLdapSearch(LdapSearchScopeBase, "(uid=admin"), "invalid filter")
+}
+
+func TestLdapSearchDataSourceValidateConfig(t *testing.T) {
+	configType := tftypes.Object{
+		AttributeTypes: map[string]tftypes.Type{
+			"attributes": tftypes.List{ElementType: tftypes.String},
+			"base_dn":    tftypes.String,
+			"entries": tftypes.List{ElementType: tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+				"attributes": tftypes.Map{ElementType: tftypes.List{ElementType: tftypes.String}},
+				"dn":         tftypes.String,
+			}}},
+			"filter":     tftypes.String,
+			"scope":      tftypes.String,
+			"size_limit": tftypes.Number,
+		},
+	}
+	validate := func(scope any, filter any) []*tfprotov6.Diagnostic {
+		config := tftypes.NewValue(configType, map[string]tftypes.Value{
+			"attributes": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, nil),
+			"base_dn":    tftypes.NewValue(tftypes.String, nil),
+			"entries":    tftypes.NewValue(configType.AttributeTypes["entries"], nil),
+			"filter":     tftypes.NewValue(tftypes.String, filter),
+			"scope":      tftypes.NewValue(tftypes.String, scope),
+			"size_limit": tftypes.NewValue(tftypes.Number, nil),
+		})
+		resp, err := testProviderServer(t).ValidateDataResourceConfig(context.Background(), &tfprotov6.ValidateDataResourceConfigRequest{
+			TypeName: "lldap_ldap_search",
+			Config:   dynamicValue(t, config),
+		})
+		require.NoError(t, err)
+		return resp.Diagnostics
+	}
+	assert.Empty(t, validate(nil, nil))
+	assert.Empty(t, validate("one", "(uid=admin)"))
+	assert.NotEmpty(t, validate("subtree", nil))
+	assert.NotEmpty(t, validate(nil, "uid=admin)("))
+}
@@ -15,8 +15,9 @@ import (
 	"net"
 	"net/http"
 	"net/url"
-	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	ldap "github.com/go-ldap/ldap/v3"
@@ -153,32 +154,17 @@ func (user *LldapUser) GetGroupIds() []int {
 	return groupIds
 }
 
-func (user *LldapUser) GetCustomAttributes() []LldapCustomAttribute {
-	hardcodedAttributeNames := []string{
-		"avatar",
-		"creation_date",
-		"display_name",
-		"first_name",
-		"last_name",
-		"mail",
-		"user_id",
-		"uuid",
-	}
-	customAttributes := make([]LldapCustomAttribute, 0)
-	for _, a := range user.Attributes {
-		if !slices.Contains(hardcodedAttributeNames, a.Name) {
-			customAttributes = append(customAttributes, a)
-		}
-	}
-	return customAttributes
-}
-
 type LldapClient struct {
-	Config       Config
-	Token        string
-	RefreshToken string
-	HttpClient   *http.Client
-	LdapClient   *ldap.Conn
+	Config            Config
+	Token             string
+	RefreshToken      string
+	HttpClient        *http.Client
+	LdapClient        *ldap.Conn
+	membershipClaims  *membershipClaims
+	role              LldapRole
+	authLock          *sync.Mutex
+	breachedPasswords map[string]bool
+	builtinAttributes *builtinAttributes
 }
 
 // Check https://github.com/lldap/lldap/blob/main/app/src/infra/schema.rs
@@ -194,7 +180,7 @@ func getLdapBindConnection(ldapUrl string, baseDn string, username string, passw
 	if dialErr != nil {
 		return nil, diag.Errorf("unable to dial ldap url: %s", dialErr)
 	}
-	userDn := fmt.Sprintf("cn=%s,ou=people,%s", ldap.EscapeFilter(username), baseDn)
+	userDn := UserDn(username, baseDn)
 	bindErr := ldapclient.Bind(userDn, password)
 	if bindErr != nil {
 		return nil, diag.Errorf("could not bind to ldap server: %s", bindErr)
@@ -221,14 +207,27 @@ func (lc *LldapClient) IsValidPassword(username string, password string) (bool,
 }
 
 func (lc *LldapClient) SetUserPassword(username string, newPassword string) diag.Diagnostics {
+	if readOnlyErr := lc.checkReadOnly(fmt.Sprintf("Changing the password of user '%s'", username)); readOnlyErr != nil {
+		return readOnlyErr
+	}
+	if roleErr := lc.checkPasswordRole(username, fmt.Sprintf("Changing the password of user '%s'", username)); roleErr != nil {
+		return roleErr
+	}
+	if policyErr := lc.checkPasswordPolicy(username, newPassword); policyErr != nil {
+		return policyErr
+	}
 	if lc.LdapClient == nil {
-		ldapclient, bindErr := getLdapBindConnection(lc.Config.LdapUrl.String(), lc.Config.BaseDn, lc.Config.UserName, lc.Config.Password)
+		password, passwordErr := lc.ldapPassword()
+		if passwordErr != nil {
+			return passwordErr
+		}
+		ldapclient, bindErr := getLdapBindConnection(lc.Config.LdapUrl.String(), lc.Config.BaseDn, lc.Config.UserName, password)
 		if bindErr != nil {
 			return bindErr
 		}
 		lc.LdapClient = ldapclient
 	}
-	userDn := fmt.Sprintf("cn=%s,ou=people,%s", ldap.EscapeFilter(username), lc.Config.BaseDn)
+	userDn := UserDn(username, lc.Config.BaseDn)
 	_, modifyErr := lc.LdapClient.PasswordModify(&ldap.PasswordModifyRequest{
 		UserIdentity: userDn,
 		NewPassword:  newPassword,
@@ -240,12 +239,18 @@ func (lc *LldapClient) SetUserPassword(username string, newPassword string) diag
 }
 
 func (lc *LldapClient) query(query LldapClientQuery) ([]byte, diag.Diagnostics) {
-	if lc.Token == "" {
-		authErr := lc.Authenticate()
-		if authErr != nil {
-			return nil, authErr
+	if strings.HasPrefix(query.Query, "mutation") {
+		if readOnlyErr := lc.checkReadOnly(fmt.Sprintf("Mutation %s", query.OperationName)); readOnlyErr != nil {
+			return nil, readOnlyErr
+		}
+		if roleErr := lc.checkMutationRole(fmt.Sprintf("Mutation %s", query.OperationName)); roleErr != nil {
+			return nil, roleErr
 		}
 	}
+	token, authErr := lc.bearerToken()
+	if authErr != nil {
+		return nil, authErr
+	}
 	queryJson, marshErr := json.Marshal(query)
 	if marshErr != nil {
 		return nil, diag.FromErr(marshErr)
@@ -257,7 +262,7 @@ func (lc *LldapClient) query(query LldapClientQuery) ([]byte, diag.Diagnostics)
 		return nil, diag.FromErr(reqErr)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", lc.Token))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	resp, respErr := lc.HttpClient.Do(req)
 	if respErr != nil {
 		return nil, diag.FromErr(respErr)
@@ -277,13 +282,29 @@ func (lc *LldapClient) query(query LldapClientQuery) ([]byte, diag.Diagnostics)
 	return bodyBytes, nil
 }
 
-func (lc *LldapClient) Authenticate() diag.Diagnostics {
+func (lc *LldapClient) ensureHttpClient() {
 	if lc.HttpClient == nil {
 		tr := &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: lc.Config.InsecureSkipCertCheck},
 		}
 		lc.HttpClient = &http.Client{Transport: tr}
 	}
+}
+
+// Authenticate gets a token by logging in with the password, or else by refreshing the refresh token.
+// A pre-issued token without either is used as it is.
+func (lc *LldapClient) Authenticate() diag.Diagnostics {
+	lc.ensureHttpClient()
+	if lc.Config.Password == "" {
+		switch {
+		case lc.RefreshToken != "":
+			return lc.refresh()
+		case lc.Token != "":
+			return nil
+		default:
+			return diag.Errorf("one of password, password_file, password_command, token or refresh_token is required")
+		}
+	}
 	type AuthBody struct {
 		UserName string `json:"username"`
 		Password string `json:"password"`
@@ -571,6 +592,192 @@ func (lc *LldapClient) DeleteUserAttribute(name string) diag.Diagnostics {
 	return nil
 }
 
+func (lc *LldapClient) GetUserObjectClasses() ([]string, diag.Diagnostics) {
+	query := LldapClientQuery{
+		Query:         "query GetUserObjectClasses { schema { userSchema { extraLdapObjectClasses }}}",
+		OperationName: "GetUserObjectClasses",
+	}
+	response, responseDiagErr := lc.query(query)
+	if responseDiagErr != nil {
+		return nil, responseDiagErr
+	}
+	type GetUserObjectClassesResponseData struct {
+		ExtraLdapObjectClasses []string `json:"extraLdapObjectClasses"`
+	}
+	type GetUserObjectClassesResponseUserSchema struct {
+		UserSchema GetUserObjectClassesResponseData `json:"userSchema"`
+	}
+	type GetUserObjectClassesResponse struct {
+		Schema GetUserObjectClassesResponseUserSchema `json:"schema"`
+	}
+	schema := LldapClientResponse[GetUserObjectClassesResponse]{}
+	unmarshErr := json.Unmarshal(response, &schema)
+	if unmarshErr != nil {
+		return nil, diag.FromErr(unmarshErr)
+	}
+	if schema.Errors != nil {
+		return nil, diag.Errorf("GraphQL query returned error: %s", string(response))
+	}
+	return schema.Data.Schema.UserSchema.ExtraLdapObjectClasses, nil
+}
+
+func (lc *LldapClient) AddUserObjectClass(name string) diag.Diagnostics {
+	type AddUserObjectClassVariables struct {
+		Name string `json:"name"`
+	}
+	type AddUserObjectClassResponseData struct {
+		AddUserObjectClass LldapMutateOk `json:"addUserObjectClass"`
+	}
+	query := LldapClientQuery{
+		Query:         "mutation AddUserObjectClass($name: String!) { addUserObjectClass(name: $name) { ok } }",
+		OperationName: "AddUserObjectClass",
+		Variables: AddUserObjectClassVariables{
+			Name: name,
+		},
+	}
+	response, responseDiagErr := lc.query(query)
+	if responseDiagErr != nil {
+		return responseDiagErr
+	}
+	addResponse := LldapClientResponse[AddUserObjectClassResponseData]{}
+	unmarshErr := json.Unmarshal(response, &addResponse)
+	if unmarshErr != nil {
+		return diag.FromErr(unmarshErr)
+	}
+	if addResponse.Errors != nil {
+		return diag.Errorf("GraphQL query returned error: %s", string(response))
+	}
+	if !addResponse.Data.AddUserObjectClass.OK {
+		return diag.Errorf("Failed to add user object class: %s", string(response))
+	}
+	return nil
+}
+
+func (lc *LldapClient) DeleteUserObjectClass(name string) diag.Diagnostics {
+	type DeleteUserObjectClassVariables struct {
+		Name string `json:"name"`
+	}
+	type DeleteUserObjectClassResponseData struct {
+		DeleteUserObjectClass LldapMutateOk `json:"deleteUserObjectClass"`
+	}
+	query := LldapClientQuery{
+		Query:         "mutation DeleteUserObjectClass($name: String!) { deleteUserObjectClass(name: $name) { ok } }",
+		OperationName: "DeleteUserObjectClass",
+		Variables: DeleteUserObjectClassVariables{
+			Name: name,
+		},
+	}
+	response, responseDiagErr := lc.query(query)
+	if responseDiagErr != nil {
+		return responseDiagErr
+	}
+	deleteResponse := LldapClientResponse[DeleteUserObjectClassResponseData]{}
+	unmarshErr := json.Unmarshal(response, &deleteResponse)
+	if unmarshErr != nil {
+		return diag.FromErr(unmarshErr)
+	}
+	if deleteResponse.Errors != nil {
+		return diag.Errorf("GraphQL query returned error: %s", string(response))
+	}
+	if !deleteResponse.Data.DeleteUserObjectClass.OK {
+		return diag.Errorf("Failed to delete user object class: %s", string(response))
+	}
+	return nil
+}
+
+func (lc *LldapClient) GetGroupObjectClasses() ([]string, diag.Diagnostics) {
+	query := LldapClientQuery{
+		Query:         "query GetGroupObjectClasses { schema { groupSchema { extraLdapObjectClasses }}}",
+		OperationName: "GetGroupObjectClasses",
+	}
+	response, responseDiagErr := lc.query(query)
+	if responseDiagErr != nil {
+		return nil, responseDiagErr
+	}
+	type GetGroupObjectClassesResponseData struct {
+		ExtraLdapObjectClasses []string `json:"extraLdapObjectClasses"`
+	}
+	type GetGroupObjectClassesResponseGroupSchema struct {
+		GroupSchema GetGroupObjectClassesResponseData `json:"groupSchema"`
+	}
+	type GetGroupObjectClassesResponse struct {
+		Schema GetGroupObjectClassesResponseGroupSchema `json:"schema"`
+	}
+	schema := LldapClientResponse[GetGroupObjectClassesResponse]{}
+	unmarshErr := json.Unmarshal(response, &schema)
+	if unmarshErr != nil {
+		return nil, diag.FromErr(unmarshErr)
+	}
+	if schema.Errors != nil {
+		return nil, diag.Errorf("GraphQL query returned error: %s", string(response))
+	}
+	return schema.Data.Schema.GroupSchema.ExtraLdapObjectClasses, nil
+}
+
+func (lc *LldapClient) AddGroupObjectClass(name string) diag.Diagnostics {
+	type AddGroupObjectClassVariables struct {
+		Name string `json:"name"`
+	}
+	type AddGroupObjectClassResponseData struct {
+		AddGroupObjectClass LldapMutateOk `json:"addGroupObjectClass"`
+	}
+	query := LldapClientQuery{
+		Query:         "mutation AddGroupObjectClass($name: String!) { addGroupObjectClass(name: $name) { ok } }",
+		OperationName: "AddGroupObjectClass",
+		Variables: AddGroupObjectClassVariables{
+			Name: name,
+		},
+	}
+	response, responseDiagErr := lc.query(query)
+	if responseDiagErr != nil {
+		return responseDiagErr
+	}
+	addResponse := LldapClientResponse[AddGroupObjectClassResponseData]{}
+	unmarshErr := json.Unmarshal(response, &addResponse)
+	if unmarshErr != nil {
+		return diag.FromErr(unmarshErr)
+	}
+	if addResponse.Errors != nil {
+		return diag.Errorf("GraphQL query returned error: %s", string(response))
+	}
+	if !addResponse.Data.AddGroupObjectClass.OK {
+		return diag.Errorf("Failed to add group object class: %s", string(response))
+	}
+	return nil
+}
+
+func (lc *LldapClient) DeleteGroupObjectClass(name string) diag.Diagnostics {
+	type DeleteGroupObjectClassVariables struct {
+		Name string `json:"name"`
+	}
+	type DeleteGroupObjectClassResponseData struct {
+		DeleteGroupObjectClass LldapMutateOk `json:"deleteGroupObjectClass"`
+	}
+	query := LldapClientQuery{
+		Query:         "mutation DeleteGroupObjectClass($name: String!) { deleteGroupObjectClass(name: $name) { ok } }",
+		OperationName: "DeleteGroupObjectClass",
+		Variables: DeleteGroupObjectClassVariables{
+			Name: name,
+		},
+	}
+	response, responseDiagErr := lc.query(query)
+	if responseDiagErr != nil {
+		return responseDiagErr
+	}
+	deleteResponse := LldapClientResponse[DeleteGroupObjectClassResponseData]{}
+	unmarshErr := json.Unmarshal(response, &deleteResponse)
+	if unmarshErr != nil {
+		return diag.FromErr(unmarshErr)
+	}
+	if deleteResponse.Errors != nil {
+		return diag.Errorf("GraphQL query returned error: %s", string(response))
+	}
+	if !deleteResponse.Data.DeleteGroupObjectClass.OK {
+		return diag.Errorf("Failed to delete group object class: %s", string(response))
+	}
+	return nil
+}
+
 func (lc *LldapClient) AddAttributeToGroup(groupId int, attributeName string, attributeValue []string) diag.Diagnostics {
 	group, getGroupErr := lc.GetGroup(groupId)
 	if getGroupErr != nil {
@@ -755,6 +962,30 @@ func (lc *LldapClient) GetGroup(id int) (*LldapGroup, diag.Diagnostics) {
 	return &group.Data.Group, nil
 }
 
+// GetGroupByDisplayName returns the group with the given display name,
+// failing if there is no such group or if the display name is ambiguous.
+func (lc *LldapClient) GetGroupByDisplayName(displayName string) (*LldapGroup, diag.Diagnostics) {
+	groups, getGroupsErr := lc.GetGroups()
+	if getGroupsErr != nil {
+		return nil, getGroupsErr
+	}
+	matchingIds := make([]string, 0)
+	var match LldapGroup
+	for _, group := range groups {
+		if group.DisplayName == displayName {
+			match = group
+			matchingIds = append(matchingIds, strconv.Itoa(group.Id))
+		}
+	}
+	if len(matchingIds) == 0 {
+		return nil, diag.Errorf("Entity not found: no group with display name '%s'", displayName)
+	}
+	if len(matchingIds) > 1 {
+		return nil, diag.Errorf("display name '%s' is ambiguous, matching group ids: %s", displayName, strings.Join(matchingIds, ", "))
+	}
+	return lc.GetGroup(match.Id)
+}
+
 func (lc *LldapClient) UpdateGroupDisplayName(groupId int, displayName string) diag.Diagnostics {
 	group, getGroupErr := lc.GetGroup(groupId)
 	if getGroupErr != nil {
@@ -1039,13 +1270,111 @@ func (lc *LldapClient) GetGroups() ([]LldapGroup, diag.Diagnostics) {
 	return groups.Data.Groups, nil
 }
 
+// GetGroupsWithAttributes returns all groups including their attributes.
+func (lc *LldapClient) GetGroupsWithAttributes() ([]LldapGroup, diag.Diagnostics) {
+	type LldapGroupListResponseData struct {
+		Groups []LldapGroup `json:"groups"`
+	}
+	query := LldapClientQuery{
+		Query:         "query GetGroupListWithAttributes {groups {id displayName creationDate uuid attributes {name value}}}",
+		OperationName: "GetGroupListWithAttributes",
+	}
+	response, responseDiagErr := lc.query(query)
+	if responseDiagErr != nil {
+		return nil, responseDiagErr
+	}
+	groups := LldapClientResponse[LldapGroupListResponseData]{}
+	unmarshErr := json.Unmarshal(response, &groups)
+	if unmarshErr != nil {
+		return nil, diag.FromErr(unmarshErr)
+	}
+	if groups.Errors != nil {
+		return nil, diag.Errorf("GraphQL query returned error: %s", string(response))
+	}
+	return groups.Data.Groups, nil
+}
+
 func (lc *LldapClient) GetUsers() ([]LldapUser, diag.Diagnostics) {
+	return lc.getUsers(nil)
+}
+
+// GetUsersWithAttributes returns all users including their attributes.
+func (lc *LldapClient) GetUsersWithAttributes() ([]LldapUser, diag.Diagnostics) {
+	type LldapUserListResponseData struct {
+		Users []LldapUser `json:"users"`
+	}
+	query := LldapClientQuery{
+		Query:         "query ListUsersWithAttributesQuery {users {id email displayName firstName lastName creationDate uuid avatar attributes {name value}}}",
+		OperationName: "ListUsersWithAttributesQuery",
+	}
+	response, responseDiagErr := lc.query(query)
+	if responseDiagErr != nil {
+		return nil, responseDiagErr
+	}
+	users := LldapClientResponse[LldapUserListResponseData]{}
+	unmarshErr := json.Unmarshal(response, &users)
+	if unmarshErr != nil {
+		return nil, diag.FromErr(unmarshErr)
+	}
+	if users.Errors != nil {
+		return nil, diag.Errorf("GraphQL query returned error: %s", string(response))
+	}
+	return users.Data.Users, nil
+}
+
+// GetUserByEmail returns the single user with the given email address.
+func (lc *LldapClient) GetUserByEmail(email string) (*LldapUser, diag.Diagnostics) {
+	return lc.getUserByField("email", email)
+}
+
+// GetUserByUuid returns the single user with the given UUID.
+func (lc *LldapClient) GetUserByUuid(uuid string) (*LldapUser, diag.Diagnostics) {
+	return lc.getUserByField("uuid", uuid)
+}
+
+func (lc *LldapClient) getUserByField(field string, value string) (*LldapUser, diag.Diagnostics) {
+	type EqualityConstraint struct {
+		Field string `json:"field"`
+		Value string `json:"value"`
+	}
+	type RequestFilter struct {
+		Eq EqualityConstraint `json:"eq"`
+	}
+	users, getUsersErr := lc.getUsers(&RequestFilter{
+		Eq: EqualityConstraint{
+			Field: field,
+			Value: value,
+		},
+	})
+	if getUsersErr != nil {
+		return nil, getUsersErr
+	}
+	if len(users) == 0 {
+		return nil, diag.Errorf("Entity not found: no user with %s '%s'", field, value)
+	}
+	if len(users) > 1 {
+		matchingIds := make([]string, 0, len(users))
+		for _, user := range users {
+			matchingIds = append(matchingIds, user.Id)
+		}
+		return nil, diag.Errorf("%s '%s' is ambiguous, matching user ids: %s", field, value, strings.Join(matchingIds, ", "))
+	}
+	return lc.GetUser(users[0].Id)
+}
+
+func (lc *LldapClient) getUsers(filters any) ([]LldapUser, diag.Diagnostics) {
+	type ListUsersVariables struct {
+		Filters any `json:"filters"`
+	}
 	type LldapUserListResponseData struct {
 		Users []LldapUser `json:"users"`
 	}
 	query := LldapClientQuery{
 		Query:         "query ListUsersQuery($filters: RequestFilter) {users(filters: $filters) {id email displayName firstName lastName creationDate uuid avatar}}",
 		OperationName: "ListUsersQuery",
+		Variables: ListUsersVariables{
+			Filters: filters,
+		},
 	}
 	response, responseDiagErr := lc.query(query)
 	if responseDiagErr != nil {
@@ -12,8 +12,11 @@ package lldap
 import (
 	"context"
 	"fmt"
+	"maps"
 	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -96,6 +99,31 @@ func TestSetUserPasswords(t *testing.T) {
 	}
 }
 
+func TestSetUserPasswordDnSpecialCharacters(t *testing.T) {
+	client := getTestClient()
+	// An unescaped '+' starts a multi-valued RDN, so this only binds with DN escaping
+	userId := randomTestSuffix("testsetuserpassword+dn")
+	createErr := client.CreateUser(&LldapUser{
+		Id:    userId,
+		Email: strings.ReplaceAll(userId, "+", "-") + "@test.local",
+	})
+	assert.Nil(t, createErr)
+
+	setErr := client.SetUserPassword(userId, "newpassword")
+	assert.Nil(t, setErr)
+
+	valid, validErr := client.IsValidPassword(userId, "newpassword")
+	assert.Nil(t, validErr)
+	assert.True(t, valid)
+
+	invalid, invalidErr := client.IsValidPassword(userId, "wrongpassword")
+	assert.Nil(t, invalidErr)
+	assert.False(t, invalid)
+
+	// Clean up
+	client.DeleteUser(userId)
+}
+
 func TestGetGroupAttributesSchema(t *testing.T) {
 	client := getTestClient()
 	getGroupAttr, getGroupAttrErr := client.GetGroupAttributesSchema()
@@ -664,6 +692,56 @@ func TestGetUsers(t *testing.T) {
 	assert.True(t, found, "Admin user not found")
 }
 
+func TestGetUsersWithAttributes(t *testing.T) {
+	client := getTestClient()
+	result, getErr := client.GetUsersWithAttributes()
+	assert.Nil(t, getErr)
+	assert.Greater(t, len(result), 0)
+
+	// Check that users come with their attributes
+	for _, user := range result {
+		if user.Id == "admin" {
+			filter, parseErr := ParseUserFilter("(&(user_id=admin)(mail=*))")
+			assert.Nil(t, parseErr)
+			assert.True(t, filter.Matches(&user))
+			assert.NotEmpty(t, user.Attributes)
+		}
+	}
+}
+
+func TestGetUserByEmailAndUuid(t *testing.T) {
+	client := getTestClient()
+	userId := strings.ToLower(randomTestSuffix("TestGetUserByEmailAndUuid"))
+	user := LldapUser{
+		Id:          userId,
+		Email:       userId + "@test.local",
+		DisplayName: "Test User",
+	}
+	createErr := client.CreateUser(&user)
+	assert.Nil(t, createErr)
+
+	byEmail, getByEmailErr := client.GetUserByEmail(user.Email)
+	assert.Nil(t, getByEmailErr)
+	assert.NotNil(t, byEmail)
+	assert.Equal(t, userId, byEmail.Id)
+
+	byUuid, getByUuidErr := client.GetUserByUuid(user.Uuid)
+	assert.Nil(t, getByUuidErr)
+	assert.NotNil(t, byUuid)
+	assert.Equal(t, userId, byUuid.Id)
+
+	// Clean up
+	client.DeleteUser(userId)
+}
+
+func TestGetUserByEmailErr(t *testing.T) {
+	client := getTestClient()
+	user, getErr := client.GetUserByEmail(randomTestSuffix("TestGetUserByEmailErr") + "@test.local")
+	assert.Nil(t, user)
+	assert.NotNil(t, getErr)
+	assert.True(t, isEntityNotFoundError(getErr))
+}
+
 func TestGetGroup(t *testing.T) {
 	client := getTestClient()
 	result, getErr := client.GetGroup(1)
@@ -728,3 +806,397 @@ func TestGetUserErr(t *testing.T) {
 	assert.NotNil(t, getErr)
 	assert.Nil(t, result)
 }
+
+func TestAddUserObjectClass(t *testing.T) {
+	client := getTestClient()
+	objectClass := strings.ReplaceAll(strings.ToLower(randomTestSuffix("TestAddUserObjectClass")), "-", "")
+	addErr := client.AddUserObjectClass(objectClass)
+	assert.Nil(t, addErr)
+
+	objectClasses, getErr := client.GetUserObjectClasses()
+	assert.Nil(t, getErr)
+	assert.Contains(t, objectClasses, objectClass)
+
+	deleteErr := client.DeleteUserObjectClass(objectClass)
+	assert.Nil(t, deleteErr)
+	objectClasses, getErr = client.GetUserObjectClasses()
+	assert.Nil(t, getErr)
+	assert.NotContains(t, objectClasses, objectClass)
+}
+
+func TestAddGroupObjectClass(t *testing.T) {
+	client := getTestClient()
+	objectClass := strings.ReplaceAll(strings.ToLower(randomTestSuffix("TestAddGroupObjectClass")), "-", "")
+	addErr := client.AddGroupObjectClass(objectClass)
+	assert.Nil(t, addErr)
+
+	objectClasses, getErr := client.GetGroupObjectClasses()
+	assert.Nil(t, getErr)
+	assert.Contains(t, objectClasses, objectClass)
+
+	deleteErr := client.DeleteGroupObjectClass(objectClass)
+	assert.Nil(t, deleteErr)
+	objectClasses, getErr = client.GetGroupObjectClasses()
+	assert.Nil(t, getErr)
+	assert.NotContains(t, objectClasses, objectClass)
+}
+
+func TestGetGroupByDisplayName(t *testing.T) {
+	client := getTestClient()
+	group := LldapGroup{
+		DisplayName: randomTestSuffix("TestGetGroupByDisplayName"),
+	}
+	createErr := client.CreateGroup(&group)
+	assert.Nil(t, createErr)
+
+	found, getErr := client.GetGroupByDisplayName(group.DisplayName)
+	assert.Nil(t, getErr)
+	assert.NotNil(t, found)
+	assert.Equal(t, group.Id, found.Id)
+
+	// Clean up
+	client.DeleteGroup(group.Id)
+}
+
+func TestGetGroupByDisplayNameErr(t *testing.T) {
+	client := getTestClient()
+	group, getErr := client.GetGroupByDisplayName(randomTestSuffix("TestGetGroupByDisplayNameErr"))
+	assert.Nil(t, group)
+	assert.NotNil(t, getErr)
+	assert.True(t, isEntityNotFoundError(getErr))
+}
+
+func TestSetGroupInclusions(t *testing.T) {
+	client := getTestClient()
+	parent := LldapGroup{DisplayName: randomTestSuffix("TestSetGroupInclusionsParent")}
+	child := LldapGroup{DisplayName: randomTestSuffix("TestSetGroupInclusionsChild")}
+	assert.Nil(t, client.CreateGroup(&parent))
+	assert.Nil(t, client.CreateGroup(&child))
+
+	setErr := client.SetGroupInclusions(parent.Id, []int{child.Id})
+	assert.Nil(t, setErr)
+
+	groups, getGroupsErr := client.GetGroupsWithMembers()
+	assert.Nil(t, getGroupsErr)
+	inclusions := GroupInclusionsFromGroups(groups)
+	assert.Equal(t, []int{child.Id}, inclusions[parent.Id])
+	assert.NotNil(t, CheckGroupInclusions(inclusions, child.Id, []int{parent.Id}))
+
+	removeErr := client.SetGroupInclusions(parent.Id, nil)
+	assert.Nil(t, removeErr)
+	groups, getGroupsErr = client.GetGroupsWithMembers()
+	assert.Nil(t, getGroupsErr)
+	assert.Empty(t, GroupInclusionsFromGroups(groups)[parent.Id])
+
+	// Clean up
+	client.DeleteGroup(parent.Id)
+	client.DeleteGroup(child.Id)
+}
+
+func TestMarkManaged(t *testing.T) {
+	client := getTestClient()
+	client.Config.ManagedMarker = "tf_managed_marker_test"
+	client.Config.ReconcileManagedOnly = true
+	managedUser := LldapUser{
+		Id:    randomTestSuffix("TestMarkManagedUser"),
+		Email: randomTestSuffix("TestMarkManagedUser") + "@example.com",
+	}
+	unmanagedUser := LldapUser{
+		Id:    randomTestSuffix("TestMarkUnmanagedUser"),
+		Email: randomTestSuffix("TestMarkUnmanagedUser") + "@example.com",
+	}
+	group := LldapGroup{DisplayName: randomTestSuffix("TestMarkManagedGroup")}
+	assert.Nil(t, client.CreateUser(&managedUser))
+	assert.Nil(t, client.CreateUser(&unmanagedUser))
+	assert.Nil(t, client.CreateGroup(&group))
+
+	assert.Nil(t, client.MarkUserManaged(&managedUser))
+	assert.Nil(t, client.MarkGroupManaged(&group))
+
+	managedUserIds, getUsersErr := client.GetManagedUserIds()
+	assert.Nil(t, getUsersErr)
+	assert.Contains(t, managedUserIds, managedUser.Id)
+	assert.NotContains(t, managedUserIds, unmanagedUser.Id)
+	managedGroupIds, getGroupsErr := client.GetManagedGroupIds()
+	assert.Nil(t, getGroupsErr)
+	assert.Contains(t, managedGroupIds, group.Id)
+
+	unmanagedUserIds, unmanagedErr := client.unmanagedUserIds([]string{managedUser.Id, unmanagedUser.Id})
+	assert.Nil(t, unmanagedErr)
+	assert.Equal(t, []string{unmanagedUser.Id}, unmanagedUserIds)
+
+	// A membership is only managed if both the user and the group are
+	unmanagedMembers, membersErr := client.unmanagedGroupMembers(group.Id, []string{managedUser.Id, unmanagedUser.Id})
+	assert.Nil(t, membersErr)
+	assert.Equal(t, []string{unmanagedUser.Id}, unmanagedMembers)
+	unmanagedGroups, groupsErr := client.unmanagedUserGroups(unmanagedUser.Id, []int{group.Id})
+	assert.Nil(t, groupsErr)
+	assert.Equal(t, []int{group.Id}, unmanagedGroups)
+	unmanagedGroup := LldapGroup{DisplayName: randomTestSuffix("TestMarkUnmanagedGroup")}
+	assert.Nil(t, client.CreateGroup(&unmanagedGroup))
+	unmanagedMembers, membersErr = client.unmanagedGroupMembers(unmanagedGroup.Id, []string{managedUser.Id})
+	assert.Nil(t, membersErr)
+	assert.Equal(t, []string{managedUser.Id}, unmanagedMembers)
+	client.DeleteGroup(unmanagedGroup.Id)
+
+	// Clean up
+	client.DeleteUser(managedUser.Id)
+	client.DeleteUser(unmanagedUser.Id)
+	client.DeleteGroup(group.Id)
+	client.DeleteUserAttribute(client.Config.ManagedMarker)
+	client.DeleteGroupAttribute(client.Config.ManagedMarker)
+}
+
+func TestLdapSearch(t *testing.T) {
+	client := getTestClient()
+	user := LldapUser{
+		Id:    strings.ToLower(randomTestSuffix("TestLdapSearchUser")),
+		Email: randomTestSuffix("TestLdapSearchUser") + "@example.com",
+	}
+	group := LldapGroup{DisplayName: randomTestSuffix("TestLdapSearchGroup")}
+	assert.Nil(t, client.CreateUser(&user))
+	assert.Nil(t, client.CreateGroup(&group))
+	assert.Nil(t, client.AddUserToGroup(group.Id, user.Id))
+
+	entries, searchErr := client.LdapSearch(
+		client.Config.BaseDn,
+		LdapSearchScopeSub,
+		MemberOfFilter([]string{group.DisplayName}, client.Config.BaseDn),
+		[]string{"uid", "mail"},
+		0,
+	)
+	assert.Nil(t, searchErr)
+	assert.Len(t, entries, 1)
+	if len(entries) == 1 {
+		assert.Equal(t, UserDn(user.Id, client.Config.BaseDn), entries[0].Dn)
+		assert.Equal(t, []string{user.Id}, entries[0].Attributes["uid"])
+	}
+
+	groupEntries, groupSearchErr := client.LdapSearch(GroupDn(group.DisplayName, client.Config.BaseDn), LdapSearchScopeBase, LdapSearchDefaultFilter, nil, 0)
+	assert.Nil(t, groupSearchErr)
+	assert.Len(t, groupEntries, 1)
+
+	noEntries, noSearchErr := client.LdapSearch(client.Config.BaseDn, LdapSearchScopeSub, "(uid=does-not-exist)", nil, 0)
+	assert.Nil(t, noSearchErr)
+	assert.Empty(t, noEntries)
+
+	// Clean up
+	client.DeleteUser(user.Id)
+	client.DeleteGroup(group.Id)
+}
+
+func TestApplyUserChanges(t *testing.T) {
+	client := getTestClient()
+	desired := map[string]LldapUser{}
+	for i := range BulkUserBatchSize + 5 {
+		userId := strings.ToLower(randomTestSuffix(fmt.Sprintf("TestApplyUserChanges%d", i)))
+		desired[userId] = LldapUser{Email: userId + "@example.com"}
+	}
+	currentUsers, getUsersErr := client.GetUsers()
+	assert.Nil(t, getUsersErr)
+	changes := DiffBulkUsers(desired, nil, currentUsers)
+	assert.Len(t, changes, len(desired))
+	// The duplicate email fails only for its own user
+	duplicate := changes[len(changes)-1]
+	duplicate.User.Id = strings.ToLower(randomTestSuffix("TestApplyUserChangesDuplicate"))
+	changes = append(changes, duplicate)
+	results := client.ApplyUserChanges(changes)
+	assert.Len(t, results, len(changes))
+	for _, result := range results[:len(desired)] {
+		assert.Nil(t, result.Err, result.UserId)
+	}
+	assert.NotNil(t, results[len(desired)].Err)
+
+	currentUsers, getUsersErr = client.GetUsers()
+	assert.Nil(t, getUsersErr)
+	for userId, user := range desired {
+		user.DisplayName = "Updated"
+		desired[userId] = user
+	}
+	userIds := slices.Collect(maps.Keys(desired))
+	changes = DiffBulkUsers(desired, userIds, currentUsers)
+	assert.Len(t, changes, len(desired))
+	for _, result := range client.ApplyUserChanges(changes) {
+		assert.Equal(t, BulkUserUpdate, result.Action)
+		assert.Nil(t, result.Err, result.UserId)
+	}
+	user, getUserErr := client.GetUser(userIds[0])
+	assert.Nil(t, getUserErr)
+	assert.Equal(t, "Updated", user.DisplayName)
+
+	currentUsers, getUsersErr = client.GetUsers()
+	assert.Nil(t, getUsersErr)
+	for _, result := range client.ApplyUserChanges(DiffBulkUsers(map[string]LldapUser{}, userIds, currentUsers)) {
+		assert.Equal(t, BulkUserDelete, result.Action)
+		assert.Nil(t, result.Err, result.UserId)
+	}
+	_, getDeletedErr := client.GetUser(userIds[0])
+	assert.NotNil(t, getDeletedErr)
+}
+
+func TestPreflight(t *testing.T) {
+	client := getTestClient()
+	assert.Nil(t, client.Preflight())
+	assert.Equal(t, LldapRoleAdmin, client.role)
+
+	wrongBaseDn := getTestClient()
+	wrongBaseDn.Config.BaseDn = "dc=example,dc=com"
+	diags := wrongBaseDn.Preflight()
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "base_dn")
+
+	wrongPassword := getTestClient()
+	wrongPassword.Config.Password = "wrong password"
+	diags = wrongPassword.Preflight()
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "could not authenticate")
+}
+
+func TestAuthenticateWithTokens(t *testing.T) {
+	client := getTestClient()
+	assert.Nil(t, client.Authenticate())
+
+	tokenClient := getTestClient()
+	tokenClient.Config.Password = ""
+	tokenClient.Config.UserName = ""
+	tokenClient.SetToken(client.Token)
+	assert.Equal(t, "admin", tokenClient.Config.UserName)
+	_, getUsersErr := tokenClient.GetUsers()
+	assert.Nil(t, getUsersErr)
+
+	refreshClient := getTestClient()
+	refreshClient.Config.Password = ""
+	refreshClient.RefreshToken = client.RefreshToken
+	_, getUsersErr = refreshClient.GetUsers()
+	assert.Nil(t, getUsersErr)
+	assert.NotEmpty(t, refreshClient.Token)
+}
+
+func TestRenameUser(t *testing.T) {
+	client := getTestClient()
+	userId := strings.ToLower(randomTestSuffix("TestRenameUser"))
+	existingId := strings.ToLower(randomTestSuffix("TestRenameUserExisting"))
+	email := userId + "@test.local"
+	assert.Nil(t, client.CreateUser(&LldapUser{Id: userId, Email: email, FirstName: "Renamed"}))
+	assert.Nil(t, client.CreateUser(&LldapUser{Id: existingId, Email: existingId + "@test.local"}))
+
+	// A failed migration leaves the old user as it was
+	_, renameErr := client.RenameUser(userId, existingId, "")
+	assert.NotNil(t, renameErr)
+	user, getUserErr := client.GetUser(userId)
+	assert.Nil(t, getUserErr)
+	assert.Equal(t, email, user.Email)
+
+	newId := strings.ToLower(randomTestSuffix("TestRenameUserNew"))
+	renamed, renameErr := client.RenameUser(userId, newId, "renamed password")
+	assert.Nil(t, renameErr)
+	assert.Equal(t, newId, renamed.Id)
+	assert.Equal(t, email, renamed.Email)
+	assert.Equal(t, "Renamed", renamed.FirstName)
+	isValid, bindErr := client.IsValidPassword(newId, "renamed password")
+	assert.Nil(t, bindErr)
+	assert.True(t, isValid)
+	_, getUserErr = client.GetUser(userId)
+	assert.True(t, isEntityNotFoundError(getUserErr))
+
+	// Clean up
+	client.DeleteUser(newId)
+	client.DeleteUser(existingId)
+}
+
+func TestMigrateUserAttribute(t *testing.T) {
+	client := getTestClient()
+	attrName := strings.ToLower(randomTestSuffix("TestMigrateUserAttribute"))
+	userId := randomTestSuffix("TestMigrateUserAttribute")
+	createAttrErr := client.CreateUserAttribute(attrName, AttributeTypeString, false, true, true)
+	assert.Nil(t, createAttrErr)
+	createUserErr := client.CreateUser(&LldapUser{Id: userId, Email: userId + "@test.local"})
+	assert.Nil(t, createUserErr)
+	addErr := client.AddAttributeToUser(userId, attrName, []string{"042"})
+	assert.Nil(t, addErr)
+
+	migrateErr := client.MigrateUserAttribute(LldapUserAttributeSchema{
+		Name: attrName, AttributeType: AttributeTypeInteger, IsList: true, IsVisible: true, IsEditable: true,
+	})
+	assert.Nil(t, migrateErr)
+	schema, getSchemaErr := client.GetUserAttributeSchema(attrName)
+	assert.Nil(t, getSchemaErr)
+	assert.Equal(t, AttributeTypeInteger, schema.AttributeType)
+	assert.True(t, schema.IsList)
+	values, valuesErr := client.UserAttributeValues(attrName)
+	assert.Nil(t, valuesErr)
+	assert.Equal(t, []string{"42"}, values[userId])
+
+	// Values that cannot be converted leave the attribute as it was
+	addErr = client.AddAttributeToUser(userId, attrName, []string{"1", "2"})
+	assert.Nil(t, addErr)
+	failedErr := client.MigrateUserAttribute(LldapUserAttributeSchema{
+		Name: attrName, AttributeType: AttributeTypeInteger, IsList: false, IsVisible: true, IsEditable: true,
+	})
+	assert.NotNil(t, failedErr)
+	schema, getSchemaErr = client.GetUserAttributeSchema(attrName)
+	assert.Nil(t, getSchemaErr)
+	assert.True(t, schema.IsList)
+
+	// Clean up
+	client.DeleteUser(userId)
+	client.DeleteUserAttribute(attrName)
+}
+
+func TestMigrateGroupAttribute(t *testing.T) {
+	client := getTestClient()
+	attrName := strings.ToLower(randomTestSuffix("TestMigrateGroupAttribute"))
+	group := LldapGroup{DisplayName: randomTestSuffix("TestMigrateGroupAttribute")}
+	createAttrErr := client.CreateGroupAttribute(attrName, AttributeTypeString, true, true)
+	assert.Nil(t, createAttrErr)
+	createGroupErr := client.CreateGroup(&group)
+	assert.Nil(t, createGroupErr)
+	addErr := client.AddAttributeToGroup(group.Id, attrName, []string{"2024-01-01T00:00:00Z"})
+	assert.Nil(t, addErr)
+
+	migrateErr := client.MigrateGroupAttribute(LldapGroupAttributeSchema{
+		Name: attrName, AttributeType: AttributeTypeDateTime, IsList: false, IsVisible: true,
+	})
+	assert.Nil(t, migrateErr)
+	schema, getSchemaErr := client.GetGroupAttributeSchema(attrName)
+	assert.Nil(t, getSchemaErr)
+	assert.Equal(t, AttributeTypeDateTime, schema.AttributeType)
+	assert.False(t, schema.IsList)
+	values, valuesErr := client.GroupAttributeValues(attrName)
+	assert.Nil(t, valuesErr)
+	assert.Len(t, values[strconv.Itoa(group.Id)], 1)
+
+	// Clean up
+	client.DeleteGroup(group.Id)
+	client.DeleteGroupAttribute(attrName)
+}
+
+func TestCustomAttributesFromSchema(t *testing.T) {
+	client := getTestClient()
+	attrName := strings.ToLower(randomTestSuffix("TestCustomAttributesFromSchema"))
+	group := LldapGroup{DisplayName: randomTestSuffix("TestCustomAttributesFromSchema")}
+	createAttrErr := client.CreateGroupAttribute(attrName, AttributeTypeString, false, true)
+	assert.Nil(t, createAttrErr)
+	createGroupErr := client.CreateGroup(&group)
+	assert.Nil(t, createGroupErr)
+	addErr := client.AddAttributeToGroup(group.Id, attrName, []string{"custom"})
+	assert.Nil(t, addErr)
+	createdGroup, getGroupErr := client.GetGroup(group.Id)
+	assert.Nil(t, getGroupErr)
+
+	// Only the custom attribute is left, built-ins such as display_name are not
+	attributes, attributesErr := client.GroupCustomAttributes(createdGroup)
+	assert.Nil(t, attributesErr)
+	assert.Equal(t, []LldapCustomAttribute{{Name: attrName, Value: []string{"custom"}}}, attributes)
+	user, getUserErr := client.GetUser("admin")
+	assert.Nil(t, getUserErr)
+	userAttributes, userAttributesErr := client.UserCustomAttributes(user)
+	assert.Nil(t, userAttributesErr)
+	for _, attribute := range userAttributes {
+		assert.NotContains(t, []string{"mail", "user_id", "display_name", "creation_date", "uuid"}, attribute.Name)
+	}
+
+	// Clean up
+	client.DeleteGroup(group.Id)
+	client.DeleteGroupAttribute(attrName)
+}
@@ -1,35 +0,0 @@
-/*
- * This Source Code Form is subject to the terms of the Mozilla Public
- * License, v. 2.0. If a copy of the MPL was not distributed with this
- * file, You can obtain one at https://mozilla.org/MPL/2.0/.
- */
-
-package lldap
-
-import (
-	"testing"
-
-	"github.com/stretchr/testify/assert"
-)
-
-func TestLldapUserGetCustomAttributes(t *testing.T) {
-	user := LldapUser{
-		Attributes: []LldapCustomAttribute{
-			{
-				Name: "first_name",
-			},
-			{
-				Name: "custom",
-			},
-			{
-				Name: "avatar",
-			},
-		},
-	}
-	expected := []LldapCustomAttribute{
-		{
-			Name: "custom",
-		},
-	}
-	assert.Equal(t, expected, user.GetCustomAttributes())
-}
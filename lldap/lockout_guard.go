@@ -0,0 +1,91 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// LldapAdminGroupName is the built-in group granting full administrative access.
+const LldapAdminGroupName = "lldap_admin"
+
+// lockoutGuard returns the client if mutations revoking the provider's own admin access must be refused.
+func lockoutGuard(m any) (*LldapClient, bool) {
+	lc, ok := m.(*LldapClient)
+	if !ok || lc.Config.AllowAdminLockout {
+		return nil, false
+	}
+	return lc, true
+}
+
+// isProviderUser reports whether userId is the user the provider authenticates as.
+func (lc *LldapClient) isProviderUser(userId string) bool {
+	return strings.EqualFold(userId, lc.Config.UserName)
+}
+
+// getAdminGroupId returns the id of the lldap_admin group, or -1 if it does not exist.
+func (lc *LldapClient) getAdminGroupId() (int, diag.Diagnostics) {
+	groups, getGroupsErr := lc.GetGroups()
+	if getGroupsErr != nil {
+		return -1, getGroupsErr
+	}
+	for _, group := range groups {
+		if group.DisplayName == LldapAdminGroupName {
+			return group.Id, nil
+		}
+	}
+	return -1, nil
+}
+
+// lockoutError describes a refused mutation.
+func (lc *LldapClient) lockoutError(action string) error {
+	return fmt.Errorf(
+		"%s would revoke the admin access of provider user '%s', which breaks every later API call. Set allow_admin_lockout = true in the provider configuration to allow it",
+		action, lc.Config.UserName)
+}
+
+// checkGroupMembersRemoval fails if removing userIds from the group drops the provider user from lldap_admin.
+func (lc *LldapClient) checkGroupMembersRemoval(groupId int, userIds []string) error {
+	if !slices.ContainsFunc(userIds, lc.isProviderUser) {
+		return nil
+	}
+	group, getGroupErr := lc.GetGroup(groupId)
+	if getGroupErr != nil {
+		return fmt.Errorf("could not read group %d: %s", groupId, getGroupErr[0].Summary)
+	}
+	if group.DisplayName != LldapAdminGroupName || !slices.ContainsFunc(group.GetUserIds(), lc.isProviderUser) {
+		return nil
+	}
+	return lc.lockoutError(fmt.Sprintf("Removing user '%s' from group %s", lc.Config.UserName, LldapAdminGroupName))
+}
+
+// checkUserGroupsRemoval fails if removing the user from groupIds drops the provider user from lldap_admin.
+func (lc *LldapClient) checkUserGroupsRemoval(userId string, groupIds []int) error {
+	if !lc.isProviderUser(userId) || len(groupIds) == 0 {
+		return nil
+	}
+	adminGroupId, getAdminGroupIdErr := lc.getAdminGroupId()
+	if getAdminGroupIdErr != nil {
+		return fmt.Errorf("could not look up group %s: %s", LldapAdminGroupName, getAdminGroupIdErr[0].Summary)
+	}
+	if !slices.Contains(groupIds, adminGroupId) {
+		return nil
+	}
+	return lc.lockoutError(fmt.Sprintf("Removing user '%s' from group %s", lc.Config.UserName, LldapAdminGroupName))
+}
+
+// checkUsersRemoval fails if the provider user is in priorUserIds but not in userIds, which deletes it.
+func (lc *LldapClient) checkUsersRemoval(priorUserIds []string, userIds []string) error {
+	if !slices.ContainsFunc(priorUserIds, lc.isProviderUser) || slices.ContainsFunc(userIds, lc.isProviderUser) {
+		return nil
+	}
+	return lc.lockoutError(fmt.Sprintf("Deleting user '%s'", lc.Config.UserName))
+}
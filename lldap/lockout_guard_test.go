@@ -0,0 +1,108 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockoutGuard(t *testing.T) {
+	lc, guarded := lockoutGuard(&LldapClient{Config: Config{UserName: "admin"}})
+	assert.True(t, guarded)
+	assert.True(t, lc.isProviderUser("admin"))
+	assert.True(t, lc.isProviderUser("Admin"))
+	assert.False(t, lc.isProviderUser("someone"))
+
+	_, guarded = lockoutGuard(&LldapClient{Config: Config{UserName: "admin", AllowAdminLockout: true}})
+	assert.False(t, guarded)
+	_, guarded = lockoutGuard(nil)
+	assert.False(t, guarded)
+}
+
+func TestLockoutGuardOtherUsers(t *testing.T) {
+	// Checks for users other than the provider user must not call the API
+	lc := &LldapClient{Config: Config{UserName: "admin"}}
+	assert.Nil(t, lc.checkGroupMembersRemoval(1, []string{"someone", "else"}))
+	assert.Nil(t, lc.checkUserGroupsRemoval("someone", []int{1}))
+	assert.Nil(t, lc.checkUserGroupsRemoval("admin", []int{}))
+}
+
+func TestLockoutGuardDelete(t *testing.T) {
+	lc := &LldapClient{Config: Config{UserName: "admin"}}
+
+	user := &userResource{client: lc}
+	userState := testResourceState(t, user, map[string]any{"id": "admin", "username": "admin"})
+	userResp := &resource.DeleteResponse{State: userState}
+	user.Delete(t.Context(), resource.DeleteRequest{State: userState}, userResp)
+	assert.True(t, userResp.Diagnostics.HasError())
+	assert.Contains(t, userResp.Diagnostics[0].Detail(), "allow_admin_lockout")
+
+	group := &groupResource{client: lc}
+	groupState := testResourceState(t, group, map[string]any{"id": "1", "display_name": LldapAdminGroupName})
+	groupResp := &resource.DeleteResponse{State: groupState}
+	group.Delete(t.Context(), resource.DeleteRequest{State: groupState}, groupResp)
+	assert.True(t, groupResp.Diagnostics.HasError())
+	assert.Contains(t, groupResp.Diagnostics[0].Detail(), "Deleting group lldap_admin")
+}
+
+func TestLockoutGuardUsersRemoval(t *testing.T) {
+	lc := &LldapClient{Config: Config{UserName: "admin"}}
+	assert.Nil(t, lc.checkUsersRemoval([]string{"admin", "someone"}, []string{"admin"}))
+	assert.Nil(t, lc.checkUsersRemoval([]string{"someone"}, []string{}))
+	removalErr := lc.checkUsersRemoval([]string{"admin", "someone"}, []string{"someone"})
+	assert.NotNil(t, removalErr)
+	assert.Contains(t, removalErr.Error(), "Deleting user 'admin'")
+}
+
+func TestLockoutGuardGroupMembershipResources(t *testing.T) {
+	removedUserIds := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query LldapClientQuery
+		_ = json.NewDecoder(r.Body).Decode(&query)
+		switch query.OperationName {
+		case "GetGroupDetails":
+			_, _ = fmt.Fprintf(w, `{"data":{"group":{"id":1,"displayName":"%s","users":[{"id":"admin"},{"id":"bob"}]}}}`, LldapAdminGroupName)
+		case "RemoveUserFromGroup":
+			removedUserIds = append(removedUserIds, query.Variables.(map[string]any)["user"].(string))
+			_, _ = fmt.Fprint(w, `{"data":{"removeUserFromGroup":{"ok":true}}}`)
+		}
+	}))
+	defer server.Close()
+	httpUrl, _ := url.Parse(server.URL)
+	lc := &LldapClient{Config: Config{HttpUrl: httpUrl, UserName: "admin"}, HttpClient: server.Client()}
+	lc.SetToken(testToken("admin", time.Now().Add(time.Hour)))
+
+	dynamicGroup := resourceDynamicGroup().TestResourceData()
+	dynamicGroup.SetId("1")
+	assert.Nil(t, dynamicGroup.Set("group_id", 1))
+	assert.Nil(t, dynamicGroup.Set("members", []string{"admin", "bob"}))
+	diags := resourceDynamicGroupDelete(context.Background(), dynamicGroup, lc)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "allow_admin_lockout")
+
+	inclusion := resourceGroupInclusion().TestResourceData()
+	inclusion.SetId("1")
+	assert.Nil(t, inclusion.Set("parent_group_id", 1))
+	assert.Nil(t, inclusion.Set("child_group_ids", []int{2}))
+	assert.Nil(t, inclusion.Set("members", []string{"admin", "bob"}))
+	diags = resourceGroupInclusionDelete(context.Background(), inclusion, lc)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "allow_admin_lockout")
+
+	// Deletions are refused before any membership is removed
+	assert.Empty(t, removedUserIds)
+}
@@ -0,0 +1,236 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// Users and groups created by the provider are tagged with the managed_marker
+// custom attribute. The attribute schemas are created on first use.
+const ManagedMarkerValue = "terraform"
+
+// IsManaged reports whether the attributes carry the managed marker.
+func IsManaged(attributes []LldapCustomAttribute, marker string) bool {
+	return slices.ContainsFunc(attributes, func(attr LldapCustomAttribute) bool {
+		return attr.Name == marker && slices.Contains(attr.Value, ManagedMarkerValue)
+	})
+}
+
+// reconcileManagedOnly reports whether authoritative resources must leave unmanaged objects untouched.
+func (lc *LldapClient) reconcileManagedOnly() bool {
+	return lc.Config.ManagedMarker != "" && lc.Config.ReconcileManagedOnly
+}
+
+func (lc *LldapClient) managedMarkerAttribute() LldapCustomAttribute {
+	return LldapCustomAttribute{
+		Name:  lc.Config.ManagedMarker,
+		Value: []string{ManagedMarkerValue},
+	}
+}
+
+// MarkUserManaged tags the user with the managed marker, if one is configured.
+func (lc *LldapClient) MarkUserManaged(user *LldapUser) diag.Diagnostics {
+	if lc.Config.ManagedMarker == "" {
+		return nil
+	}
+	schemaErr := lc.ensureManagedMarkerUserAttribute()
+	if schemaErr != nil {
+		return schemaErr
+	}
+	marker := lc.managedMarkerAttribute()
+	updateErr := lc.updateUser(user, nil, []LldapCustomAttribute{marker})
+	if updateErr != nil {
+		return updateErr
+	}
+	user.Attributes = append(slices.DeleteFunc(user.Attributes, func(attr LldapCustomAttribute) bool {
+		return attr.Name == marker.Name
+	}), marker)
+	return nil
+}
+
+// ensureManagedMarkerUserAttribute creates the user attribute schema of the managed marker, if it does not exist.
+func (lc *LldapClient) ensureManagedMarkerUserAttribute() diag.Diagnostics {
+	attributeSchema, getSchemaErr := lc.GetUserAttributeSchema(lc.Config.ManagedMarker)
+	if getSchemaErr != nil {
+		return getSchemaErr
+	}
+	if attributeSchema == nil {
+		return lc.CreateUserAttribute(lc.Config.ManagedMarker, AttributeTypeString, false, false, false)
+	}
+	return nil
+}
+
+// MarkGroupManaged tags the group with the managed marker, if one is configured.
+func (lc *LldapClient) MarkGroupManaged(group *LldapGroup) diag.Diagnostics {
+	if lc.Config.ManagedMarker == "" {
+		return nil
+	}
+	attributeSchema, getSchemaErr := lc.GetGroupAttributeSchema(lc.Config.ManagedMarker)
+	if getSchemaErr != nil {
+		return getSchemaErr
+	}
+	if attributeSchema == nil {
+		createErr := lc.CreateGroupAttribute(lc.Config.ManagedMarker, AttributeTypeString, false, false)
+		if createErr != nil {
+			return createErr
+		}
+	}
+	marker := lc.managedMarkerAttribute()
+	updateErr := lc.updateGroup(group, nil, []LldapCustomAttribute{marker})
+	if updateErr != nil {
+		return updateErr
+	}
+	group.Attributes = append(slices.DeleteFunc(group.Attributes, func(attr LldapCustomAttribute) bool {
+		return attr.Name == marker.Name
+	}), marker)
+	return nil
+}
+
+// canMarkManaged reports whether existing objects can be tagged, which read-only providers
+// and roles that cannot make mutations cannot do.
+func (lc *LldapClient) canMarkManaged() bool {
+	return lc.Config.ManagedMarker != "" && !lc.Config.ReadOnly && lc.checkMutationRole("Tagging with managed_marker") == nil
+}
+
+// markUserManagedOnRead tags a user that is managed by Terraform but lacks the managed marker,
+// e.g. because it was imported or created before managed_marker was configured. A failure is
+// only a warning, so it does not fail the refresh.
+func (lc *LldapClient) markUserManagedOnRead(user *LldapUser) diag.Diagnostics {
+	if !lc.canMarkManaged() || IsManaged(user.Attributes, lc.Config.ManagedMarker) {
+		return nil
+	}
+	return managedMarkerWarnings(fmt.Sprintf("user '%s'", user.Id), lc.MarkUserManaged(user))
+}
+
+// markGroupManagedOnRead is markUserManagedOnRead for groups.
+func (lc *LldapClient) markGroupManagedOnRead(group *LldapGroup) diag.Diagnostics {
+	if !lc.canMarkManaged() || IsManaged(group.Attributes, lc.Config.ManagedMarker) {
+		return nil
+	}
+	return managedMarkerWarnings(fmt.Sprintf("group %d", group.Id), lc.MarkGroupManaged(group))
+}
+
+func managedMarkerWarnings(object string, diags diag.Diagnostics) diag.Diagnostics {
+	if len(diags) == 0 {
+		return nil
+	}
+	warnings := make(diag.Diagnostics, len(diags))
+	for i, d := range diags {
+		warnings[i] = diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Could not tag %s with managed_marker", object),
+			Detail:   d.Summary,
+		}
+	}
+	return warnings
+}
+
+// GetManagedUserIds returns the ids of all users carrying the managed marker.
+func (lc *LldapClient) GetManagedUserIds() ([]string, diag.Diagnostics) {
+	users, getUsersErr := lc.GetUsersWithAttributes()
+	if getUsersErr != nil {
+		return nil, getUsersErr
+	}
+	userIds := []string{}
+	for _, user := range users {
+		if IsManaged(user.Attributes, lc.Config.ManagedMarker) {
+			userIds = append(userIds, user.Id)
+		}
+	}
+	return userIds, nil
+}
+
+// GetManagedGroupIds returns the ids of all groups carrying the managed marker.
+func (lc *LldapClient) GetManagedGroupIds() ([]int, diag.Diagnostics) {
+	groups, getGroupsErr := lc.GetGroupsWithMembers()
+	if getGroupsErr != nil {
+		return nil, getGroupsErr
+	}
+	groupIds := []int{}
+	for _, group := range groups {
+		if IsManaged(group.Attributes, lc.Config.ManagedMarker) {
+			groupIds = append(groupIds, group.Id)
+		}
+	}
+	return groupIds, nil
+}
+
+// unmanagedUserIds returns the users among userIds that must be left untouched because they are not managed.
+func (lc *LldapClient) unmanagedUserIds(userIds []string) ([]string, diag.Diagnostics) {
+	if !lc.reconcileManagedOnly() || len(userIds) == 0 {
+		return []string{}, nil
+	}
+	managedUserIds, getManagedErr := lc.GetManagedUserIds()
+	if getManagedErr != nil {
+		return nil, getManagedErr
+	}
+	return slices.DeleteFunc(slices.Clone(userIds), func(userId string) bool {
+		return slices.Contains(managedUserIds, userId)
+	}), nil
+}
+
+// unmanagedGroupIds returns the groups among groupIds that must be left untouched because they are not managed.
+func (lc *LldapClient) unmanagedGroupIds(groupIds []int) ([]int, diag.Diagnostics) {
+	if !lc.reconcileManagedOnly() || len(groupIds) == 0 {
+		return []int{}, nil
+	}
+	managedGroupIds, getManagedErr := lc.GetManagedGroupIds()
+	if getManagedErr != nil {
+		return nil, getManagedErr
+	}
+	return slices.DeleteFunc(slices.Clone(groupIds), func(groupId int) bool {
+		return slices.Contains(managedGroupIds, groupId)
+	}), nil
+}
+
+// unmanagedGroupMembers returns the users among userIds whose membership in the group must be left
+// untouched, because the user or the group is not managed.
+func (lc *LldapClient) unmanagedGroupMembers(groupId int, userIds []string) ([]string, diag.Diagnostics) {
+	if !lc.reconcileManagedOnly() || len(userIds) == 0 {
+		return []string{}, nil
+	}
+	unmanagedGroupIds, unmanagedErr := lc.unmanagedGroupIds([]int{groupId})
+	if unmanagedErr != nil {
+		return nil, unmanagedErr
+	}
+	if len(unmanagedGroupIds) > 0 {
+		return slices.Clone(userIds), nil
+	}
+	return lc.unmanagedUserIds(userIds)
+}
+
+// unmanagedUserGroups returns the groups among groupIds whose membership of the user must be left
+// untouched, because the group or the user is not managed.
+func (lc *LldapClient) unmanagedUserGroups(userId string, groupIds []int) ([]int, diag.Diagnostics) {
+	if !lc.reconcileManagedOnly() || len(groupIds) == 0 {
+		return []int{}, nil
+	}
+	unmanagedUserIds, unmanagedErr := lc.unmanagedUserIds([]string{userId})
+	if unmanagedErr != nil {
+		return nil, unmanagedErr
+	}
+	if len(unmanagedUserIds) > 0 {
+		return slices.Clone(groupIds), nil
+	}
+	return lc.unmanagedGroupIds(groupIds)
+}
+
+// unmanagedMembershipWarning reports a membership that was never part of the configuration and is not
+// reconciled because the user or the group is unmanaged.
+func unmanagedMembershipWarning(groupId int, userId string) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  "Unmanaged membership left untouched",
+		Detail: fmt.Sprintf(
+			"User '%s' is a member of group %d but not part of the configuration. The user or the group is not managed by Terraform, so the membership is not removed while reconcile_managed_only is enabled.",
+			userId, groupId),
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsManaged(t *testing.T) {
+	attributes := []LldapCustomAttribute{
+		{Name: "other", Value: []string{ManagedMarkerValue}},
+		{Name: "managed_by", Value: []string{ManagedMarkerValue}},
+	}
+	assert.True(t, IsManaged(attributes, "managed_by"))
+	assert.False(t, IsManaged(attributes, "owner"))
+	assert.False(t, IsManaged([]LldapCustomAttribute{{Name: "managed_by", Value: []string{"someone"}}}, "managed_by"))
+	assert.False(t, IsManaged(nil, "managed_by"))
+}
+
+func TestReconcileManagedOnly(t *testing.T) {
+	// Without reconcile_managed_only, nothing is left untouched and the API is not called
+	for _, config := range []Config{
+		{},
+		{ManagedMarker: "managed_by"},
+		{ReconcileManagedOnly: true},
+	} {
+		lc := &LldapClient{Config: config}
+		assert.False(t, lc.reconcileManagedOnly())
+		unmanagedUserIds, userErr := lc.unmanagedUserIds([]string{"someone"})
+		assert.Nil(t, userErr)
+		assert.Empty(t, unmanagedUserIds)
+		unmanagedGroupIds, groupErr := lc.unmanagedGroupIds([]int{1})
+		assert.Nil(t, groupErr)
+		assert.Empty(t, unmanagedGroupIds)
+		unmanagedMembers, membersErr := lc.unmanagedGroupMembers(1, []string{"someone"})
+		assert.Nil(t, membersErr)
+		assert.Empty(t, unmanagedMembers)
+		unmanagedGroups, groupsErr := lc.unmanagedUserGroups("someone", []int{1})
+		assert.Nil(t, groupsErr)
+		assert.Empty(t, unmanagedGroups)
+	}
+	assert.True(t, (&LldapClient{Config: Config{ManagedMarker: "managed_by", ReconcileManagedOnly: true}}).reconcileManagedOnly())
+}
+
+func TestMarkManagedOnRead(t *testing.T) {
+	mutations := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query LldapClientQuery
+		_ = json.NewDecoder(r.Body).Decode(&query)
+		switch query.OperationName {
+		case "GetUserAttributesSchema":
+			_, _ = fmt.Fprint(w, `{"data":{"schema":{"userSchema":{"attributes":[{"name":"managed_by","attributeType":"STRING"}]}}}}`)
+		case "GetGroupAttributesSchema":
+			_, _ = fmt.Fprint(w, `{"data":{"schema":{"groupSchema":{"attributes":[{"name":"managed_by","attributeType":"STRING"}]}}}}`)
+		case "GetUserDetails":
+			_, _ = fmt.Fprint(w, `{"data":{"user":{"id":"imported","email":"imported@example.com","attributes":[]}}}`)
+		case "GetGroupDetails":
+			_, _ = fmt.Fprint(w, `{"data":{"group":{"id":1,"displayName":"imported","attributes":[]}}}`)
+		default:
+			mutations = append(mutations, query.OperationName)
+			_, _ = fmt.Fprint(w, `{"data":{"updateUser":{"ok":true},"updateGroup":{"ok":true}}}`)
+		}
+	}))
+	defer server.Close()
+	httpUrl, _ := url.Parse(server.URL)
+	lc := &LldapClient{Config: Config{HttpUrl: httpUrl, ManagedMarker: "managed_by"}, HttpClient: server.Client()}
+	lc.SetToken(testToken("admin", time.Now().Add(time.Hour)))
+
+	// Imported objects, or objects created before managed_marker was set, are tagged on read
+	user := &userResource{client: lc}
+	group := &groupResource{client: lc}
+	read := func(r resource.Resource, id string) []string {
+		state := testResourceState(t, r, map[string]any{"id": id})
+		resp := &resource.ReadResponse{State: state}
+		r.Read(t.Context(), resource.ReadRequest{State: state}, resp)
+		assert.Empty(t, resp.Diagnostics)
+		var attributes []struct {
+			Name  string   `tfsdk:"name"`
+			Value []string `tfsdk:"value"`
+		}
+		assert.Empty(t, resp.State.GetAttribute(t.Context(), path.Root("attributes"), &attributes))
+		names := make([]string, len(attributes))
+		for i, attribute := range attributes {
+			names[i] = attribute.Name
+		}
+		return names
+	}
+	assert.Equal(t, []string{"managed_by"}, read(user, "imported"))
+	assert.Equal(t, []string{"managed_by"}, read(group, "1"))
+	assert.Equal(t, []string{"UpdateUser", "UpdateGroup"}, mutations)
+
+	// Read-only providers leave them untagged
+	lc.Config.ReadOnly = true
+	mutations = mutations[:0]
+	read(user, "imported")
+	read(group, "1")
+	assert.Empty(t, mutations)
+}
@@ -0,0 +1,127 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Memberships are managed either additively, one group/user pair at a time by
+// lldap_member, or authoritatively, for all users of a group by
+// lldap_group_memberships or for all groups of a user by lldap_user_memberships.
+// Like the IAM member/policy resources of other providers, an additive resource
+// must not manage a pair that is also covered by an authoritative resource,
+// otherwise each apply removes what the other one added.
+//
+// All resources of a configuration are planned by the same provider instance,
+// so each resource records its claim while planning and overlapping claims
+// are refused.
+type membershipClaims struct {
+	mutex   sync.Mutex
+	members map[int][]string
+	groups  map[int][]string
+	users   map[string][]int
+}
+
+func newMembershipClaims() *membershipClaims {
+	return &membershipClaims{
+		members: map[int][]string{},
+		groups:  map[int][]string{},
+		users:   map[string][]int{},
+	}
+}
+
+// claimMember records that lldap_member manages the user's membership in the group.
+func (claims *membershipClaims) claimMember(groupId int, userId string) error {
+	if claims == nil {
+		return nil
+	}
+	userId = strings.ToLower(userId)
+	claims.mutex.Lock()
+	defer claims.mutex.Unlock()
+	if _, found := claims.groups[groupId]; found {
+		return fmt.Errorf("lldap_member for user '%s' in group %d conflicts with lldap_group_memberships, which manages all members of group %d", userId, groupId, groupId)
+	}
+	if _, found := claims.users[userId]; found {
+		return fmt.Errorf("lldap_member for user '%s' in group %d conflicts with lldap_user_memberships, which manages all groups of user '%s'", userId, groupId, userId)
+	}
+	if !slices.Contains(claims.members[groupId], userId) {
+		claims.members[groupId] = append(claims.members[groupId], userId)
+	}
+	return nil
+}
+
+// claimGroupMembers records that lldap_group_memberships manages all members of the group.
+func (claims *membershipClaims) claimGroupMembers(groupId int, userIds []string) error {
+	if claims == nil {
+		return nil
+	}
+	userIds = lowerUserIds(userIds)
+	claims.mutex.Lock()
+	defer claims.mutex.Unlock()
+	if claimedUserIds, found := claims.groups[groupId]; found && !sameElements(claimedUserIds, userIds) {
+		return fmt.Errorf("group %d is managed by more than one lldap_group_memberships", groupId)
+	}
+	if memberUserIds := claims.members[groupId]; len(memberUserIds) > 0 {
+		return fmt.Errorf("lldap_group_memberships for group %d conflicts with lldap_member for user '%s' in the same group", groupId, memberUserIds[0])
+	}
+	for userId, groupIds := range claims.users {
+		if slices.Contains(userIds, userId) != slices.Contains(groupIds, groupId) {
+			return fmt.Errorf("lldap_group_memberships for group %d and lldap_user_memberships for user '%s' disagree on whether the user is a member of the group", groupId, userId)
+		}
+	}
+	claims.groups[groupId] = userIds
+	return nil
+}
+
+// claimUserGroups records that lldap_user_memberships manages all groups of the user.
+func (claims *membershipClaims) claimUserGroups(userId string, groupIds []int) error {
+	if claims == nil {
+		return nil
+	}
+	userId = strings.ToLower(userId)
+	claims.mutex.Lock()
+	defer claims.mutex.Unlock()
+	if claimedGroupIds, found := claims.users[userId]; found && !sameElements(claimedGroupIds, groupIds) {
+		return fmt.Errorf("user '%s' is managed by more than one lldap_user_memberships", userId)
+	}
+	for groupId, memberUserIds := range claims.members {
+		if slices.Contains(memberUserIds, userId) {
+			return fmt.Errorf("lldap_user_memberships for user '%s' conflicts with lldap_member for the same user in group %d", userId, groupId)
+		}
+	}
+	for groupId, userIds := range claims.groups {
+		if slices.Contains(groupIds, groupId) != slices.Contains(userIds, userId) {
+			return fmt.Errorf("lldap_user_memberships for user '%s' and lldap_group_memberships for group %d disagree on whether the user is a member of the group", userId, groupId)
+		}
+	}
+	claims.users[userId] = slices.Clone(groupIds)
+	return nil
+}
+
+func lowerUserIds(userIds []string) []string {
+	lowered := make([]string, len(userIds))
+	for i, userId := range userIds {
+		lowered[i] = strings.ToLower(userId)
+	}
+	return lowered
+}
+
+func sameElements[T comparable](a []T, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMembershipClaimsMember(t *testing.T) {
+	claims := newMembershipClaims()
+	assert.Nil(t, claims.claimMember(1, "alice"))
+	// Planning the same resource again is fine
+	assert.Nil(t, claims.claimMember(1, "Alice"))
+	assert.Nil(t, claims.claimMember(2, "bob"))
+
+	assert.ErrorContains(t, claims.claimGroupMembers(1, []string{"alice"}), "conflicts with lldap_member")
+	assert.ErrorContains(t, claims.claimUserGroups("bob", []int{2}), "conflicts with lldap_member")
+	assert.Nil(t, claims.claimGroupMembers(3, []string{"alice"}))
+	assert.Nil(t, claims.claimUserGroups("carol", []int{4}))
+
+	assert.ErrorContains(t, claims.claimMember(3, "dave"), "lldap_group_memberships")
+	assert.ErrorContains(t, claims.claimMember(5, "carol"), "lldap_user_memberships")
+}
+
+func TestMembershipClaimsAuthoritative(t *testing.T) {
+	claims := newMembershipClaims()
+	assert.Nil(t, claims.claimGroupMembers(1, []string{"alice", "bob"}))
+	assert.Nil(t, claims.claimGroupMembers(1, []string{"bob", "alice"}))
+	assert.ErrorContains(t, claims.claimGroupMembers(1, []string{"alice"}), "more than one lldap_group_memberships")
+
+	// Both authoritative resources agree on the membership of alice in group 1
+	assert.Nil(t, claims.claimUserGroups("alice", []int{1, 2}))
+	assert.Nil(t, claims.claimUserGroups("carol", []int{2}))
+	assert.ErrorContains(t, claims.claimUserGroups("bob", []int{2}), "disagree")
+	assert.ErrorContains(t, claims.claimUserGroups("alice", []int{2}), "more than one lldap_user_memberships")
+
+	assert.Nil(t, claims.claimGroupMembers(2, []string{"alice", "carol"}))
+	assert.ErrorContains(t, claims.claimGroupMembers(3, []string{"carol"}), "disagree")
+}
+
+func TestMembershipClaimsDisabled(t *testing.T) {
+	var claims *membershipClaims
+	assert.Nil(t, claims.claimMember(1, "alice"))
+	assert.Nil(t, claims.claimGroupMembers(1, []string{"bob"}))
+	assert.Nil(t, claims.claimUserGroups("alice", []int{}))
+}
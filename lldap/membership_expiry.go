@@ -0,0 +1,190 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// LldapMembership is a single group-user relationship with an optional expiry.
+type LldapMembership struct {
+	GroupId   int    `json:"groupId"`
+	UserId    string `json:"userId"`
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+func ParseMembershipExpiry(expiresAt string) (time.Time, error) {
+	parsed, parseErr := time.Parse(time.RFC3339, strings.TrimSpace(expiresAt))
+	if parseErr != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry '%s', expected RFC 3339 timestamp: %s", expiresAt, parseErr)
+	}
+	return parsed, nil
+}
+
+// IsMembershipExpired reports whether the expiry is at or before now. Memberships
+// without a (valid) expiry never expire.
+func IsMembershipExpired(expiresAt string, now time.Time) bool {
+	if expiresAt == "" {
+		return false
+	}
+	parsed, parseErr := ParseMembershipExpiry(expiresAt)
+	if parseErr != nil {
+		return false
+	}
+	return !parsed.After(now)
+}
+
+func validateMembershipExpiry(val any, key string) (warns []string, errs []error) {
+	if _, parseErr := ParseMembershipExpiry(val.(string)); parseErr != nil {
+		errs = append(errs, fmt.Errorf("%q: %s", key, parseErr))
+	}
+	return
+}
+
+func validateMembershipExpiryMap(val any, path cty.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for userId, expiresAt := range val.(map[string]any) {
+		if _, parseErr := ParseMembershipExpiry(expiresAt.(string)); parseErr != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("invalid expiry for user %s", userId),
+				Detail:        parseErr.Error(),
+				AttributePath: path.IndexString(userId),
+			})
+		}
+	}
+	return diags
+}
+
+// ExpiredMembershipsFromState returns all expired memberships managed by
+// Terraform, read from a Terraform state file.
+func ExpiredMembershipsFromState(state []byte, now time.Time) ([]LldapMembership, error) {
+	type StateInstance struct {
+		IndexKey   any            `json:"index_key"`
+		Attributes map[string]any `json:"attributes"`
+	}
+	type StateResource struct {
+		Module    string          `json:"module"`
+		Mode      string          `json:"mode"`
+		Type      string          `json:"type"`
+		Name      string          `json:"name"`
+		Instances []StateInstance `json:"instances"`
+	}
+	type State struct {
+		Version   int             `json:"version"`
+		Resources []StateResource `json:"resources"`
+	}
+	parsedState := State{}
+	if unmarshErr := json.Unmarshal(state, &parsedState); unmarshErr != nil {
+		return nil, fmt.Errorf("could not parse state: %s", unmarshErr)
+	}
+	if parsedState.Version != 4 {
+		return nil, fmt.Errorf("unsupported state version %d, expected 4", parsedState.Version)
+	}
+	result := make([]LldapMembership, 0)
+	for _, resource := range parsedState.Resources {
+		if resource.Mode != "managed" || (resource.Type != "lldap_member" && resource.Type != "lldap_group_memberships") {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			groupId, groupIdErr := stateInt(instance.Attributes["group_id"])
+			if groupIdErr != nil {
+				return nil, fmt.Errorf("invalid group_id in state of %s: %s",
+					stateResourceAddress(resource.Module, resource.Type, resource.Name, instance.IndexKey), groupIdErr)
+			}
+			switch resource.Type {
+			case "lldap_member":
+				expiresAt, _ := instance.Attributes["expires_at"].(string)
+				userId, _ := instance.Attributes["user_id"].(string)
+				if IsMembershipExpired(expiresAt, now) {
+					result = append(result, LldapMembership{GroupId: groupId, UserId: userId, ExpiresAt: expiresAt})
+				}
+			case "lldap_group_memberships":
+				expiry, _ := instance.Attributes["expires_at"].(map[string]any)
+				for userId, expiresAtVal := range expiry {
+					expiresAt, _ := expiresAtVal.(string)
+					if IsMembershipExpired(expiresAt, now) {
+						result = append(result, LldapMembership{GroupId: groupId, UserId: userId, ExpiresAt: expiresAt})
+					}
+				}
+			}
+		}
+	}
+	sortMemberships(result)
+	return result, nil
+}
+
+// stateResourceAddress returns the address of a resource instance in the state, e.g. module.a.lldap_member.b["c"].
+func stateResourceAddress(module string, resourceType string, name string, indexKey any) string {
+	address := fmt.Sprintf("%s.%s", resourceType, name)
+	if module != "" {
+		address = fmt.Sprintf("%s.%s", module, address)
+	}
+	switch key := indexKey.(type) {
+	case string:
+		address += fmt.Sprintf("[%q]", key)
+	case float64:
+		address += fmt.Sprintf("[%d]", int(key))
+	}
+	return address
+}
+
+// GetExpiredMembershipsFromAttribute returns the memberships of all users whose
+// DATE_TIME attribute is at or before now. Only the given groups are considered,
+// or all groups of the user but lldap_admin if none are given.
+func (lc *LldapClient) GetExpiredMembershipsFromAttribute(attributeName string, groupIds []int, now time.Time) ([]LldapMembership, diag.Diagnostics) {
+	attributeType, getTypeErr := lc.GetUserAttributeType(attributeName)
+	if getTypeErr != nil {
+		return nil, getTypeErr
+	}
+	if attributeType != AttributeTypeDateTime {
+		return nil, diag.Errorf("user attribute '%s' must be of type %s, got %s", attributeName, AttributeTypeDateTime, attributeType)
+	}
+	users, getUsersErr := lc.GetUsersWithAttributes()
+	if getUsersErr != nil {
+		return nil, getUsersErr
+	}
+	result := make([]LldapMembership, 0)
+	for _, user := range users {
+		expiresAt := ""
+		for _, attr := range user.Attributes {
+			if attr.Name == attributeName && len(attr.Value) > 0 {
+				expiresAt = attr.Value[0]
+			}
+		}
+		if !IsMembershipExpired(expiresAt, now) {
+			continue
+		}
+		userDetails, getUserErr := lc.GetUser(user.Id)
+		if getUserErr != nil {
+			return nil, getUserErr
+		}
+		for _, group := range userDetails.Groups {
+			if slices.Contains(groupIds, group.Id) || (len(groupIds) == 0 && group.DisplayName != LldapAdminGroupName) {
+				result = append(result, LldapMembership{GroupId: group.Id, UserId: user.Id, ExpiresAt: expiresAt})
+			}
+		}
+	}
+	sortMemberships(result)
+	return result, nil
+}
+
+func sortMemberships(memberships []LldapMembership) {
+	slices.SortFunc(memberships, func(a LldapMembership, b LldapMembership) int {
+		if a.GroupId != b.GroupId {
+			return a.GroupId - b.GroupId
+		}
+		return strings.Compare(a.UserId, b.UserId)
+	})
+}
@@ -0,0 +1,120 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMembershipExpired(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.False(t, IsMembershipExpired("", now))
+	assert.False(t, IsMembershipExpired("not a timestamp", now))
+	assert.False(t, IsMembershipExpired("2025-06-01T12:00:01Z", now))
+	assert.True(t, IsMembershipExpired("2025-06-01T12:00:00Z", now))
+	assert.True(t, IsMembershipExpired("2025-06-01T13:00:00+02:00", now))
+}
+
+func TestExpiredMembershipsFromState(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	state := []byte(`{
+		"version": 4,
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "lldap_member",
+				"instances": [
+					{"attributes": {"group_id": 3, "user_id": "alice", "expires_at": "2025-01-01T00:00:00Z"}},
+					{"attributes": {"group_id": 3, "user_id": "bob", "expires_at": "2030-01-01T00:00:00Z"}},
+					{"attributes": {"group_id": 3, "user_id": "carol", "expires_at": null}}
+				]
+			},
+			{
+				"mode": "managed",
+				"type": "lldap_group_memberships",
+				"instances": [
+					{"attributes": {"group_id": "2", "user_ids": ["dave", "erin"], "expires_at": {"dave": "2025-05-31T00:00:00Z"}}}
+				]
+			},
+			{
+				"mode": "data",
+				"type": "lldap_member",
+				"instances": [
+					{"attributes": {"group_id": 4, "user_id": "frank", "expires_at": "2025-01-01T00:00:00Z"}}
+				]
+			}
+		]
+	}`)
+	expired, parseErr := ExpiredMembershipsFromState(state, now)
+	assert.Nil(t, parseErr)
+	assert.Equal(t, []LldapMembership{
+		{GroupId: 2, UserId: "dave", ExpiresAt: "2025-05-31T00:00:00Z"},
+		{GroupId: 3, UserId: "alice", ExpiresAt: "2025-01-01T00:00:00Z"},
+	}, expired)
+
+	_, versionErr := ExpiredMembershipsFromState([]byte(`{"version": 3}`), now)
+	assert.NotNil(t, versionErr)
+
+	// A missing or malformed group_id must not turn into group 0
+	_, groupIdErr := ExpiredMembershipsFromState([]byte(`{
+		"version": 4,
+		"resources": [
+			{
+				"module": "module.access",
+				"mode": "managed",
+				"type": "lldap_member",
+				"name": "temporary",
+				"instances": [
+					{"index_key": "alice", "attributes": {"group_id": "admins", "user_id": "alice", "expires_at": "2025-01-01T00:00:00Z"}}
+				]
+			}
+		]
+	}`), now)
+	assert.ErrorContains(t, groupIdErr, `invalid group_id in state of module.access.lldap_member.temporary["alice"]`)
+	_, missingErr := ExpiredMembershipsFromState([]byte(`{
+		"version": 4,
+		"resources": [
+			{"mode": "managed", "type": "lldap_group_memberships", "name": "team", "instances": [{"index_key": 0, "attributes": {}}]}
+		]
+	}`), now)
+	assert.ErrorContains(t, missingErr, "invalid group_id in state of lldap_group_memberships.team[0]")
+}
+
+func TestExpiredMembershipsFromAttributeSkipsAdminGroup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query LldapClientQuery
+		_ = json.NewDecoder(r.Body).Decode(&query)
+		switch query.OperationName {
+		case "GetUserAttributesSchema":
+			_, _ = fmt.Fprint(w, `{"data":{"schema":{"userSchema":{"attributes":[{"name":"expires","attributeType":"DATE_TIME"}]}}}}`)
+		case "ListUsersWithAttributesQuery":
+			_, _ = fmt.Fprint(w, `{"data":{"users":[{"id":"alice","attributes":[{"name":"expires","value":["2000-01-01T00:00:00Z"]}]}]}}`)
+		default:
+			_, _ = fmt.Fprint(w, `{"data":{"user":{"id":"alice","groups":[{"id":1,"displayName":"lldap_admin"},{"id":2,"displayName":"contractors"}]}}}`)
+		}
+	}))
+	defer server.Close()
+	httpUrl, _ := url.Parse(server.URL)
+	lc := &LldapClient{Config: Config{HttpUrl: httpUrl}, HttpClient: server.Client()}
+	lc.SetToken(testToken("admin", time.Now().Add(time.Hour)))
+
+	// lldap_admin is only pruned if named explicitly
+	expired, diags := lc.GetExpiredMembershipsFromAttribute("expires", nil, time.Now())
+	assert.Nil(t, diags)
+	assert.Equal(t, []LldapMembership{{GroupId: 2, UserId: "alice", ExpiresAt: "2000-01-01T00:00:00Z"}}, expired)
+	expired, diags = lc.GetExpiredMembershipsFromAttribute("expires", []int{1}, time.Now())
+	assert.Nil(t, diags)
+	assert.Equal(t, []LldapMembership{{GroupId: 1, UserId: "alice", ExpiresAt: "2000-01-01T00:00:00Z"}}, expired)
+}
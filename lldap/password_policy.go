@@ -0,0 +1,166 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// PasswordPolicy is checked before passwords are sent to LLDAP, which accepts any password.
+// The zero value accepts any password as well.
+type PasswordPolicy struct {
+	MinLength              int    `json:"min_length"`
+	RequireLowercase       bool   `json:"require_lowercase"`
+	RequireUppercase       bool   `json:"require_uppercase"`
+	RequireDigit           bool   `json:"require_digit"`
+	RequireSymbol          bool   `json:"require_symbol"`
+	RejectUsernameAndEmail bool   `json:"reject_username_and_email"`
+	BreachedPasswordsFile  string `json:"breached_passwords_file"`
+}
+
+// ReadPasswordPolicyFile reads a password policy from a JSON file with the keys of the
+// provider's password_policy block.
+func ReadPasswordPolicyFile(path string) (PasswordPolicy, error) {
+	policy := PasswordPolicy{}
+	content, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return policy, fmt.Errorf("could not read password policy: %s", readErr)
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(content)))
+	decoder.DisallowUnknownFields()
+	if decodeErr := decoder.Decode(&policy); decodeErr != nil {
+		return policy, fmt.Errorf("invalid password policy in '%s': %s", path, decodeErr)
+	}
+	return policy, nil
+}
+
+// readBreachedPasswords reads a breached password list with one entry per line, either the
+// password itself or its SHA-1 hash in hex, optionally followed by ':count' as in the
+// Have I Been Pwned downloads. Entries are returned as uppercase SHA-1 hashes.
+func readBreachedPasswords(path string) (map[string]bool, error) {
+	file, openErr := os.Open(path)
+	if openErr != nil {
+		return nil, fmt.Errorf("could not read breached_passwords_file: %s", openErr)
+	}
+	defer func() { _ = file.Close() }()
+	hashes := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if hash, _, _ := strings.Cut(line, ":"); isSha1Hex(hash) {
+			hashes[strings.ToUpper(hash)] = true
+		} else {
+			hashes[passwordSha1(line)] = true
+		}
+	}
+	if scanErr := scanner.Err(); scanErr != nil {
+		return nil, fmt.Errorf("could not read breached_passwords_file: %s", scanErr)
+	}
+	return hashes, nil
+}
+
+func isSha1Hex(s string) bool {
+	if len(s) != 2*sha1.Size {
+		return false
+	}
+	_, decodeErr := hex.DecodeString(s)
+	return decodeErr == nil
+}
+
+func passwordSha1(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// SetPasswordPolicy sets the policy of the client and loads its breached password list.
+func (lc *LldapClient) SetPasswordPolicy(policy PasswordPolicy) error {
+	if policy.MinLength < 0 {
+		return fmt.Errorf("password_policy min_length must not be negative")
+	}
+	lc.breachedPasswords = nil
+	if policy.BreachedPasswordsFile != "" {
+		hashes, readErr := readBreachedPasswords(policy.BreachedPasswordsFile)
+		if readErr != nil {
+			return readErr
+		}
+		lc.breachedPasswords = hashes
+	}
+	lc.Config.PasswordPolicy = policy
+	return nil
+}
+
+// ValidatePassword returns every violation of the password policy. The username and email
+// are only used by reject_username_and_email, and ignored if empty.
+func (lc *LldapClient) ValidatePassword(password string, username string, email string) error {
+	policy := lc.Config.PasswordPolicy
+	var violations []string
+	if length := len([]rune(password)); length < policy.MinLength {
+		violations = append(violations, fmt.Sprintf("it must be at least %d characters long", policy.MinLength))
+	}
+	for _, class := range []struct {
+		required bool
+		name     string
+		matches  func(rune) bool
+	}{
+		{policy.RequireLowercase, "a lowercase letter", unicode.IsLower},
+		{policy.RequireUppercase, "an uppercase letter", unicode.IsUpper},
+		{policy.RequireDigit, "a digit", unicode.IsDigit},
+		{policy.RequireSymbol, "a symbol", func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+		}},
+	} {
+		if class.required && !strings.ContainsFunc(password, class.matches) {
+			violations = append(violations, fmt.Sprintf("it must contain %s", class.name))
+		}
+	}
+	if policy.RejectUsernameAndEmail {
+		lowerPassword := strings.ToLower(password)
+		emailLocalPart, _, _ := strings.Cut(email, "@")
+		for _, value := range []string{username, email, emailLocalPart} {
+			if value != "" && strings.Contains(lowerPassword, strings.ToLower(value)) {
+				violations = append(violations, "it must not contain the username or email")
+				break
+			}
+		}
+	}
+	if lc.breachedPasswords[passwordSha1(password)] {
+		violations = append(violations, "it is on the list of breached passwords")
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("password does not match the password policy: %s", strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+// checkPasswordPolicy validates a password before it is set, looking up the email of the
+// user only if the policy needs it.
+func (lc *LldapClient) checkPasswordPolicy(username string, password string) diag.Diagnostics {
+	email := ""
+	if lc.Config.PasswordPolicy.RejectUsernameAndEmail {
+		user, getUserErr := lc.GetUser(username)
+		if getUserErr != nil {
+			return getUserErr
+		}
+		email = user.Email
+	}
+	if policyErr := lc.ValidatePassword(password, username, email); policyErr != nil {
+		return diag.Errorf("Changing the password of user '%s' failed: %s", username, policyErr)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePassword(t *testing.T) {
+	lc := &LldapClient{}
+	// The zero policy accepts any password
+	assert.Nil(t, lc.ValidatePassword("a", "someone", "someone@example.com"))
+
+	assert.Nil(t, lc.SetPasswordPolicy(PasswordPolicy{
+		MinLength:              10,
+		RequireLowercase:       true,
+		RequireUppercase:       true,
+		RequireDigit:           true,
+		RequireSymbol:          true,
+		RejectUsernameAndEmail: true,
+	}))
+	assert.Nil(t, lc.ValidatePassword("Corr3ct-horse", "someone", "someone@example.com"))
+	assert.EqualError(t, lc.ValidatePassword("short", "", ""),
+		"password does not match the password policy: it must be at least 10 characters long, it must contain an uppercase letter, it must contain a digit, it must contain a symbol")
+	// Length counts characters, not bytes
+	assert.ErrorContains(t, lc.ValidatePassword("Pässwörd1", "", ""), "at least 10 characters")
+	assert.Nil(t, lc.ValidatePassword("Pässwörd-1", "", ""))
+	assert.ErrorContains(t, lc.ValidatePassword("My-SomeOne-Pass1", "someone", ""), "username or email")
+	assert.ErrorContains(t, lc.ValidatePassword("X-mail-Alias-1", "", "mail-alias@example.com"), "username or email")
+
+	assert.NotNil(t, lc.SetPasswordPolicy(PasswordPolicy{MinLength: -1}))
+}
+
+func TestBreachedPasswords(t *testing.T) {
+	breachedFile := filepath.Join(t.TempDir(), "breached.txt")
+	assert.Nil(t, os.WriteFile(breachedFile, []byte(
+		"Password123!\r\n"+
+			"\n"+
+			// SHA-1 of "Summer2024!" in Have I Been Pwned format
+			passwordSha1("Summer2024!")+":42\n"+
+			"c2c0b4e7c0e3b5e1b4c0e3b5e1b4c0e3b5e1b4c0\n",
+	), 0600))
+	hashes, readErr := readBreachedPasswords(breachedFile)
+	assert.Nil(t, readErr)
+	assert.Len(t, hashes, 3)
+	assert.True(t, hashes["C2C0B4E7C0E3B5E1B4C0E3B5E1B4C0E3B5E1B4C0"])
+
+	lc := &LldapClient{}
+	assert.Nil(t, lc.SetPasswordPolicy(PasswordPolicy{BreachedPasswordsFile: breachedFile}))
+	assert.ErrorContains(t, lc.ValidatePassword("Password123!", "", ""), "breached")
+	assert.ErrorContains(t, lc.ValidatePassword("Summer2024!", "", ""), "breached")
+	assert.Nil(t, lc.ValidatePassword("Summer2025!", "", ""))
+
+	assert.NotNil(t, lc.SetPasswordPolicy(PasswordPolicy{BreachedPasswordsFile: filepath.Join(t.TempDir(), "missing")}))
+}
+
+func TestReadPasswordPolicyFile(t *testing.T) {
+	dir := t.TempDir()
+	policyFile := filepath.Join(dir, "policy.json")
+	assert.Nil(t, os.WriteFile(policyFile, []byte(`{"min_length": 12, "require_digit": true}`), 0600))
+	policy, readErr := ReadPasswordPolicyFile(policyFile)
+	assert.Nil(t, readErr)
+	assert.Equal(t, PasswordPolicy{MinLength: 12, RequireDigit: true}, policy)
+
+	// Typos must not silently disable a rule
+	assert.Nil(t, os.WriteFile(policyFile, []byte(`{"min_lenght": 12}`), 0600))
+	_, readErr = ReadPasswordPolicyFile(policyFile)
+	assert.ErrorContains(t, readErr, "min_lenght")
+}
+
+func TestSetUserPasswordPolicy(t *testing.T) {
+	// Refused before anything is sent, the client has neither a token nor a server
+	lc := &LldapClient{}
+	assert.Nil(t, lc.SetPasswordPolicy(PasswordPolicy{MinLength: 12}))
+	diags := lc.SetUserPassword("someone", "short")
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "at least 12 characters")
+}
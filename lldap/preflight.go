@@ -0,0 +1,162 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	ldap "github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// LldapRole is the effective role of an account, derived from its built-in group memberships.
+type LldapRole string
+
+const (
+	LldapRoleAdmin           LldapRole = "admin"
+	LldapRolePasswordManager LldapRole = "password_manager"
+	LldapRoleReadonly        LldapRole = "strict_readonly"
+	LldapRoleUser            LldapRole = "user"
+)
+
+const (
+	LldapPasswordManagerGroupName = "lldap_password_manager"
+	LldapReadonlyGroupName        = "lldap_strict_readonly"
+)
+
+// RoleFromGroups returns the most privileged role granted by the groups.
+func RoleFromGroups(groups []LldapGroup) LldapRole {
+	names := make([]string, len(groups))
+	for i, group := range groups {
+		names[i] = group.DisplayName
+	}
+	switch {
+	case slices.Contains(names, LldapAdminGroupName):
+		return LldapRoleAdmin
+	case slices.Contains(names, LldapPasswordManagerGroupName):
+		return LldapRolePasswordManager
+	case slices.Contains(names, LldapReadonlyGroupName):
+		return LldapRoleReadonly
+	default:
+		return LldapRoleUser
+	}
+}
+
+// preflightRoles remembers the role found for each provider configuration. The SDK and the
+// framework provider are configured with the same settings, so the check only runs, and
+// reports its diagnostics, once.
+var preflightRoles = struct {
+	sync.Mutex
+	roles map[providerSettings]LldapRole
+}{roles: map[providerSettings]LldapRole{}}
+
+// preflight checks the provider configuration against the server once per configuration,
+// and records the role of the provider user in the client.
+func (lc *LldapClient) preflight(settings providerSettings) diag.Diagnostics {
+	preflightRoles.Lock()
+	defer preflightRoles.Unlock()
+	if role, ok := preflightRoles.roles[settings]; ok {
+		lc.role = role
+		return nil
+	}
+	// Failures are remembered too, so they are not reported twice
+	diags := lc.Preflight()
+	preflightRoles.roles[settings] = lc.role
+	return diags
+}
+
+// Preflight authenticates eagerly and checks the LDAP bind, the base DN and the role of the
+// provider user, so a misconfiguration fails with guidance instead of in the middle of an apply.
+func (lc *LldapClient) Preflight() diag.Diagnostics {
+	if _, authErr := lc.bearerToken(); authErr != nil {
+		return diag.Errorf("could not authenticate as '%s' at %s, check http_url, username and the password or token: %s",
+			lc.Config.UserName, lc.Config.HttpUrl, authErr[0].Summary)
+	}
+	user, getUserErr := lc.GetUser(lc.Config.UserName)
+	if getUserErr != nil {
+		return diag.Errorf("could not read provider user '%s': %s", lc.Config.UserName, getUserErr[0].Summary)
+	}
+	lc.role = RoleFromGroups(user.Groups)
+	if ldapErr := lc.checkLdapBaseDn(); ldapErr != nil {
+		return ldapErr
+	}
+	return lc.checkRole()
+}
+
+// checkLdapBaseDn binds as the provider user and looks up its own entry below base_dn.
+// Without a password, e.g. when authenticating with a token, there is nothing to bind with.
+func (lc *LldapClient) checkLdapBaseDn() diag.Diagnostics {
+	if lc.Config.Password == "" {
+		return nil
+	}
+	bind, bindErr := getLdapBindConnection(lc.Config.LdapUrl.String(), lc.Config.BaseDn, lc.Config.UserName, lc.Config.Password)
+	if bindErr != nil {
+		return diag.Errorf("could not bind as '%s' at %s, check ldap_url and base_dn: %s",
+			UserDn(lc.Config.UserName, lc.Config.BaseDn), lc.Config.LdapUrl, bindErr[0].Summary)
+	}
+	defer func() {
+		if err := bind.Close(); err != nil {
+			log.Println("Error closing ldap bind connection:", err)
+		}
+	}()
+	userDn := UserDn(lc.Config.UserName, lc.Config.BaseDn)
+	result, searchErr := bind.Search(ldap.NewSearchRequest(
+		userDn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, 0, false, "(objectClass=*)", []string{"dn"}, nil,
+	))
+	if searchErr != nil || len(result.Entries) == 0 {
+		return diag.Errorf("provider user '%s' was not found below base_dn '%s', check base_dn", userDn, lc.Config.BaseDn)
+	}
+	return nil
+}
+
+// checkRole warns or fails if the role of the provider user cannot make changes.
+func (lc *LldapClient) checkRole() diag.Diagnostics {
+	if lc.role == LldapRoleAdmin || lc.Config.ReadOnly {
+		return nil
+	}
+	if lc.role == LldapRoleUser {
+		return diag.Errorf(
+			"provider user '%s' is not a member of %s, %s or %s and can only read itself. Add it to %s, or to %s with read_only = true for plans",
+			lc.Config.UserName, LldapAdminGroupName, LldapPasswordManagerGroupName, LldapReadonlyGroupName, LldapAdminGroupName, LldapReadonlyGroupName)
+	}
+	refused := "every change"
+	if lc.role == LldapRolePasswordManager {
+		refused = "every change other than password changes of non-admin users"
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Provider user '%s' has the %s role", lc.Config.UserName, lc.role),
+		Detail: fmt.Sprintf(
+			"Resources can be read, but %s will fail. Add the user to %s to manage resources, or set read_only = true to make this explicit.",
+			refused, LldapAdminGroupName),
+	}}
+}
+
+// checkMutationRole refuses a mutation the role of the provider user is not allowed to make.
+// The role is unknown, and nothing is refused, if the preflight check did not run.
+func (lc *LldapClient) checkMutationRole(action string) diag.Diagnostics {
+	if lc.role == "" || lc.role == LldapRoleAdmin {
+		return nil
+	}
+	return lc.roleError(action)
+}
+
+// checkPasswordRole refuses a password change the role of the provider user is not allowed to make.
+func (lc *LldapClient) checkPasswordRole(username string, action string) diag.Diagnostics {
+	if lc.role == "" || lc.role == LldapRoleAdmin || lc.role == LldapRolePasswordManager || lc.isProviderUser(username) {
+		return nil
+	}
+	return lc.roleError(action)
+}
+
+func (lc *LldapClient) roleError(action string) diag.Diagnostics {
+	return diag.Errorf("%s requires membership in %s, but provider user '%s' has the %s role. Add the user to %s",
+		action, LldapAdminGroupName, lc.Config.UserName, lc.role, LldapAdminGroupName)
+}
@@ -0,0 +1,79 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleFromGroups(t *testing.T) {
+	groups := func(names ...string) []LldapGroup {
+		result := make([]LldapGroup, len(names))
+		for i, name := range names {
+			result[i] = LldapGroup{DisplayName: name}
+		}
+		return result
+	}
+	assert.Equal(t, LldapRoleAdmin, RoleFromGroups(groups("users", LldapReadonlyGroupName, LldapAdminGroupName)))
+	assert.Equal(t, LldapRolePasswordManager, RoleFromGroups(groups(LldapReadonlyGroupName, LldapPasswordManagerGroupName)))
+	assert.Equal(t, LldapRoleReadonly, RoleFromGroups(groups(LldapReadonlyGroupName)))
+	assert.Equal(t, LldapRoleUser, RoleFromGroups(groups("users")))
+	assert.Equal(t, LldapRoleUser, RoleFromGroups(nil))
+}
+
+func TestCheckRole(t *testing.T) {
+	lc := &LldapClient{Config: Config{UserName: "robot"}, role: LldapRoleAdmin}
+	assert.Nil(t, lc.checkRole())
+
+	lc.role = LldapRolePasswordManager
+	diags := lc.checkRole()
+	assert.False(t, diags.HasError())
+	assert.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Contains(t, diags[0].Detail, "password changes of non-admin users")
+
+	lc.role = LldapRoleUser
+	assert.True(t, lc.checkRole().HasError())
+	lc.Config.ReadOnly = true
+	assert.Nil(t, lc.checkRole())
+}
+
+func TestCheckMutationRole(t *testing.T) {
+	// Nothing is refused if the preflight check did not run
+	lc := &LldapClient{Config: Config{UserName: "robot"}}
+	assert.Nil(t, lc.checkMutationRole("Mutation DeleteUser"))
+	assert.Nil(t, lc.checkPasswordRole("someone", "Changing the password"))
+
+	lc.role = LldapRolePasswordManager
+	diags := lc.checkMutationRole("Mutation DeleteUser")
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "Mutation DeleteUser requires membership in lldap_admin")
+	assert.Nil(t, lc.checkPasswordRole("someone", "Changing the password"))
+
+	lc.role = LldapRoleReadonly
+	assert.True(t, lc.checkPasswordRole("someone", "Changing the password").HasError())
+	assert.Nil(t, lc.checkPasswordRole("Robot", "Changing the password"))
+
+	// Refused before anything is sent, the client has neither a token nor a server
+	_, diags = lc.query(LldapClientQuery{OperationName: "DeleteUser", Query: "mutation DeleteUser($userId: String!) { deleteUser(userId: $userId) { ok } }"})
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "strict_readonly role")
+}
+
+func TestPreflightOnce(t *testing.T) {
+	settings := providerSettings{HttpUrl: "http://preflight.invalid", UserName: "robot"}
+	preflightRoles.Lock()
+	preflightRoles.roles[settings] = LldapRoleReadonly
+	preflightRoles.Unlock()
+	// The result of the first check is reused without any request
+	lc := &LldapClient{}
+	assert.Nil(t, lc.preflight(settings))
+	assert.Equal(t, LldapRoleReadonly, lc.role)
+}
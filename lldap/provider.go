@@ -11,8 +11,10 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/url"
 	"strconv"
+	"sync"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -21,15 +23,27 @@ import (
 func Provider() *schema.Provider {
 	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
+			"allow_admin_lockout": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow changes that revoke the admin access of the provider's own `username`, such as removing it from `lldap_admin`, deleting it or changing its password (default: `false`)",
+			},
 			"base_dn": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Default:     "dc=example,dc=com",
 				Description: "Base DN, defaults to `dc=example,dc=com`",
 			},
+			"deletion_protection": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Default for `deletion_protection` on users, groups and attribute schemas (default: `false`)",
+			},
 			"http_url": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("LLDAP_HTTP_URL", nil),
 				Description: "HTTP URL in the format `http[s]://(hostname)[:port]`, can be set using the `LLDAP_HTTP_URL` environment variable",
 			},
@@ -41,38 +55,140 @@ func Provider() *schema.Provider {
 			},
 			"ldap_url": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("LLDAP_LDAP_URL", nil),
 				Description: "LDAP URL in the format `ldap[s]://(hostname)[:port]`, can be set using the `LLDAP_LDAP_URL` environment variable",
 			},
+			"managed_marker": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Name of a custom user and group attribute that tags objects created by this provider, or managed by `lldap_user` and `lldap_group`, as managed by Terraform, the attribute schemas are created on first use",
+			},
+			"max_concurrent_requests": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     DefaultMaxConcurrentRequests,
+				Description: "Maximum number of concurrent API requests when reconciling memberships, defaults to `4`",
+			},
 			"password": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("LLDAP_PASSWORD", nil),
 				Description: "admin account password, can be set using the `LLDAP_PASSWORD` environment variable",
 			},
+			"password_command": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LLDAP_PASSWORD_COMMAND", nil),
+				Description: "Shell command printing the password, e.g. of a secret manager CLI, can be set using the `LLDAP_PASSWORD_COMMAND` environment variable",
+			},
+			"password_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LLDAP_PASSWORD_FILE", nil),
+				Description: "File containing the password, e.g. a mounted secret, can be set using the `LLDAP_PASSWORD_FILE` environment variable",
+			},
+			"password_policy": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Checks passwords before they are sent to LLDAP, which accepts any password. Passwords known at plan time are checked during the plan",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"breached_passwords_file": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "File with one breached password per line, either in plain text or as SHA-1 hash in hex, optionally followed by `:count` as in the Have I Been Pwned downloads",
+						},
+						"min_length": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Default:     0,
+							Description: "Minimum number of characters (default: `0`)",
+						},
+						"reject_username_and_email": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Reject passwords containing the username, the email or the local part of the email, compared case-insensitively (default: `false`)",
+						},
+						"require_digit": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Require a digit (default: `false`)",
+						},
+						"require_lowercase": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Require a lowercase letter (default: `false`)",
+						},
+						"require_symbol": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Require a character that is neither a letter, a digit nor whitespace (default: `false`)",
+						},
+						"require_uppercase": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Require an uppercase letter (default: `false`)",
+						},
+					},
+				},
+			},
+			"read_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Refuse every change, including password changes, before anything is sent to LLDAP, e.g. for plans with credentials of the `lldap_strict_readonly` group (default: `false`)",
+			},
+			"reconcile_managed_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Only let `lldap_group_memberships` and `lldap_user_memberships` remove memberships that were never configured if both the user and the group are tagged with `managed_marker`, other differences are reported as warnings (default: `false`)",
+			},
+			"refresh_token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("LLDAP_REFRESH_TOKEN", nil),
+				Description: "Refresh token used to get tokens instead of logging in with a password, can be set using the `LLDAP_REFRESH_TOKEN` environment variable",
+			},
+			"token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("LLDAP_TOKEN", nil),
+				Description: "Pre-issued JWT used instead of logging in with a password, issued for `username` if that is set, can be set using the `LLDAP_TOKEN` environment variable",
+			},
 			"username": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Default:     "admin",
-				Description: "admin account username, defaults to `admin`",
+				Description: "admin account username, defaults to `admin`, or to the user of `token` or `refresh_token`. If set, tokens must be issued for this user",
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
+			"lldap_dynamic_group":              resourceDynamicGroup(),
 			"lldap_group_attribute_assignment": resourceGroupAttributeAssignment(),
 			"lldap_group_attribute":            resourceGroupAttribute(),
+			"lldap_group_inclusion":            resourceGroupInclusion(),
 			"lldap_group_memberships":          resourceGroupMemberships(),
-			"lldap_group":                      resourceGroup(),
 			"lldap_member":                     resourceMember(),
 			"lldap_user_attribute_assignment":  resourceUserAttributeAssignment(),
 			"lldap_user_attribute":             resourceUserAttribute(),
 			"lldap_user_memberships":           resourceUserMemberships(),
-			"lldap_user":                       resourceUser(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"lldap_group_attributes": dataSourceGroupAttributes(),
+			"lldap_group_inclusion":  dataSourceGroupInclusion(),
 			"lldap_group":            dataSourceGroup(),
 			"lldap_groups":           dataSourceGroups(),
+			"lldap_object_classes":   dataSourceObjectClasses(),
 			"lldap_user_attributes":  dataSourceUserAttributes(),
 			"lldap_user":             dataSourceUser(),
 			"lldap_users":            dataSourceUsers(),
@@ -80,39 +196,152 @@ func Provider() *schema.Provider {
 	}
 
 	provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (any, diag.Diagnostics) {
-		rawHttpUrl := d.Get("http_url").(string)
-		parsedHttpUrl, parseHttpUrlErr := url.Parse(rawHttpUrl)
-		if parseHttpUrlErr != nil {
-			return nil, diag.FromErr(parseHttpUrlErr)
-		}
-		if parsedHttpUrl.Scheme != "http" && parsedHttpUrl.Scheme != "https" {
-			return nil, diag.Errorf("Invalid LLDAP HTTP URL: '%s'", rawHttpUrl)
+		settings := providerSettings{
+			HttpUrl:               d.Get("http_url").(string),
+			LdapUrl:               d.Get("ldap_url").(string),
+			UserName:              d.Get("username").(string),
+			Password:              d.Get("password").(string),
+			PasswordFile:          d.Get("password_file").(string),
+			PasswordCommand:       d.Get("password_command").(string),
+			PasswordPolicy:        passwordPolicyFromList(d.Get("password_policy").([]any)),
+			Token:                 d.Get("token").(string),
+			RefreshToken:          d.Get("refresh_token").(string),
+			BaseDn:                d.Get("base_dn").(string),
+			InsecureSkipCertCheck: d.Get("insecure_skip_cert_check").(bool),
+			DeletionProtection:    d.Get("deletion_protection").(bool),
+			AllowAdminLockout:     d.Get("allow_admin_lockout").(bool),
+			ManagedMarker:         d.Get("managed_marker").(string),
+			MaxConcurrentRequests: d.Get("max_concurrent_requests").(int),
+			ReadOnly:              d.Get("read_only").(bool),
+			ReconcileManagedOnly:  d.Get("reconcile_managed_only").(bool),
 		}
-		rawLdapUrl := d.Get("ldap_url").(string)
-		parsedLdapUrl, parseLdapUrlErr := url.Parse(rawLdapUrl)
-		if parseLdapUrlErr != nil {
-			return nil, diag.FromErr(parseLdapUrlErr)
+		client, clientErr := newLldapClient(ctx, settings)
+		if clientErr != nil {
+			return nil, diag.FromErr(clientErr)
 		}
-		if parsedLdapUrl.Scheme != "ldap" && parsedLdapUrl.Scheme != "ldaps" {
-			return nil, diag.Errorf("Invalid LLDAP LDAP URL: '%s'", rawLdapUrl)
+		preflightErr := client.preflight(settings)
+		if preflightErr.HasError() {
+			return nil, preflightErr
 		}
-		client := LldapClient{
-			Config: Config{
-				Context:               ctx,
-				HttpUrl:               parsedHttpUrl,
-				LdapUrl:               parsedLdapUrl,
-				UserName:              d.Get("username").(string),
-				Password:              d.Get("password").(string),
-				BaseDn:                d.Get("base_dn").(string),
-				InsecureSkipCertCheck: d.Get("insecure_skip_cert_check").(bool),
-			},
-		}
-		return &client, nil
+		return client, preflightErr
+	}
+
+	for typeName, r := range provider.ResourcesMap {
+		readOnlyResource(typeName, r)
 	}
 
 	return provider
 }
 
+// providerSettings holds the provider arguments with defaults applied. Both the
+// SDK and the framework provider resolve them into the same client.
+type providerSettings struct {
+	HttpUrl               string
+	LdapUrl               string
+	UserName              string
+	Password              string
+	PasswordFile          string
+	PasswordCommand       string
+	PasswordPolicy        PasswordPolicy
+	Token                 string
+	RefreshToken          string
+	BaseDn                string
+	InsecureSkipCertCheck bool
+	DeletionProtection    bool
+	AllowAdminLockout     bool
+	ManagedMarker         string
+	MaxConcurrentRequests int
+	ReadOnly              bool
+	ReconcileManagedOnly  bool
+}
+
+func newLldapClient(ctx context.Context, settings providerSettings) (*LldapClient, error) {
+	if settings.HttpUrl == "" {
+		return nil, fmt.Errorf("http_url must be set, either in the provider configuration or using the LLDAP_HTTP_URL environment variable")
+	}
+	parsedHttpUrl, parseHttpUrlErr := url.Parse(settings.HttpUrl)
+	if parseHttpUrlErr != nil {
+		return nil, parseHttpUrlErr
+	}
+	if parsedHttpUrl.Scheme != "http" && parsedHttpUrl.Scheme != "https" {
+		return nil, fmt.Errorf("Invalid LLDAP HTTP URL: '%s'", settings.HttpUrl)
+	}
+	if settings.LdapUrl == "" {
+		return nil, fmt.Errorf("ldap_url must be set, either in the provider configuration or using the LLDAP_LDAP_URL environment variable")
+	}
+	parsedLdapUrl, parseLdapUrlErr := url.Parse(settings.LdapUrl)
+	if parseLdapUrlErr != nil {
+		return nil, parseLdapUrlErr
+	}
+	if parsedLdapUrl.Scheme != "ldap" && parsedLdapUrl.Scheme != "ldaps" {
+		return nil, fmt.Errorf("Invalid LLDAP LDAP URL: '%s'", settings.LdapUrl)
+	}
+	password, passwordErr := ResolvePassword(settings.Password, settings.PasswordFile, settings.PasswordCommand)
+	if passwordErr != nil {
+		return nil, passwordErr
+	}
+	if password == "" && settings.Token == "" && settings.RefreshToken == "" {
+		return nil, fmt.Errorf("one of password, password_file, password_command, token or refresh_token must be set, either in the provider configuration or using the LLDAP_PASSWORD, LLDAP_PASSWORD_FILE, LLDAP_PASSWORD_COMMAND, LLDAP_TOKEN or LLDAP_REFRESH_TOKEN environment variable")
+	}
+	// With a token, the username is taken from its claims unless it is set
+	if settings.UserName == "" && settings.Token == "" && settings.RefreshToken == "" {
+		settings.UserName = "admin"
+	}
+	if settings.MaxConcurrentRequests < 1 {
+		return nil, fmt.Errorf("max_concurrent_requests must be at least 1")
+	}
+	if settings.ReconcileManagedOnly && settings.ManagedMarker == "" {
+		return nil, fmt.Errorf("reconcile_managed_only requires managed_marker to be set")
+	}
+	client := LldapClient{
+		Config: Config{
+			Context:               ctx,
+			HttpUrl:               parsedHttpUrl,
+			LdapUrl:               parsedLdapUrl,
+			UserName:              settings.UserName,
+			Password:              password,
+			BaseDn:                settings.BaseDn,
+			InsecureSkipCertCheck: settings.InsecureSkipCertCheck,
+			DeletionProtection:    settings.DeletionProtection,
+			AllowAdminLockout:     settings.AllowAdminLockout,
+			ManagedMarker:         settings.ManagedMarker,
+			MaxConcurrentRequests: settings.MaxConcurrentRequests,
+			ReadOnly:              settings.ReadOnly,
+			ReconcileManagedOnly:  settings.ReconcileManagedOnly,
+		},
+		RefreshToken:      settings.RefreshToken,
+		membershipClaims:  newMembershipClaims(),
+		authLock:          &sync.Mutex{},
+		builtinAttributes: newBuiltinAttributes(),
+	}
+	if policyErr := client.SetPasswordPolicy(settings.PasswordPolicy); policyErr != nil {
+		return nil, policyErr
+	}
+	if settings.Token != "" {
+		if setTokenErr := client.SetToken(settings.Token); setTokenErr != nil {
+			return nil, setTokenErr
+		}
+	}
+	return &client, nil
+}
+
+// passwordPolicyFromList converts the password_policy block, the zero policy accepts any password.
+func passwordPolicyFromList(blocks []any) PasswordPolicy {
+	if len(blocks) == 0 || blocks[0] == nil {
+		return PasswordPolicy{}
+	}
+	block := blocks[0].(map[string]any)
+	return PasswordPolicy{
+		MinLength:              block["min_length"].(int),
+		RequireLowercase:       block["require_lowercase"].(bool),
+		RequireUppercase:       block["require_uppercase"].(bool),
+		RequireDigit:           block["require_digit"].(bool),
+		RequireSymbol:          block["require_symbol"].(bool),
+		RejectUsernameAndEmail: block["reject_username_and_email"].(bool),
+		BreachedPasswordsFile:  block["breached_passwords_file"].(string),
+	}
+}
+
 func dataSourceSetHashId(d *schema.ResourceData, v any) diag.Diagnostics {
 	hashBase, marshalErr := json.Marshal(v)
 	if marshalErr != nil {
@@ -150,6 +379,31 @@ func attributesParser(attrs []LldapCustomAttribute) []map[string]any {
 	return result
 }
 
+// attributeValueDiffSuppress suppresses differences between custom attribute
+// values that only differ in their representation, using the attribute type
+// stored in state.
+func attributeValueDiffSuppress(_, _, _ string, d *schema.ResourceData) bool {
+	attributeType := LldapCustomAttributeType(d.Get("attribute_type").(string))
+	if attributeType == "" {
+		return false
+	}
+	oldRaw, newRaw := d.GetChange("value")
+	return AttributeValuesEquivalent(attributeType, attributeValueSetToList(oldRaw), attributeValueSetToList(newRaw))
+}
+
+func attributeValueSetToList(v any) []string {
+	valueRaw, ok := v.(*schema.Set)
+	if !ok || valueRaw == nil {
+		return []string{}
+	}
+	valueRawList := valueRaw.List()
+	value := make([]string, len(valueRawList))
+	for i, vRaw := range valueRawList {
+		value[i] = vRaw.(string)
+	}
+	return value
+}
+
 var dataSourceGroupsSchema = schema.Schema{
 	Type:        schema.TypeSet,
 	Computed:    true,
@@ -174,3 +428,16 @@ var dataSourceGroupsSchema = schema.Schema{
 		},
 	},
 }
+
+func intSetToList(v any) []int {
+	valueRaw, ok := v.(*schema.Set)
+	if !ok || valueRaw == nil {
+		return []int{}
+	}
+	valueRawList := valueRaw.List()
+	value := make([]int, len(valueRawList))
+	for i, vRaw := range valueRawList {
+		value[i] = vRaw.(int)
+	}
+	return value
+}
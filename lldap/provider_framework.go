@@ -0,0 +1,364 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"os"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// The provider is being migrated from terraform-plugin-sdk/v2 to
+// terraform-plugin-framework one resource at a time. Both providers are served
+// together through terraform-plugin-mux, so every resource and data source must
+// be registered in exactly one of them, and their provider schemas must be
+// identical.
+
+// ProviderServer returns a factory for the muxed provider server, combining the
+// SDK provider with the framework provider. The SDK provider is upgraded to
+// protocol version 6, which is required for nested attributes.
+func ProviderServer(ctx context.Context) (func() tfprotov6.ProviderServer, error) {
+	sdkServer, upgradeErr := tf5to6server.UpgradeServer(ctx, Provider().GRPCProvider)
+	if upgradeErr != nil {
+		return nil, upgradeErr
+	}
+	muxServer, muxErr := tf6muxserver.NewMuxServer(ctx,
+		providerserver.NewProtocol6(NewFrameworkProvider()),
+		func() tfprotov6.ProviderServer { return sdkServer },
+	)
+	if muxErr != nil {
+		return nil, muxErr
+	}
+	return muxServer.ProviderServer, nil
+}
+
+type frameworkProvider struct{}
+
+var _ provider.ProviderWithFunctions = &frameworkProvider{}
+
+type frameworkProviderModel struct {
+	AllowAdminLockout     types.Bool                     `tfsdk:"allow_admin_lockout"`
+	BaseDn                types.String                   `tfsdk:"base_dn"`
+	DeletionProtection    types.Bool                     `tfsdk:"deletion_protection"`
+	HttpUrl               types.String                   `tfsdk:"http_url"`
+	InsecureSkipCertCheck types.Bool                     `tfsdk:"insecure_skip_cert_check"`
+	LdapUrl               types.String                   `tfsdk:"ldap_url"`
+	ManagedMarker         types.String                   `tfsdk:"managed_marker"`
+	MaxConcurrentRequests types.Int64                    `tfsdk:"max_concurrent_requests"`
+	Password              types.String                   `tfsdk:"password"`
+	PasswordCommand       types.String                   `tfsdk:"password_command"`
+	PasswordFile          types.String                   `tfsdk:"password_file"`
+	PasswordPolicy        []frameworkPasswordPolicyModel `tfsdk:"password_policy"`
+	ReadOnly              types.Bool                     `tfsdk:"read_only"`
+	ReconcileManagedOnly  types.Bool                     `tfsdk:"reconcile_managed_only"`
+	RefreshToken          types.String                   `tfsdk:"refresh_token"`
+	Token                 types.String                   `tfsdk:"token"`
+	Username              types.String                   `tfsdk:"username"`
+}
+
+type frameworkPasswordPolicyModel struct {
+	BreachedPasswordsFile  types.String `tfsdk:"breached_passwords_file"`
+	MinLength              types.Int64  `tfsdk:"min_length"`
+	RejectUsernameAndEmail types.Bool   `tfsdk:"reject_username_and_email"`
+	RequireDigit           types.Bool   `tfsdk:"require_digit"`
+	RequireLowercase       types.Bool   `tfsdk:"require_lowercase"`
+	RequireSymbol          types.Bool   `tfsdk:"require_symbol"`
+	RequireUppercase       types.Bool   `tfsdk:"require_uppercase"`
+}
+
+// passwordPolicy converts the password_policy block, the zero policy accepts any password.
+func (m frameworkProviderModel) passwordPolicy() PasswordPolicy {
+	if len(m.PasswordPolicy) == 0 {
+		return PasswordPolicy{}
+	}
+	block := m.PasswordPolicy[0]
+	return PasswordPolicy{
+		MinLength:              int(block.MinLength.ValueInt64()),
+		RequireLowercase:       block.RequireLowercase.ValueBool(),
+		RequireUppercase:       block.RequireUppercase.ValueBool(),
+		RequireDigit:           block.RequireDigit.ValueBool(),
+		RequireSymbol:          block.RequireSymbol.ValueBool(),
+		RejectUsernameAndEmail: block.RejectUsernameAndEmail.ValueBool(),
+		BreachedPasswordsFile:  block.BreachedPasswordsFile.ValueString(),
+	}
+}
+
+func NewFrameworkProvider() provider.Provider {
+	return &frameworkProvider{}
+}
+
+func (p *frameworkProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "lldap"
+}
+
+// Schema must stay identical to the schema of the SDK provider in provider.go.
+func (p *frameworkProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"allow_admin_lockout": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Allow changes that revoke the admin access of the provider's own `username`, such as removing it from `lldap_admin`, deleting it or changing its password (default: `false`)",
+			},
+			"base_dn": schema.StringAttribute{
+				Optional:    true,
+				Description: "Base DN, defaults to `dc=example,dc=com`",
+			},
+			"deletion_protection": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Default for `deletion_protection` on users, groups and attribute schemas (default: `false`)",
+			},
+			"http_url": schema.StringAttribute{
+				Optional:    true,
+				Description: "HTTP URL in the format `http[s]://(hostname)[:port]`, can be set using the `LLDAP_HTTP_URL` environment variable",
+			},
+			"insecure_skip_cert_check": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Disable check for valid certificate chain for https/ldaps (default: `false`)",
+			},
+			"ldap_url": schema.StringAttribute{
+				Optional:    true,
+				Description: "LDAP URL in the format `ldap[s]://(hostname)[:port]`, can be set using the `LLDAP_LDAP_URL` environment variable",
+			},
+			"managed_marker": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of a custom user and group attribute that tags objects created by this provider, or managed by `lldap_user` and `lldap_group`, as managed by Terraform, the attribute schemas are created on first use",
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Maximum number of concurrent API requests when reconciling memberships, defaults to `4`",
+			},
+			"password": schema.StringAttribute{
+				Optional:    true,
+				Description: "admin account password, can be set using the `LLDAP_PASSWORD` environment variable",
+			},
+			"password_command": schema.StringAttribute{
+				Optional:    true,
+				Description: "Shell command printing the password, e.g. of a secret manager CLI, can be set using the `LLDAP_PASSWORD_COMMAND` environment variable",
+			},
+			"password_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "File containing the password, e.g. a mounted secret, can be set using the `LLDAP_PASSWORD_FILE` environment variable",
+			},
+			"read_only": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Refuse every change, including password changes, before anything is sent to LLDAP, e.g. for plans with credentials of the `lldap_strict_readonly` group (default: `false`)",
+			},
+			"reconcile_managed_only": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Only let `lldap_group_memberships` and `lldap_user_memberships` remove memberships that were never configured if both the user and the group are tagged with `managed_marker`, other differences are reported as warnings (default: `false`)",
+			},
+			"refresh_token": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Refresh token used to get tokens instead of logging in with a password, can be set using the `LLDAP_REFRESH_TOKEN` environment variable",
+			},
+			"token": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Pre-issued JWT used instead of logging in with a password, issued for `username` if that is set, can be set using the `LLDAP_TOKEN` environment variable",
+			},
+			"username": schema.StringAttribute{
+				Optional:    true,
+				Description: "admin account username, defaults to `admin`, or to the user of `token` or `refresh_token`. If set, tokens must be issued for this user",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			// At most one block, which is validated by the SDK provider
+			"password_policy": schema.ListNestedBlock{
+				Description: "Checks passwords before they are sent to LLDAP, which accepts any password. Passwords known at plan time are checked during the plan",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"breached_passwords_file": schema.StringAttribute{
+							Optional:    true,
+							Description: "File with one breached password per line, either in plain text or as SHA-1 hash in hex, optionally followed by `:count` as in the Have I Been Pwned downloads",
+						},
+						"min_length": schema.Int64Attribute{
+							Optional:    true,
+							Description: "Minimum number of characters (default: `0`)",
+						},
+						"reject_username_and_email": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Reject passwords containing the username, the email or the local part of the email, compared case-insensitively (default: `false`)",
+						},
+						"require_digit": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Require a digit (default: `false`)",
+						},
+						"require_lowercase": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Require a lowercase letter (default: `false`)",
+						},
+						"require_symbol": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Require a character that is neither a letter, a digit nor whitespace (default: `false`)",
+						},
+						"require_uppercase": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Require an uppercase letter (default: `false`)",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config frameworkProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	settings := providerSettings{
+		HttpUrl:               stringValueOrDefault(config.HttpUrl, os.Getenv("LLDAP_HTTP_URL")),
+		LdapUrl:               stringValueOrDefault(config.LdapUrl, os.Getenv("LLDAP_LDAP_URL")),
+		UserName:              config.Username.ValueString(),
+		Password:              stringValueOrDefault(config.Password, os.Getenv("LLDAP_PASSWORD")),
+		PasswordFile:          stringValueOrDefault(config.PasswordFile, os.Getenv("LLDAP_PASSWORD_FILE")),
+		PasswordCommand:       stringValueOrDefault(config.PasswordCommand, os.Getenv("LLDAP_PASSWORD_COMMAND")),
+		PasswordPolicy:        config.passwordPolicy(),
+		Token:                 stringValueOrDefault(config.Token, os.Getenv("LLDAP_TOKEN")),
+		RefreshToken:          stringValueOrDefault(config.RefreshToken, os.Getenv("LLDAP_REFRESH_TOKEN")),
+		BaseDn:                stringValueOrDefault(config.BaseDn, "dc=example,dc=com"),
+		InsecureSkipCertCheck: config.InsecureSkipCertCheck.ValueBool(),
+		DeletionProtection:    config.DeletionProtection.ValueBool(),
+		AllowAdminLockout:     config.AllowAdminLockout.ValueBool(),
+		ManagedMarker:         config.ManagedMarker.ValueString(),
+		MaxConcurrentRequests: int(int64ValueOrDefault(config.MaxConcurrentRequests, DefaultMaxConcurrentRequests)),
+		ReadOnly:              config.ReadOnly.ValueBool(),
+		ReconcileManagedOnly:  config.ReconcileManagedOnly.ValueBool(),
+	}
+	client, clientErr := newLldapClient(ctx, settings)
+	if clientErr != nil {
+		resp.Diagnostics.AddError("Invalid provider configuration", clientErr.Error())
+		return
+	}
+	resp.Diagnostics.Append(frameworkDiagnostics(client.preflight(settings))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.DataSourceData = client
+	resp.ResourceData = client
+}
+
+func (p *frameworkProvider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		NewGroupObjectClassResource,
+		NewGroupResource,
+		NewUserObjectClassResource,
+		NewUserResource,
+		NewUsersResource,
+	}
+}
+
+func (p *frameworkProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{
+		NewLdapSearchDataSource,
+	}
+}
+
+func (p *frameworkProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewEscapeDnValueFunction,
+		NewEscapeFilterFunction,
+		NewGroupDnFunction,
+		NewMemberOfFilterFunction,
+		NewUserDnFunction,
+	}
+}
+
+func stringValueOrDefault(value types.String, defaultValue string) string {
+	if value.IsNull() || value.IsUnknown() {
+		return defaultValue
+	}
+	return value.ValueString()
+}
+
+func int64ValueOrDefault(value types.Int64, defaultValue int64) int64 {
+	if value.IsNull() || value.IsUnknown() {
+		return defaultValue
+	}
+	return value.ValueInt64()
+}
+
+// optionalStringValue returns a string read from LLDAP, which does not distinguish empty from unset
+// fields. An empty value is null, unless prior, the value from the plan or state, is empty as well.
+func optionalStringValue(value string, prior types.String) types.String {
+	if value == "" && !prior.Equal(types.StringValue("")) {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
+
+// stringSetValue returns the strings as a set, without duplicates.
+func stringSetValue(values []string) types.Set {
+	elements := make([]attr.Value, 0, len(values))
+	for _, value := range slices.Compact(slices.Sorted(slices.Values(values))) {
+		elements = append(elements, types.StringValue(value))
+	}
+	return types.SetValueMust(types.StringType, elements)
+}
+
+var customAttributeObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"name":  types.StringType,
+		"value": types.SetType{ElemType: types.StringType},
+	},
+}
+
+// customAttributesValue converts custom attributes into the computed attributes of lldap_user and lldap_group.
+func customAttributesValue(attributes []LldapCustomAttribute) types.Set {
+	elements := make([]attr.Value, len(attributes))
+	for i, attribute := range attributes {
+		elements[i] = types.ObjectValueMust(customAttributeObjectType.AttrTypes, map[string]attr.Value{
+			"name":  types.StringValue(attribute.Name),
+			"value": stringSetValue(attribute.Value),
+		})
+	}
+	return types.SetValueMust(customAttributeObjectType, elements)
+}
+
+// frameworkDiagnostics converts diagnostics returned by the client into framework diagnostics.
+func frameworkDiagnostics(diags diag.Diagnostics) fwdiag.Diagnostics {
+	result := fwdiag.Diagnostics{}
+	for _, d := range diags {
+		if d.Severity == diag.Warning {
+			result.AddWarning(d.Summary, d.Detail)
+		} else {
+			result.AddError(d.Summary, d.Detail)
+		}
+	}
+	return result
+}
+
+// frameworkClient returns the client passed by the provider to Configure of a resource or data source.
+func frameworkClient(providerData any) (*LldapClient, fwdiag.Diagnostics) {
+	diags := fwdiag.Diagnostics{}
+	if providerData == nil {
+		// The provider is not configured yet, e.g. during validation
+		return nil, diags
+	}
+	client, ok := providerData.(*LldapClient)
+	if !ok {
+		diags.AddError("Unexpected provider data", "Expected the LLDAP client, this is a bug in the provider.")
+	}
+	return client, diags
+}
@@ -0,0 +1,194 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var objectClassStateType = tftypes.Object{
+	AttributeTypes: map[string]tftypes.Type{
+		"id":   tftypes.String,
+		"name": tftypes.String,
+	},
+}
+
+func testProviderServer(t *testing.T) tfprotov6.ProviderServer {
+	providerServer, providerServerErr := ProviderServer(context.Background())
+	require.NoError(t, providerServerErr)
+	return providerServer()
+}
+
+func objectClassValue(id any, name any) tftypes.Value {
+	return tftypes.NewValue(objectClassStateType, map[string]tftypes.Value{
+		"id":   tftypes.NewValue(tftypes.String, id),
+		"name": tftypes.NewValue(tftypes.String, name),
+	})
+}
+
+func dynamicValue(t *testing.T, value tftypes.Value) *tfprotov6.DynamicValue {
+	dv, dvErr := tfprotov6.NewDynamicValue(value.Type(), value)
+	require.NoError(t, dvErr)
+	return &dv
+}
+
+// testResourceState returns a state of a framework resource with the given attributes, all others are null.
+func testResourceState(t *testing.T, r resource.Resource, attributes map[string]any) tfsdk.State {
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	state := tfsdk.State{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+	}
+	for name, value := range attributes {
+		diags := state.SetAttribute(ctx, path.Root(name), value)
+		require.False(t, diags.HasError(), diags)
+	}
+	return state
+}
+
+func TestProviderServerSchema(t *testing.T) {
+	resp, err := testProviderServer(t).GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	// The mux server reports differing provider schemas and duplicate resource types as errors
+	assert.Empty(t, resp.Diagnostics)
+	for name := range Provider().ResourcesMap {
+		assert.Contains(t, resp.ResourceSchemas, name)
+	}
+	for name := range Provider().DataSourcesMap {
+		assert.Contains(t, resp.DataSourceSchemas, name)
+	}
+	assert.Contains(t, resp.ResourceSchemas, "lldap_user_object_class")
+	assert.Contains(t, resp.ResourceSchemas, "lldap_group_object_class")
+	assert.Contains(t, resp.ResourceSchemas, "lldap_users")
+	assert.Contains(t, resp.ResourceSchemas, "lldap_user")
+	assert.Contains(t, resp.ResourceSchemas, "lldap_group")
+}
+
+// State written by the SDK implementations of migrated resources must be readable without changes.
+func TestObjectClassStateCompatibility(t *testing.T) {
+	server := testProviderServer(t)
+	for _, typeName := range []string{"lldap_user_object_class", "lldap_group_object_class"} {
+		resp, err := server.UpgradeResourceState(context.Background(), &tfprotov6.UpgradeResourceStateRequest{
+			TypeName: typeName,
+			Version:  0,
+			RawState: &tfprotov6.RawState{JSON: []byte(`{"id":"posixAccount","name":"posixAccount"}`)},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Diagnostics, typeName)
+		upgraded, unmarshalErr := resp.UpgradedState.Unmarshal(objectClassStateType)
+		require.NoError(t, unmarshalErr)
+		assert.True(t, upgraded.Equal(objectClassValue("posixAccount", "posixAccount")), typeName)
+	}
+}
+
+func TestObjectClassPlanCaseChange(t *testing.T) {
+	server := testProviderServer(t)
+	plan := func(name string) *tfprotov6.PlanResourceChangeResponse {
+		resp, err := server.PlanResourceChange(context.Background(), &tfprotov6.PlanResourceChangeRequest{
+			TypeName:         "lldap_user_object_class",
+			PriorState:       dynamicValue(t, objectClassValue("posixAccount", "posixAccount")),
+			ProposedNewState: dynamicValue(t, objectClassValue("posixAccount", name)),
+			Config:           dynamicValue(t, objectClassValue(nil, name)),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Diagnostics)
+		return resp
+	}
+	assert.Empty(t, plan("posixaccount").RequiresReplace)
+	assert.Len(t, plan("mailRecipient").RequiresReplace, 1)
+}
+
+// planUnchanged upgrades state written by the baseline SDK schema and plans it against a configuration
+// that only sets the configured attributes. It returns the upgraded state.
+func planUnchanged(t *testing.T, typeName string, rawState string, configured []string) map[string]tftypes.Value {
+	ctx := context.Background()
+	server := testProviderServer(t)
+	schemaResp, schemaErr := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	require.NoError(t, schemaErr)
+	stateType := schemaResp.ResourceSchemas[typeName].ValueType()
+
+	upgradeResp, upgradeErr := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: typeName,
+		Version:  0,
+		RawState: &tfprotov6.RawState{JSON: []byte(rawState)},
+	})
+	require.NoError(t, upgradeErr)
+	require.Empty(t, upgradeResp.Diagnostics, typeName)
+	upgraded, unmarshalErr := upgradeResp.UpgradedState.Unmarshal(stateType)
+	require.NoError(t, unmarshalErr)
+	var attributes map[string]tftypes.Value
+	require.NoError(t, upgraded.As(&attributes))
+
+	configAttributes := make(map[string]tftypes.Value, len(attributes))
+	for name, value := range attributes {
+		if slices.Contains(configured, name) {
+			configAttributes[name] = value
+		} else {
+			configAttributes[name] = tftypes.NewValue(value.Type(), nil)
+		}
+	}
+	planResp, planErr := server.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       upgradeResp.UpgradedState,
+		ProposedNewState: upgradeResp.UpgradedState,
+		Config:           dynamicValue(t, tftypes.NewValue(stateType, configAttributes)),
+	})
+	require.NoError(t, planErr)
+	assert.Empty(t, planResp.Diagnostics, typeName)
+	assert.Empty(t, planResp.RequiresReplace, typeName)
+	planned, unmarshalErr := planResp.PlannedState.Unmarshal(stateType)
+	require.NoError(t, unmarshalErr)
+	assert.True(t, planned.Equal(upgraded), planned.Diff)
+	return attributes
+}
+
+func TestUserStateCompatibility(t *testing.T) {
+	upgraded := planUnchanged(t, "lldap_user", `{
+		"attributes": [{"name": "level", "value": ["3"]}],
+		"avatar": "",
+		"creation_date": "2024-01-01T00:00:00.000000000+00:00",
+		"display_name": "",
+		"email": "alice@example.com",
+		"first_name": "Alice",
+		"groups": [{"creation_date": "", "display_name": "lldap_admin", "id": "1"}],
+		"id": "alice",
+		"last_name": "",
+		"password": null,
+		"username": "alice",
+		"uuid": "6d9d7d6e-3c1c-4c8e-9d0e-7b2b5e1f4c2a"
+	}`, []string{"email", "first_name", "username"})
+	// The SDK stored unset profile fields as empty strings
+	for _, name := range []string{"avatar", "display_name", "last_name", "password", "password_wo", "password_wo_version"} {
+		assert.True(t, upgraded[name].IsNull(), name)
+	}
+	assert.True(t, upgraded["rename_strategy"].Equal(tftypes.NewValue(tftypes.String, RenameStrategyRecreate)))
+	assert.True(t, upgraded["first_name"].Equal(tftypes.NewValue(tftypes.String, "Alice")))
+}
+
+func TestGroupStateCompatibility(t *testing.T) {
+	upgraded := planUnchanged(t, "lldap_group", `{
+		"attributes": [],
+		"creation_date": "2024-01-01T00:00:00.000000000+00:00",
+		"display_name": "admins",
+		"id": "3",
+		"users": ["alice"],
+		"uuid": "0b3d7f1e-2c4a-4e5b-8f6d-9a1c2b3d4e5f"
+	}`, []string{"display_name"})
+	assert.True(t, upgraded["deletion_protection"].IsNull())
+}
@@ -0,0 +1,47 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// With read_only, the provider refuses every change before anything is sent,
+// so plans work with credentials of the lldap_strict_readonly group.
+
+// checkReadOnly returns an error diagnostic if the provider is read-only.
+func (lc *LldapClient) checkReadOnly(action string) diag.Diagnostics {
+	if !lc.Config.ReadOnly {
+		return nil
+	}
+	return diag.Errorf("%s is not allowed, the provider is configured with read_only = true", action)
+}
+
+// readOnlyGuard wraps a create, update or delete function, so it fails in read-only mode without being called.
+func readOnlyGuard[F ~func(context.Context, *schema.ResourceData, any) diag.Diagnostics](action string, f F) F {
+	return func(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+		if lc, ok := m.(*LldapClient); ok {
+			if readOnlyErr := lc.checkReadOnly(action); readOnlyErr != nil {
+				return readOnlyErr
+			}
+		}
+		return f(ctx, d, m)
+	}
+}
+
+// readOnlyResource guards all changes of the SDK resource.
+func readOnlyResource(typeName string, r *schema.Resource) {
+	r.CreateContext = readOnlyGuard(fmt.Sprintf("Creating %s", typeName), r.CreateContext)
+	if r.UpdateContext != nil {
+		r.UpdateContext = readOnlyGuard(fmt.Sprintf("Updating %s", typeName), r.UpdateContext)
+	}
+	r.DeleteContext = readOnlyGuard(fmt.Sprintf("Deleting %s", typeName), r.DeleteContext)
+}
@@ -0,0 +1,57 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnlyResource(t *testing.T) {
+	called := false
+	r := &schema.Resource{
+		CreateContext: func(context.Context, *schema.ResourceData, any) diag.Diagnostics {
+			called = true
+			return nil
+		},
+		DeleteContext: func(context.Context, *schema.ResourceData, any) diag.Diagnostics {
+			called = true
+			return nil
+		},
+	}
+	readOnlyResource("lldap_test", r)
+	assert.Nil(t, r.UpdateContext)
+
+	lc := &LldapClient{Config: Config{ReadOnly: true}}
+	diags := r.CreateContext(context.Background(), nil, lc)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "Creating lldap_test")
+	diags = r.DeleteContext(context.Background(), nil, lc)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "Deleting lldap_test")
+	assert.False(t, called)
+
+	assert.Nil(t, r.CreateContext(context.Background(), nil, &LldapClient{}))
+	assert.True(t, called)
+}
+
+func TestReadOnlyClient(t *testing.T) {
+	// Nothing must be sent, the client has neither a token nor a server
+	lc := &LldapClient{Config: Config{ReadOnly: true}}
+	_, diags := lc.query(LldapClientQuery{OperationName: "DeleteUser", Query: "mutation DeleteUser($userId: String!) { deleteUser(userId: $userId) { ok } }"})
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "Mutation DeleteUser")
+	assert.Contains(t, diags[0].Summary, "read_only")
+
+	diags = lc.SetUserPassword("someone", "new password")
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "password of user 'someone'")
+}
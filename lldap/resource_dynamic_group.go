@@ -0,0 +1,219 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceDynamicGroup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDynamicGroupCreate,
+		ReadContext:   resourceDynamicGroupRead,
+		UpdateContext: resourceDynamicGroupUpdate,
+		DeleteContext: resourceDynamicGroupDelete,
+		CustomizeDiff: resourceDynamicGroupCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: func(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
+				groupId, resolveErr := resolveGroupImportId(m.(*LldapClient), d.Id())
+				if resolveErr != nil {
+					return nil, resolveErr
+				}
+				d.SetId(strconv.Itoa(groupId))
+				_ = d.Set("id", d.Id())
+				_ = d.Set("group_id", groupId)
+				return schema.ImportStatePassthroughContext(ctx, d, m)
+			},
+		},
+		Description: "Exclusively manages the memberships of a LLDAP group, so they match all users selected by a filter",
+		Schema: map[string]*schema.Schema{
+			"filter": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "LDAP search filter (RFC 4515) over user fields and custom attributes, e.g. `(&(department=engineering)(!(uid=admin)))`",
+				ValidateFunc: func(val any, key string) (warns []string, errs []error) {
+					if _, parseErr := ParseUserFilter(val.(string)); parseErr != nil {
+						errs = append(errs, fmt.Errorf("%q: %s", key, parseErr))
+					}
+					return
+				},
+			},
+			"group_id": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The unique group id",
+			},
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID representing this dynamic group",
+			},
+			"members": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "User ids that are members of this group, the plan shows which users will be added or removed",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// resourceDynamicGroupCustomizeDiff evaluates the filter at plan time, so membership changes show up in the plan.
+// Users that are created within the same apply are picked up on the next apply.
+func resourceDynamicGroupCustomizeDiff(_ context.Context, d *schema.ResourceDiff, m any) error {
+	if !d.NewValueKnown("filter") {
+		return d.SetNewComputed("members")
+	}
+	lc, ok := m.(*LldapClient)
+	if !ok {
+		return nil
+	}
+	members, getMembersErr := resourceDynamicGroupGetMatchingUserIds(lc, d.Get("filter").(string))
+	if getMembersErr != nil {
+		return fmt.Errorf("could not evaluate filter: %v", getMembersErr)
+	}
+	if _, guarded := lockoutGuard(m); guarded && d.NewValueKnown("group_id") && !slices.ContainsFunc(members, lc.isProviderUser) {
+		if lockoutErr := lc.checkGroupMembersRemoval(d.Get("group_id").(int), []string{lc.Config.UserName}); lockoutErr != nil {
+			return lockoutErr
+		}
+	}
+	currentMembers := attributeValueSetToList(d.Get("members"))
+	slices.Sort(currentMembers)
+	if d.Id() == "" || !slices.Equal(currentMembers, members) {
+		return d.SetNew("members", members)
+	}
+	return nil
+}
+
+func resourceDynamicGroupGetMatchingUserIds(lc *LldapClient, expression string) ([]string, diag.Diagnostics) {
+	filter, parseErr := ParseUserFilter(expression)
+	if parseErr != nil {
+		return nil, diag.FromErr(parseErr)
+	}
+	users, getUsersErr := lc.GetUsersWithAttributes()
+	if getUsersErr != nil {
+		return nil, getUsersErr
+	}
+	return filter.MatchingUserIds(users), nil
+}
+
+// resourceDynamicGroupReconcile reports whether any membership was changed, so a partially failed create is still recorded in state.
+func resourceDynamicGroupReconcile(ctx context.Context, d *schema.ResourceData, lc *LldapClient) (bool, diag.Diagnostics) {
+	groupId := d.Get("group_id").(int)
+	wantsUserIds, getMatchingErr := resourceDynamicGroupGetMatchingUserIds(lc, d.Get("filter").(string))
+	if getMatchingErr != nil {
+		return false, getMatchingErr
+	}
+	group, getGroupErr := lc.GetGroup(groupId)
+	if getGroupErr != nil {
+		return false, getGroupErr
+	}
+	hasUserIds := group.GetUserIds()
+	addUserIds := slices.DeleteFunc(slices.Clone(wantsUserIds), func(userId string) bool {
+		return slices.Contains(hasUserIds, userId)
+	})
+	removeUserIds := slices.DeleteFunc(slices.Clone(hasUserIds), func(userId string) bool {
+		return slices.Contains(wantsUserIds, userId)
+	})
+	if _, guarded := lockoutGuard(lc); guarded {
+		if lockoutErr := lc.checkGroupMembersRemoval(groupId, removeUserIds); lockoutErr != nil {
+			return false, diag.FromErr(lockoutErr)
+		}
+	}
+	tflog.Info(ctx, fmt.Sprintf("Adding users %v to and removing users %v from dynamic group %d", addUserIds, removeUserIds, groupId))
+	addedUserIds, addErr := lc.AddUsersToGroup(groupId, addUserIds)
+	removedUserIds, removeErr := lc.RemoveUsersFromGroup(groupId, removeUserIds)
+	applied := len(addedUserIds) > 0 || len(removedUserIds) > 0
+	diags := append(addErr, removeErr...)
+	if diags.HasError() {
+		memberUserIds := slices.DeleteFunc(slices.Clone(hasUserIds), func(userId string) bool {
+			return slices.Contains(removedUserIds, userId)
+		})
+		memberUserIds = append(memberUserIds, addedUserIds...)
+		slices.Sort(memberUserIds)
+		wantsUserIds = memberUserIds
+	}
+	if setErr := d.Set("members", wantsUserIds); setErr != nil {
+		return applied, append(diags, diag.FromErr(setErr)...)
+	}
+	return applied, diags
+}
+
+func resourceDynamicGroupCreate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	lc := m.(*LldapClient)
+	applied, reconcileErr := resourceDynamicGroupReconcile(ctx, d, lc)
+	if reconcileErr.HasError() && !applied {
+		return reconcileErr
+	}
+	d.SetId(strconv.Itoa(d.Get("group_id").(int)))
+	return reconcileErr
+}
+
+func resourceDynamicGroupRead(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	lc := m.(*LldapClient)
+	groupId, getGroupIdErr := strconv.Atoi(d.Id())
+	if getGroupIdErr != nil {
+		return diag.FromErr(getGroupIdErr)
+	}
+	group, getGroupErr := lc.GetGroup(groupId)
+	if getGroupErr != nil {
+		// If the group was not found, mark the resource as deleted so Terraform will recreate it
+		if isEntityNotFoundError(getGroupErr) {
+			d.SetId("")
+			return nil
+		}
+		return getGroupErr
+	}
+	userIds := group.GetUserIds()
+	slices.Sort(userIds)
+	for k, v := range map[string]any{
+		"group_id": group.Id,
+		"members":  userIds,
+	} {
+		if setErr := d.Set(k, v); setErr != nil {
+			return diag.FromErr(setErr)
+		}
+	}
+	return nil
+}
+
+func resourceDynamicGroupUpdate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	lc := m.(*LldapClient)
+	_, reconcileErr := resourceDynamicGroupReconcile(ctx, d, lc)
+	return reconcileErr
+}
+
+func resourceDynamicGroupDelete(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	groupId := d.Get("group_id").(int)
+	lc := m.(*LldapClient)
+	memberUserIds := attributeValueSetToList(d.Get("members"))
+	if _, guarded := lockoutGuard(m); guarded {
+		if lockoutErr := lc.checkGroupMembersRemoval(groupId, memberUserIds); lockoutErr != nil {
+			return diag.FromErr(lockoutErr)
+		}
+	}
+	removedUserIds, removeErr := lc.RemoveUsersFromGroup(groupId, memberUserIds)
+	if removeErr.HasError() {
+		remainingUserIds := slices.DeleteFunc(memberUserIds, func(userId string) bool {
+			return slices.Contains(removedUserIds, userId)
+		})
+		if setErr := d.Set("members", remainingUserIds); setErr != nil {
+			return append(removeErr, diag.FromErr(setErr)...)
+		}
+	}
+	return removeErr
+}
@@ -8,165 +8,277 @@ package lldap
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func resourceGroup() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceGroupCreate,
-		ReadContext:   resourceGroupRead,
-		UpdateContext: resourceGroupUpdate,
-		DeleteContext: resourceGroupDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: func(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
-				_ = d.Set("id", d.Id())
-				return schema.ImportStatePassthroughContext(ctx, d, m)
-			},
-		},
+// groupResource implements lldap_group.
+type groupResource struct {
+	client *LldapClient
+}
+
+type groupResourceModel struct {
+	Attributes         types.Set    `tfsdk:"attributes"`
+	CreationDate       types.String `tfsdk:"creation_date"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
+	DisplayName        types.String `tfsdk:"display_name"`
+	Id                 types.String `tfsdk:"id"`
+	Users              types.Set    `tfsdk:"users"`
+	Uuid               types.String `tfsdk:"uuid"`
+}
+
+var (
+	_ resource.ResourceWithConfigure   = &groupResource{}
+	_ resource.ResourceWithImportState = &groupResource{}
+	_ resource.ResourceWithModifyPlan  = &groupResource{}
+)
+
+func NewGroupResource() resource.Resource {
+	return &groupResource{}
+}
+
+func (r *groupResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_group"
+}
+
+func (r *groupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
 		Description: "Manages a LLDAP group, without memberships",
-		Schema: map[string]*schema.Schema{
-			"creation_date": {
-				Type:        schema.TypeString,
+		Attributes: map[string]schema.Attribute{
+			"attributes": customAttributesSchema("Custom attributes for this group, without the built-in attributes LLDAP manages itself"),
+			"creation_date": schema.StringAttribute{
 				Computed:    true,
 				Description: "Metadata of group object creation",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"attributes": {
-				Type:        schema.TypeSet,
+			"deletion_protection": schema.BoolAttribute{
+				Optional:    true,
 				Computed:    true,
-				Description: "Attributes for this group",
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"name": {
-							Type:        schema.TypeString,
-							Required:    true,
-							Description: "Unique name of this attribute",
-						},
-						"value": {
-							Type:     schema.TypeSet,
-							Required: true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
-							Description: "List of values for this attribute",
-						},
-					},
-				},
+				Description: deletionProtectionSchema.Description,
 			},
-			"display_name": {
-				Type:        schema.TypeString,
+			"display_name": schema.StringAttribute{
 				Required:    true,
 				Description: "Display name of this group",
 			},
-			"id": {
-				Type:        schema.TypeString,
+			"id": schema.StringAttribute{
 				Computed:    true,
 				Description: "The unique group ID",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"users": {
-				Type:        schema.TypeSet,
+			"users": schema.SetAttribute{
 				Computed:    true,
+				ElementType: types.StringType,
 				Description: "Set of users who are members of this group",
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"uuid": {
-				Type:        schema.TypeString,
+			"uuid": schema.StringAttribute{
 				Computed:    true,
 				Description: "UUID of group",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
 }
 
-func resourceGroupSetResourceData(d *schema.ResourceData, group *LldapGroup) diag.Diagnostics {
-	for k, v := range map[string]any{
-		"attributes":    attributesParser(group.Attributes),
-		"creation_date": group.CreationDate,
-		"display_name":  group.DisplayName,
-		"users":         resourceGroupUsersParser(group.Users),
-		"uuid":          group.Uuid,
-	} {
-		if setErr := d.Set(k, v); setErr != nil {
-			return diag.FromErr(setErr)
-		}
-	}
-	return nil
+func (r *groupResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	client, clientDiags := frameworkClient(req.ProviderData)
+	resp.Diagnostics.Append(clientDiags...)
+	r.client = client
 }
 
-func resourceGroupUsersParser(llusers []LldapUser) *schema.Set {
-	result := make([]any, len(llusers))
-	for i, lluser := range llusers {
-		result[i] = lluser.Id
+// ModifyPlan refuses plans renaming the lldap_admin group and applies the provider default
+// if deletion_protection is not configured.
+func (r *groupResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+	if lc, guarded := lockoutGuard(r.client); guarded && !req.State.Raw.IsNull() {
+		var priorDisplayName, plannedDisplayName types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("display_name"), &priorDisplayName)...)
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("display_name"), &plannedDisplayName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if priorDisplayName.ValueString() == LldapAdminGroupName && !plannedDisplayName.Equal(priorDisplayName) {
+			resp.Diagnostics.AddAttributeError(path.Root("display_name"), "Refusing to rename the admin group",
+				lc.lockoutError(fmt.Sprintf("Renaming group %s", LldapAdminGroupName)).Error())
+			return
+		}
 	}
-	return schema.NewSet(schema.HashString, result)
+	planDeletionProtection(ctx, r.client, req, resp)
 }
 
-func resourceGroupCreate(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	group := LldapGroup{
-		DisplayName: d.Get("display_name").(string),
+func (r *groupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly("Creating lldap_group"))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var plan groupResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	lc := m.(*LldapClient)
-	createErr := lc.CreateGroup(&group)
-	if createErr != nil {
-		return createErr
+	group := LldapGroup{
+		DisplayName: plan.DisplayName.ValueString(),
 	}
-	d.SetId(strconv.Itoa(group.Id))
-	setRdErr := resourceGroupSetResourceData(d, &group)
-	if setRdErr != nil {
-		return setRdErr
+	createErr := r.client.CreateGroup(&group)
+	resp.Diagnostics.Append(frameworkDiagnostics(createErr)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	return nil
+	// The group exists from here on, so it is kept in state even if tagging it fails
+	markErr := r.client.MarkGroupManaged(&group)
+	r.setComputed(&plan, &group, &resp.Diagnostics)
+	resp.Diagnostics.Append(frameworkDiagnostics(markErr)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
-func resourceGroupRead(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	lc := m.(*LldapClient)
-	groupId, getGroupIdErr := strconv.Atoi(d.Id())
-	if getGroupIdErr != nil {
-		return diag.FromErr(getGroupIdErr)
+func (r *groupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state groupResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	group, getGroupErr := lc.GetGroup(groupId)
+	groupId, parseErr := strconv.Atoi(state.Id.ValueString())
+	if parseErr != nil {
+		resp.Diagnostics.AddError("Invalid group id", parseErr.Error())
+		return
+	}
+	group, getGroupErr := r.client.GetGroup(groupId)
 	if getGroupErr != nil {
 		// If the group was not found, mark the resource as deleted so Terraform will recreate it
 		if isEntityNotFoundError(getGroupErr) {
-			d.SetId("")
-			return nil
+			resp.State.RemoveResource(ctx)
+			return
 		}
-		return getGroupErr
+		resp.Diagnostics.Append(frameworkDiagnostics(getGroupErr)...)
+		return
+	}
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.markGroupManagedOnRead(group))...)
+	state.DisplayName = types.StringValue(group.DisplayName)
+	r.setComputed(&state, group, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+func (r *groupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly("Updating lldap_group"))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var plan, state groupResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// The prior state is kept if the update fails
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	groupId, parseErr := strconv.Atoi(state.Id.ValueString())
+	if parseErr != nil {
+		resp.Diagnostics.AddError("Invalid group id", parseErr.Error())
+		return
+	}
+	displayName := plan.DisplayName.ValueString()
+	updateErr := r.client.UpdateGroupDisplayName(groupId, displayName)
+	resp.Diagnostics.Append(frameworkDiagnostics(updateErr)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Groups imported or created before managed_marker was configured are tagged as well
+	markErr := r.client.MarkGroupManaged(&LldapGroup{Id: groupId, DisplayName: displayName})
+	resp.Diagnostics.Append(frameworkDiagnostics(markErr)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	setRdErr := resourceGroupSetResourceData(d, group)
-	if setRdErr != nil {
-		return setRdErr
+	group, getGroupErr := r.client.GetGroup(groupId)
+	resp.Diagnostics.Append(frameworkDiagnostics(getGroupErr)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	return nil
+	r.setComputed(&plan, group, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
-func resourceGroupUpdate(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	lc := m.(*LldapClient)
-	groupId, getGroupIdErr := strconv.Atoi(d.Id())
-	if getGroupIdErr != nil {
-		return diag.FromErr(getGroupIdErr)
+func (r *groupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly("Deleting lldap_group"))...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	displayName := d.Get("display_name").(string)
-	updateErr := lc.UpdateGroupDisplayName(groupId, displayName)
-	if updateErr != nil {
-		return updateErr
+	var state groupResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	return nil
+	displayName := state.DisplayName.ValueString()
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.Append(frameworkDiagnostics(deletionProtectionError("group", displayName))...)
+		return
+	}
+	if lc, guarded := lockoutGuard(r.client); guarded && displayName == LldapAdminGroupName {
+		resp.Diagnostics.AddError("Refusing to delete the admin group", lc.lockoutError(fmt.Sprintf("Deleting group %s", LldapAdminGroupName)).Error())
+		return
+	}
+	groupId, parseErr := strconv.Atoi(state.Id.ValueString())
+	if parseErr != nil {
+		resp.Diagnostics.AddError("Invalid group id", parseErr.Error())
+		return
+	}
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.DeleteGroup(groupId))...)
+}
+
+func (r *groupResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	groupId, resolveErr := resolveGroupImportId(r.client, req.ID)
+	if resolveErr != nil {
+		resp.Diagnostics.AddError("Invalid import id", resolveErr.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), strconv.Itoa(groupId))...)
+}
+
+// setComputed sets the values LLDAP assigns to the group.
+func (r *groupResource) setComputed(model *groupResourceModel, group *LldapGroup, diags *fwdiag.Diagnostics) {
+	attributes, attributesErr := r.client.GroupCustomAttributes(group)
+	diags.Append(frameworkDiagnostics(attributesErr)...)
+	model.Attributes = customAttributesValue(attributes)
+	model.CreationDate = types.StringValue(group.CreationDate)
+	model.Id = types.StringValue(strconv.Itoa(group.Id))
+	model.Users = stringSetValue(group.GetUserIds())
+	model.Uuid = types.StringValue(group.Uuid)
 }
 
-func resourceGroupDelete(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	lc := m.(*LldapClient)
-	groupId, getGroupIdErr := strconv.Atoi(d.Id())
-	if getGroupIdErr != nil {
-		return diag.FromErr(getGroupIdErr)
+const groupImportNamePrefix = "name:"
+
+// resolveGroupImportId resolves the group part of an import ID, which is either
+// the numeric group ID or the group display name prefixed with `name:`.
+func resolveGroupImportId(lc *LldapClient, ref string) (int, error) {
+	if displayName, found := strings.CutPrefix(ref, groupImportNamePrefix); found {
+		group, getGroupErr := lc.GetGroupByDisplayName(displayName)
+		if getGroupErr != nil {
+			return 0, fmt.Errorf("could not resolve group '%s': %s", displayName, getGroupErr[0].Summary)
+		}
+		return group.Id, nil
 	}
-	deleteErr := lc.DeleteGroup(groupId)
-	if deleteErr != nil {
-		return deleteErr
+	groupId, parseErr := strconv.Atoi(ref)
+	if parseErr != nil {
+		return 0, fmt.Errorf("not a valid group id, expected an integer or '%s' followed by the display name: %s", groupImportNamePrefix, ref)
 	}
-	return nil
+	return groupId, nil
 }
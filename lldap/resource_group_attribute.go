@@ -13,6 +13,7 @@ import (
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -20,6 +21,7 @@ func resourceGroupAttribute() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceGroupAttributeCreate,
 		ReadContext:   resourceGroupAttributeRead,
+		UpdateContext: resourceGroupAttributeUpdate,
 		Importer: &schema.ResourceImporter{
 			StateContext: func(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
 				_ = d.Set("id", d.Id())
@@ -27,12 +29,15 @@ func resourceGroupAttribute() *schema.Resource {
 			},
 		},
 		DeleteContext: resourceGroupAttributeDelete,
-		Description:   "Defines a new custom attribute schema for groups",
+		CustomizeDiff: customdiff.All(
+			deletionProtectionCustomizeDiff,
+			attributeMigrationCustomizeDiff("group", []string{"attribute_type", "is_list", "is_visible"}, (*LldapClient).GroupAttributeValues),
+		),
+		Description: "Defines a new custom attribute schema for groups",
 		Schema: map[string]*schema.Schema{
 			"attribute_type": {
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
 				Description: fmt.Sprintf("The attribute type (one of: %s)", strings.Join(VALID_ATTRIBUTE_TYPES[:], ", ")),
 				ValidateDiagFunc: func(v any, p cty.Path) diag.Diagnostics {
 					attributeType := v.(string)
@@ -43,6 +48,7 @@ func resourceGroupAttribute() *schema.Resource {
 					return nil
 				},
 			},
+			"deletion_protection": &deletionProtectionSchema,
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -51,14 +57,12 @@ func resourceGroupAttribute() *schema.Resource {
 			"is_list": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
 				Default:     false,
 				Description: "Does this represent a list?",
 			},
 			"is_visible": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
 				Default:     true,
 				Description: "Is this attribute visible in LDAP?",
 			},
@@ -72,6 +76,7 @@ func resourceGroupAttribute() *schema.Resource {
 				Computed:    true,
 				Description: "Is this attribute hardcoded (i.e. managed by LLDAP)?",
 			},
+			"migrate_values": &migrateValuesSchema,
 			"name": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -148,7 +153,26 @@ func resourceGroupAttributeRead(_ context.Context, d *schema.ResourceData, m any
 	return nil
 }
 
+// resourceGroupAttributeUpdate migrates the attribute if its settings changed with migrate_values set,
+// deletion_protection and migrate_values are only kept in state
+func resourceGroupAttributeUpdate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	if d.HasChanges("attribute_type", "is_list", "is_visible") {
+		lc := m.(*LldapClient)
+		target, getAttrErr := resourceGroupAttributeGetResourceData(d)
+		if getAttrErr != nil {
+			return diag.FromErr(getAttrErr)
+		}
+		if migrateErr := lc.MigrateGroupAttribute(*target); migrateErr != nil {
+			return migrateErr
+		}
+	}
+	return resourceGroupAttributeRead(ctx, d, m)
+}
+
 func resourceGroupAttributeDelete(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	if protectedErr := deletionProtectionCheck(d, "group attribute", d.Id()); protectedErr != nil {
+		return protectedErr
+	}
 	lc := m.(*LldapClient)
 	deleteErr := lc.DeleteGroupAttribute(d.Id())
 	if deleteErr != nil {
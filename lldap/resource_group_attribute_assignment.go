@@ -12,27 +12,31 @@ import (
 	"fmt"
 	"slices"
 	"strconv"
-	"strings"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-const resourceGroupAttributeAssignmentIdSeparator = ":"
-
 func resourceGroupAttributeAssignment() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceGroupAttributeAssignmentCreate,
 		ReadContext:   resourceGroupAttributeAssignmentRead,
 		UpdateContext: resourceGroupAttributeAssignmentUpdate,
 		DeleteContext: resourceGroupAttributeAssignmentDelete,
+		CustomizeDiff: resourceGroupAttributeAssignmentCustomizeDiff,
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceGroupAttributeAssignmentV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceGroupAttributeAssignmentStateUpgradeV0,
+			},
+		},
 		Importer: &schema.ResourceImporter{
 			StateContext: func(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
-				id := d.Id()
-				_, _, found := strings.Cut(id, resourceGroupAttributeAssignmentIdSeparator)
-				if !found {
-					return nil, fmt.Errorf("not a valid attribute assignment id: %s", id)
+				if _, parseErr := ParseCompositeId(d.Id(), 2); parseErr != nil {
+					return nil, fmt.Errorf("not a valid attribute assignment id: %s", parseErr)
 				}
 				_ = d.Set("id", d.Id())
 				return schema.ImportStatePassthroughContext(ctx, d, m)
@@ -43,7 +47,7 @@ func resourceGroupAttributeAssignment() *schema.Resource {
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
-				Description: "The assignment 'ID', constructed as group_id:attribute_name",
+				Description: "The assignment 'ID', constructed as group_id:attribute_name with ':' and '%' in the parts escaped as '%3A' and '%25'",
 			},
 			"attribute_id": {
 				Type:        schema.TypeString,
@@ -51,6 +55,11 @@ func resourceGroupAttributeAssignment() *schema.Resource {
 				ForceNew:    true,
 				Description: "The attribute name",
 			},
+			"attribute_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The attribute type, used to compare values independent of their representation",
+			},
 			"group_id": {
 				Type:        schema.TypeInt,
 				Required:    true,
@@ -60,46 +69,74 @@ func resourceGroupAttributeAssignment() *schema.Resource {
 			"value": {
 				Type:        schema.TypeSet,
 				Required:    true,
-				Description: "The value(s) for this attribute",
+				Description: "The value(s) for this attribute, compared according to the attribute type (e.g. equivalent DATE_TIME timestamps or padded INTEGER values do not cause a diff)",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
+				DiffSuppressFunc: attributeValueDiffSuppress,
 			},
 		},
 	}
 }
 
+// resourceGroupAttributeAssignmentCustomizeDiff validates the values against the attribute type at plan time.
+// Attributes that do not exist yet (e.g. created within the same apply) are validated on apply.
+func resourceGroupAttributeAssignmentCustomizeDiff(_ context.Context, d *schema.ResourceDiff, m any) error {
+	if !d.NewValueKnown("attribute_id") || !d.NewValueKnown("value") {
+		return nil
+	}
+	lc, ok := m.(*LldapClient)
+	if !ok {
+		return nil
+	}
+	attributeId := d.Get("attribute_id").(string)
+	attributeSchema, getSchemaErr := lc.GetGroupAttributeSchema(attributeId)
+	if getSchemaErr != nil || attributeSchema == nil {
+		return nil
+	}
+	_, canonicalErr := CanonicalAttributeValues(attributeSchema.AttributeType, attributeValueSetToList(d.Get("value")))
+	if canonicalErr != nil {
+		return fmt.Errorf("invalid value for group attribute '%s': %s", attributeId, canonicalErr)
+	}
+	return nil
+}
+
 func resourceGroupAttributeAssignmentCreate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
 	groupId := d.Get("group_id").(int)
 	attributeId := d.Get("attribute_id").(string)
-	valueRaw := d.Get("value").(*schema.Set)
-	valueRawList := valueRaw.List()
-	value := make([]string, len(valueRawList))
-	for i, vRaw := range valueRawList {
-		value[i] = vRaw.(string)
-	}
+	value := attributeValueSetToList(d.Get("value"))
 	something, _ := json.Marshal(value)
 	tflog.Error(ctx, fmt.Sprintf("Got something: %s", string(something)))
-	id := fmt.Sprintf("%d%s%s", groupId, resourceGroupAttributeAssignmentIdSeparator, attributeId)
+	id := FormatCompositeId(strconv.Itoa(groupId), attributeId)
 	tflog.Debug(ctx, fmt.Sprintf("Will create group attribute assignment with id: %s", id))
-	d.SetId(id)
 	lc := m.(*LldapClient)
-	addAttrErr := lc.AddAttributeToGroup(groupId, attributeId, value)
+	attributeType, getTypeErr := lc.GetGroupAttributeType(attributeId)
+	if getTypeErr != nil {
+		return getTypeErr
+	}
+	canonicalValue, canonicalErr := CanonicalAttributeValues(attributeType, value)
+	if canonicalErr != nil {
+		return diag.FromErr(canonicalErr)
+	}
+	d.SetId(id)
+	addAttrErr := lc.AddAttributeToGroup(groupId, attributeId, canonicalValue)
 	if addAttrErr != nil {
 		return addAttrErr
 	}
+	if setErr := d.Set("attribute_type", attributeType); setErr != nil {
+		return diag.FromErr(setErr)
+	}
 	tflog.Info(ctx, fmt.Sprintf("Created group attribute assignment with id: %s", id))
 	return nil
 }
 
 func resourceGroupAttributeAssignmentRead(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	id := d.Id()
-	groupIdString, attributeId, ok := strings.Cut(id, resourceGroupAttributeAssignmentIdSeparator)
-	if !ok {
-		return diag.Errorf("not a valid lldap_group_attribute_assignment id: %s", id)
+	parts, parseErr := ParseCompositeId(d.Id(), 2)
+	if parseErr != nil {
+		return diag.Errorf("not a valid lldap_group_attribute_assignment id: %s", parseErr)
 	}
-
-	groupId, err := strconv.Atoi(groupIdString)
+	attributeId := parts[1]
+	groupId, err := strconv.Atoi(parts[0])
 	if err != nil {
 		return diag.Errorf("group_id should be an integer: %v", err)
 	}
@@ -120,10 +157,20 @@ func resourceGroupAttributeAssignmentRead(_ context.Context, d *schema.ResourceD
 	if !slices.Contains(groupAttributes, attributeId) {
 		return diag.Errorf("Group is missing attribute!")
 	}
+	attributeType, getTypeErr := lc.GetGroupAttributeType(attributeId)
+	if getTypeErr != nil {
+		return getTypeErr
+	}
+	// Keep the representation from state if LLDAP returns an equivalent value
+	stateValue := attributeValueSetToList(d.Get("value"))
+	if AttributeValuesEquivalent(attributeType, stateValue, value) {
+		value = stateValue
+	}
 	for k, v := range map[string]any{
-		"group_id":     groupId,
-		"attribute_id": attributeId,
-		"value":        value,
+		"attribute_type": attributeType,
+		"group_id":       groupId,
+		"attribute_id":   attributeId,
+		"value":          value,
 	} {
 		if setErr := d.Set(k, v); setErr != nil {
 			return diag.FromErr(setErr)
@@ -135,23 +182,23 @@ func resourceGroupAttributeAssignmentRead(_ context.Context, d *schema.ResourceD
 func resourceGroupAttributeAssignmentUpdate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
 	groupId := d.Get("group_id").(int)
 	attributeId := d.Get("attribute_id").(string)
-	valueRaw := d.Get("value").(*schema.Set)
-	valueRawList := valueRaw.List()
-	value := make([]string, len(valueRawList))
-	for i, vRaw := range valueRawList {
-		value[i] = vRaw.(string)
-	}
+	value := attributeValueSetToList(d.Get("value"))
 	lc := m.(*LldapClient)
-
-	// First remove the existing attribute
-	removeAttrErr := lc.RemoveAttributeFromGroup(groupId, attributeId)
-	if removeAttrErr != nil {
-		return removeAttrErr
+	attributeType, getTypeErr := lc.GetGroupAttributeType(attributeId)
+	if getTypeErr != nil {
+		return getTypeErr
+	}
+	canonicalValue, canonicalErr := CanonicalAttributeValues(attributeType, value)
+	if canonicalErr != nil {
+		return diag.FromErr(canonicalErr)
 	}
 
-	// Then add it back with the new values
-	updateAttrErr := lc.AddAttributeToGroup(groupId, attributeId, value)
+	// Inserting an attribute replaces its existing value in a single mutation,
+	// so a failed update never leaves the attribute removed
+	updateAttrErr := lc.AddAttributeToGroup(groupId, attributeId, canonicalValue)
 	if updateAttrErr != nil {
+		// Keep the previous value in state instead of the planned one
+		d.Partial(true)
 		return updateAttrErr
 	}
 	tflog.Info(ctx, fmt.Sprintf("Updated group attribute assignment with id: %s", d.Id()))
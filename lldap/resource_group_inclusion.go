@@ -0,0 +1,291 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceGroupInclusion() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceGroupInclusionCreate,
+		ReadContext:   resourceGroupInclusionRead,
+		UpdateContext: resourceGroupInclusionUpdate,
+		DeleteContext: resourceGroupInclusionDelete,
+		CustomizeDiff: resourceGroupInclusionCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: func(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
+				groupId, resolveErr := resolveGroupImportId(m.(*LldapClient), d.Id())
+				if resolveErr != nil {
+					return nil, resolveErr
+				}
+				d.SetId(strconv.Itoa(groupId))
+				_ = d.Set("id", d.Id())
+				_ = d.Set("parent_group_id", groupId)
+				return schema.ImportStatePassthroughContext(ctx, d, m)
+			},
+		},
+		Description: "Emulates nested groups: all members of the child groups, including their own child groups, become members of the parent group",
+		Schema: map[string]*schema.Schema{
+			"child_group_ids": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Description: "Ids of the groups included by the parent group",
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+			},
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID representing this group inclusion",
+			},
+			"members": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "User ids this inclusion added to the parent group through the child groups, without users that were direct members of the parent group already",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"parent_group_id": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The unique id of the parent group",
+			},
+		},
+	}
+}
+
+func resourceGroupInclusionGetChildGroupIds(d interface{ Get(string) any }) []int {
+	childGroupIdsList := d.Get("child_group_ids").(*schema.Set).List()
+	childGroupIds := make([]int, len(childGroupIdsList))
+	for i, childGroupId := range childGroupIdsList {
+		childGroupIds[i] = childGroupId.(int)
+	}
+	slices.Sort(childGroupIds)
+	return childGroupIds
+}
+
+// resourceGroupInclusionGetMembers validates the planned inclusion and returns the flattened
+// members of the child groups and the current members of the parent group.
+func resourceGroupInclusionGetMembers(lc *LldapClient, parentGroupId int, childGroupIds []int) ([]string, []string, error) {
+	groups, getGroupsErr := lc.GetGroupsWithMembers()
+	if getGroupsErr != nil {
+		return nil, nil, fmt.Errorf("could not get groups: %v", getGroupsErr)
+	}
+	inclusions := GroupInclusionsFromGroups(groups)
+	if checkErr := CheckGroupInclusions(inclusions, parentGroupId, childGroupIds); checkErr != nil {
+		return nil, nil, checkErr
+	}
+	inclusions[parentGroupId] = childGroupIds
+	hasUserIds := make([]string, 0)
+	for _, group := range groups {
+		if group.Id == parentGroupId {
+			hasUserIds = group.GetUserIds()
+		}
+	}
+	return inclusions.InheritedUserIds(groups, parentGroupId), hasUserIds, nil
+}
+
+// groupInclusionMembers returns the inherited users the inclusion is responsible for: users it
+// added before, and users that are not yet members of the parent group. Users that were direct
+// members of the parent group already are left to whatever manages them.
+func groupInclusionMembers(wantsUserIds []string, hasUserIds []string, oldMembers []string) []string {
+	members := slices.DeleteFunc(slices.Clone(wantsUserIds), func(userId string) bool {
+		return slices.Contains(hasUserIds, userId) && !slices.Contains(oldMembers, userId)
+	})
+	slices.Sort(members)
+	return members
+}
+
+// resourceGroupInclusionCustomizeDiff detects cycles and shows membership changes at plan time.
+func resourceGroupInclusionCustomizeDiff(_ context.Context, d *schema.ResourceDiff, m any) error {
+	if !d.NewValueKnown("parent_group_id") || !d.NewValueKnown("child_group_ids") {
+		return d.SetNewComputed("members")
+	}
+	parentGroupId := d.Get("parent_group_id").(int)
+	childGroupIds := resourceGroupInclusionGetChildGroupIds(d)
+	if slices.Contains(childGroupIds, parentGroupId) {
+		return fmt.Errorf("group %d cannot include itself", parentGroupId)
+	}
+	lc, ok := m.(*LldapClient)
+	if !ok {
+		return nil
+	}
+	wantsUserIds, hasUserIds, getMembersErr := resourceGroupInclusionGetMembers(lc, parentGroupId, childGroupIds)
+	if getMembersErr != nil {
+		return getMembersErr
+	}
+	currentMembers := attributeValueSetToList(d.Get("members"))
+	slices.Sort(currentMembers)
+	members := groupInclusionMembers(wantsUserIds, hasUserIds, currentMembers)
+	if _, guarded := lockoutGuard(m); guarded && slices.ContainsFunc(currentMembers, lc.isProviderUser) && !slices.ContainsFunc(members, lc.isProviderUser) {
+		if lockoutErr := lc.checkGroupMembersRemoval(parentGroupId, []string{lc.Config.UserName}); lockoutErr != nil {
+			return lockoutErr
+		}
+	}
+	if d.Id() == "" || !slices.Equal(currentMembers, members) {
+		return d.SetNew("members", members)
+	}
+	return nil
+}
+
+// resourceGroupInclusionReconcile reports whether the inclusion was recorded, so a partially failed create is still recorded in state.
+func resourceGroupInclusionReconcile(ctx context.Context, d *schema.ResourceData, lc *LldapClient) (bool, diag.Diagnostics) {
+	parentGroupId := d.Get("parent_group_id").(int)
+	childGroupIds := resourceGroupInclusionGetChildGroupIds(d)
+	wantsUserIds, _, getMembersErr := resourceGroupInclusionGetMembers(lc, parentGroupId, childGroupIds)
+	if getMembersErr != nil {
+		return false, diag.FromErr(getMembersErr)
+	}
+	oldMembers, _ := d.GetChange("members")
+	if _, guarded := lockoutGuard(lc); guarded {
+		noLongerInherited := slices.DeleteFunc(attributeValueSetToList(oldMembers), func(userId string) bool {
+			return slices.Contains(wantsUserIds, userId)
+		})
+		if lockoutErr := lc.checkGroupMembersRemoval(parentGroupId, noLongerInherited); lockoutErr != nil {
+			return false, diag.FromErr(lockoutErr)
+		}
+	}
+	setInclusionsErr := lc.SetGroupInclusions(parentGroupId, childGroupIds)
+	if setInclusionsErr != nil {
+		return false, setInclusionsErr
+	}
+	parentGroup, getGroupErr := lc.GetGroup(parentGroupId)
+	if getGroupErr != nil {
+		return true, getGroupErr
+	}
+	hasUserIds := parentGroup.GetUserIds()
+	addUserIds := slices.DeleteFunc(slices.Clone(wantsUserIds), func(userId string) bool {
+		return slices.Contains(hasUserIds, userId)
+	})
+	// Only users that were inherited before are removed, direct members of the parent group are kept
+	oldMemberIds := attributeValueSetToList(oldMembers)
+	members := groupInclusionMembers(wantsUserIds, hasUserIds, oldMemberIds)
+	removeUserIds := slices.DeleteFunc(oldMemberIds, func(userId string) bool {
+		return slices.Contains(wantsUserIds, userId) || !slices.Contains(hasUserIds, userId)
+	})
+	tflog.Info(ctx, fmt.Sprintf("Adding inherited users %v to and removing formerly inherited users %v from group %d", addUserIds, removeUserIds, parentGroupId))
+	addedUserIds, addErr := lc.AddUsersToGroup(parentGroupId, addUserIds)
+	removedUserIds, removeErr := lc.RemoveUsersFromGroup(parentGroupId, removeUserIds)
+	diags := append(addErr, removeErr...)
+	if diags.HasError() {
+		// Inherited users that were not added yet are missing, formerly inherited users that were not removed are kept
+		members = slices.DeleteFunc(members, func(userId string) bool {
+			return slices.Contains(addUserIds, userId) && !slices.Contains(addedUserIds, userId)
+		})
+		for _, userId := range removeUserIds {
+			if !slices.Contains(removedUserIds, userId) {
+				members = append(members, userId)
+			}
+		}
+		slices.Sort(members)
+	}
+	if setErr := d.Set("members", members); setErr != nil {
+		return true, append(diags, diag.FromErr(setErr)...)
+	}
+	return true, diags
+}
+
+func resourceGroupInclusionCreate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	lc := m.(*LldapClient)
+	recorded, reconcileErr := resourceGroupInclusionReconcile(ctx, d, lc)
+	if reconcileErr.HasError() && !recorded {
+		return reconcileErr
+	}
+	d.SetId(strconv.Itoa(d.Get("parent_group_id").(int)))
+	return reconcileErr
+}
+
+func resourceGroupInclusionRead(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	lc := m.(*LldapClient)
+	parentGroupId, getGroupIdErr := strconv.Atoi(d.Id())
+	if getGroupIdErr != nil {
+		return diag.FromErr(getGroupIdErr)
+	}
+	parentGroup, getGroupErr := lc.GetGroup(parentGroupId)
+	if getGroupErr != nil {
+		// If the group was not found, mark the resource as deleted so Terraform will recreate it
+		if isEntityNotFoundError(getGroupErr) {
+			d.SetId("")
+			return nil
+		}
+		return getGroupErr
+	}
+	childGroupIds := GroupInclusionsFromGroups([]LldapGroup{*parentGroup})[parentGroupId]
+	if len(childGroupIds) == 0 {
+		// If the inclusion record no longer exists, mark the resource as deleted
+		d.SetId("")
+		return nil
+	}
+	// Inherited users that were removed from the parent group show up as changes again
+	hasUserIds := parentGroup.GetUserIds()
+	members := make([]string, 0)
+	for _, member := range attributeValueSetToList(d.Get("members")) {
+		if slices.Contains(hasUserIds, member) {
+			members = append(members, member)
+		}
+	}
+	for k, v := range map[string]any{
+		"child_group_ids": childGroupIds,
+		"members":         members,
+		"parent_group_id": parentGroupId,
+	} {
+		if setErr := d.Set(k, v); setErr != nil {
+			return diag.FromErr(setErr)
+		}
+	}
+	return nil
+}
+
+func resourceGroupInclusionUpdate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	lc := m.(*LldapClient)
+	_, reconcileErr := resourceGroupInclusionReconcile(ctx, d, lc)
+	return reconcileErr
+}
+
+func resourceGroupInclusionDelete(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	parentGroupId := d.Get("parent_group_id").(int)
+	lc := m.(*LldapClient)
+	parentGroup, getGroupErr := lc.GetGroup(parentGroupId)
+	if getGroupErr != nil {
+		if isEntityNotFoundError(getGroupErr) {
+			return nil
+		}
+		return getGroupErr
+	}
+	hasUserIds := parentGroup.GetUserIds()
+	removeUserIds := slices.DeleteFunc(attributeValueSetToList(d.Get("members")), func(userId string) bool {
+		return !slices.Contains(hasUserIds, userId)
+	})
+	if _, guarded := lockoutGuard(m); guarded {
+		if lockoutErr := lc.checkGroupMembersRemoval(parentGroupId, removeUserIds); lockoutErr != nil {
+			return diag.FromErr(lockoutErr)
+		}
+	}
+	removedUserIds, removeErr := lc.RemoveUsersFromGroup(parentGroupId, removeUserIds)
+	if removeErr.HasError() {
+		remainingUserIds := slices.DeleteFunc(removeUserIds, func(userId string) bool {
+			return slices.Contains(removedUserIds, userId)
+		})
+		if setErr := d.Set("members", remainingUserIds); setErr != nil {
+			return append(removeErr, diag.FromErr(setErr)...)
+		}
+		return removeErr
+	}
+	return append(removeErr, lc.SetGroupInclusions(parentGroupId, nil)...)
+}
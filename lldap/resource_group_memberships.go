@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"slices"
 	"strconv"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -22,14 +23,46 @@ func resourceGroupMemberships() *schema.Resource {
 		ReadContext:   resourceGroupMembershipsRead,
 		UpdateContext: resourceGroupMembershipsUpdate,
 		DeleteContext: resourceGroupMembershipsDelete,
+		CustomizeDiff: resourceGroupMembershipsCustomizeDiff,
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceGroupMembershipsV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceGroupMembershipsStateUpgradeV0,
+			},
+		},
 		Importer: &schema.ResourceImporter{
 			StateContext: func(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
+				groupId, resolveErr := resolveGroupImportId(m.(*LldapClient), d.Id())
+				if resolveErr != nil {
+					return nil, resolveErr
+				}
+				d.SetId(strconv.Itoa(groupId))
 				_ = d.Set("id", d.Id())
+				_ = d.Set("group_id", groupId)
 				return schema.ImportStatePassthroughContext(ctx, d, m)
 			},
 		},
 		Description: "Exclusively manages all LLDAP memberhips for this specific group",
 		Schema: map[string]*schema.Schema{
+			"active_user_ids": {
+				Type:        schema.TypeSet,
+				Description: "User ids that are members of this group, i.e. all user ids that have not expired",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"expires_at": {
+				Type:             schema.TypeMap,
+				Description:      "RFC 3339 timestamps after which users are removed from this group, keyed by user id, e.g. `{ contractor = \"2030-12-31T23:59:59Z\" }`",
+				Optional:         true,
+				ValidateDiagFunc: validateMembershipExpiryMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"user_ids": {
 				Type:        schema.TypeSet,
 				Description: "User ids that must be members of this group",
@@ -44,31 +77,31 @@ func resourceGroupMemberships() *schema.Resource {
 				Description: "ID representing this specific group memberships",
 			},
 			"group_id": {
-				Type:        schema.TypeString,
+				Type:        schema.TypeInt,
 				Required:    true,
 				ForceNew:    true,
 				Description: "The unique group id",
-				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
-					v := val.(string)
-					if _, err := strconv.Atoi(v); err != nil {
-						errs = append(errs, fmt.Errorf("%q must be a string representing an integer, got: %s", key, v))
-					}
-					return
-				},
 			},
 		},
 	}
 }
 
 func resourceGroupMembershipsSetResourceData(d *schema.ResourceData, group *LldapGroup) diag.Diagnostics {
-	userIds := make([]string, len(group.Users))
-	for i, user := range group.Users {
-		userIds[i] = user.Id
+	activeUserIds := group.GetUserIds()
+	slices.Sort(activeUserIds)
+	// Expired users are expected to be gone, so they remain part of user_ids
+	userIds := slices.Clone(activeUserIds)
+	expiry := resourceGroupMembershipsGetExpiry(d)
+	for _, userId := range attributeValueSetToList(d.Get("user_ids")) {
+		if IsMembershipExpired(expiry[userId], time.Now()) && !slices.Contains(userIds, userId) {
+			userIds = append(userIds, userId)
+		}
 	}
 	slices.Sort(userIds)
 	for k, v := range map[string]any{
-		"group_id": strconv.Itoa(group.Id),
-		"user_ids": userIds,
+		"active_user_ids": activeUserIds,
+		"group_id":        group.Id,
+		"user_ids":        userIds,
 	} {
 		if v != nil {
 			if setErr := d.Set(k, v); setErr != nil {
@@ -88,84 +121,207 @@ func resourceGroupMembershipsGetUserIds(d *schema.ResourceData) ([]string, diag.
 	return userIds, nil
 }
 
+func resourceGroupMembershipsGetExpiry(d interface{ Get(string) any }) map[string]string {
+	expiry := map[string]string{}
+	for userId, expiresAt := range d.Get("expires_at").(map[string]any) {
+		expiry[userId] = expiresAt.(string)
+	}
+	return expiry
+}
+
+// resourceGroupMembershipsGetActiveUserIds returns the sorted user ids that have not expired.
+func resourceGroupMembershipsGetActiveUserIds(userIds []string, expiry map[string]string) []string {
+	now := time.Now()
+	activeUserIds := make([]string, 0, len(userIds))
+	for _, userId := range userIds {
+		if !IsMembershipExpired(expiry[userId], now) {
+			activeUserIds = append(activeUserIds, userId)
+		}
+	}
+	slices.Sort(activeUserIds)
+	return activeUserIds
+}
+
+// resourceGroupMembershipsCustomizeDiff shows expired users being removed in the plan,
+// and refuses memberships that are also managed by another membership resource.
+func resourceGroupMembershipsCustomizeDiff(_ context.Context, d *schema.ResourceDiff, m any) error {
+	if lc, ok := m.(*LldapClient); ok && d.NewValueKnown("group_id") && d.NewValueKnown("user_ids") {
+		if claimErr := lc.membershipClaims.claimGroupMembers(d.Get("group_id").(int), attributeValueSetToList(d.Get("user_ids"))); claimErr != nil {
+			return claimErr
+		}
+	}
+	if !d.NewValueKnown("user_ids") || !d.NewValueKnown("expires_at") {
+		return d.SetNewComputed("active_user_ids")
+	}
+	userIds := attributeValueSetToList(d.Get("user_ids"))
+	expiry := resourceGroupMembershipsGetExpiry(d)
+	for userId := range expiry {
+		if !slices.Contains(userIds, userId) {
+			return fmt.Errorf("expires_at contains user %s, which is not part of user_ids", userId)
+		}
+	}
+	activeUserIds := resourceGroupMembershipsGetActiveUserIds(userIds, expiry)
+	if lc, guarded := lockoutGuard(m); guarded && d.Id() != "" && (d.HasChange("group_id") || !slices.ContainsFunc(activeUserIds, lc.isProviderUser)) {
+		groupId, _ := d.GetChange("group_id")
+		if lockoutErr := lc.checkGroupMembersRemoval(groupId.(int), []string{lc.Config.UserName}); lockoutErr != nil {
+			return lockoutErr
+		}
+	}
+	currentActiveUserIds := attributeValueSetToList(d.Get("active_user_ids"))
+	slices.Sort(currentActiveUserIds)
+	if d.Id() == "" || !slices.Equal(currentActiveUserIds, activeUserIds) {
+		return d.SetNew("active_user_ids", activeUserIds)
+	}
+	return nil
+}
+
 func resourceGroupMembershipsCreate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	groupId := d.Get("group_id").(string)
+	groupId := d.Get("group_id").(int)
 	userIds, getUserIdsErr := resourceGroupMembershipsGetUserIds(d)
 	if getUserIdsErr != nil {
 		return getUserIdsErr
 	}
+	activeUserIds := resourceGroupMembershipsGetActiveUserIds(userIds, resourceGroupMembershipsGetExpiry(d))
 	lc := m.(*LldapClient)
-	groupIdInt, _ := strconv.Atoi(groupId)
-	for _, userId := range userIds {
-		addErr := lc.AddUserToGroup(groupIdInt, userId)
-		if addErr != nil {
-			return addErr
+	addedUserIds, addErr := lc.AddUsersToGroup(groupId, activeUserIds)
+	if addErr.HasError() && len(addedUserIds) == 0 {
+		return addErr
+	}
+	d.SetId(strconv.Itoa(groupId))
+	if addErr.HasError() {
+		return append(addErr, resourceGroupMembershipsSetApplied(d, addedUserIds)...)
+	}
+	if setErr := d.Set("active_user_ids", activeUserIds); setErr != nil {
+		return diag.FromErr(setErr)
+	}
+	return nil
+}
+
+// resourceGroupMembershipsSetApplied records the users that actually are members after a partially failed apply.
+func resourceGroupMembershipsSetApplied(d *schema.ResourceData, memberUserIds []string) diag.Diagnostics {
+	slices.Sort(memberUserIds)
+	for _, k := range []string{"active_user_ids", "user_ids"} {
+		if setErr := d.Set(k, memberUserIds); setErr != nil {
+			return diag.FromErr(setErr)
 		}
 	}
-	d.SetId(groupId)
 	return nil
 }
 
 func resourceGroupMembershipsRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	groupId := d.Get("group_id").(string)
-	groupIdInt, _ := strconv.Atoi(groupId)
+	groupIdInt := d.Get("group_id").(int)
 	lc := m.(*LldapClient)
 	group, getGroupErr := lc.GetGroup(groupIdInt)
 	if getGroupErr != nil {
 		return getGroupErr
 	}
+	// Unmanaged users that are not part of the configuration are ignored
+	stateUserIds := attributeValueSetToList(d.Get("user_ids"))
+	unknownUserIds := slices.DeleteFunc(group.GetUserIds(), func(userId string) bool {
+		return slices.Contains(stateUserIds, userId)
+	})
+	unmanagedUserIds, unmanagedErr := lc.unmanagedGroupMembers(group.Id, unknownUserIds)
+	if unmanagedErr != nil {
+		return unmanagedErr
+	}
+	var diags diag.Diagnostics
+	for _, userId := range unmanagedUserIds {
+		diags = append(diags, unmanagedMembershipWarning(group.Id, userId))
+	}
+	group.Users = slices.DeleteFunc(group.Users, func(user LldapUser) bool {
+		return slices.Contains(unmanagedUserIds, user.Id)
+	})
 	setRdErr := resourceGroupMembershipsSetResourceData(d, group)
 	if setRdErr != nil {
 		return setRdErr
 	}
-	return nil
+	return diags
 }
 
 func resourceGroupMembershipsUpdate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	groupId := d.Get("group_id").(string)
-	groupIdInt, _ := strconv.Atoi(groupId)
-	groupWantsUserIds, getGroupIdsErr := resourceGroupMembershipsGetUserIds(d)
+	groupIdInt := d.Get("group_id").(int)
+	userIds, getGroupIdsErr := resourceGroupMembershipsGetUserIds(d)
 	if getGroupIdsErr != nil {
 		return getGroupIdsErr
 	}
+	groupWantsUserIds := resourceGroupMembershipsGetActiveUserIds(userIds, resourceGroupMembershipsGetExpiry(d))
 	lc := m.(*LldapClient)
 	group, getGroupErr := lc.GetGroup(groupIdInt)
 	if getGroupErr != nil {
 		return getGroupErr
 	}
 	groupHasUserIds := group.GetUserIds()
-	for _, wantsUserId := range groupWantsUserIds {
-		if !slices.Contains(groupHasUserIds, wantsUserId) {
-			addErr := lc.AddUserToGroup(group.Id, wantsUserId)
-			if addErr != nil {
-				return addErr
-			}
+	if _, guarded := lockoutGuard(m); guarded && !slices.ContainsFunc(groupWantsUserIds, lc.isProviderUser) {
+		if lockoutErr := lc.checkGroupMembersRemoval(group.Id, groupHasUserIds); lockoutErr != nil {
+			return diag.FromErr(lockoutErr)
 		}
 	}
-	for _, hasUserId := range groupHasUserIds {
-		if !slices.Contains(groupWantsUserIds, hasUserId) {
-			removeErr := lc.RemoveUserFromGroup(group.Id, hasUserId)
-			if removeErr != nil {
-				return removeErr
-			}
-		}
+	addUserIds := slices.DeleteFunc(slices.Clone(groupWantsUserIds), func(userId string) bool {
+		return slices.Contains(groupHasUserIds, userId)
+	})
+	removeUserIds := slices.DeleteFunc(slices.Clone(groupHasUserIds), func(userId string) bool {
+		return slices.Contains(groupWantsUserIds, userId)
+	})
+	// Memberships that were ever configured are Terraform's own, only the others may be unmanaged
+	priorUserIds, _ := d.GetChange("user_ids")
+	configuredUserIds := append(attributeValueSetToList(priorUserIds), userIds...)
+	unknownUserIds := slices.DeleteFunc(slices.Clone(removeUserIds), func(userId string) bool {
+		return slices.Contains(configuredUserIds, userId)
+	})
+	unmanagedUserIds, unmanagedErr := lc.unmanagedGroupMembers(group.Id, unknownUserIds)
+	if unmanagedErr != nil {
+		return unmanagedErr
 	}
-	return nil
+	var diags diag.Diagnostics
+	for _, userId := range unmanagedUserIds {
+		diags = append(diags, unmanagedMembershipWarning(group.Id, userId))
+	}
+	removeUserIds = slices.DeleteFunc(removeUserIds, func(userId string) bool {
+		return slices.Contains(unmanagedUserIds, userId)
+	})
+	addedUserIds, addErr := lc.AddUsersToGroup(group.Id, addUserIds)
+	removedUserIds, removeErr := lc.RemoveUsersFromGroup(group.Id, removeUserIds)
+	diags = append(append(diags, addErr...), removeErr...)
+	if diags.HasError() {
+		memberUserIds := slices.DeleteFunc(slices.Clone(groupHasUserIds), func(userId string) bool {
+			return slices.Contains(removedUserIds, userId) || slices.Contains(unmanagedUserIds, userId)
+		})
+		return append(diags, resourceGroupMembershipsSetApplied(d, append(memberUserIds, addedUserIds...))...)
+	}
+	if setErr := d.Set("active_user_ids", groupWantsUserIds); setErr != nil {
+		return diag.FromErr(setErr)
+	}
+	return diags
 }
 
 func resourceGroupMembershipsDelete(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	groupId := d.Get("group_id").(string)
-	groupIdInt, _ := strconv.Atoi(groupId)
+	groupIdInt := d.Get("group_id").(int)
 	userIds, getUserIdsErr := resourceGroupMembershipsGetUserIds(d)
 	if getUserIdsErr != nil {
 		return getUserIdsErr
 	}
 	lc := m.(*LldapClient)
-	for _, userId := range userIds {
-		removeErr := lc.RemoveUserFromGroup(groupIdInt, userId)
-		if removeErr != nil {
-			return removeErr
+	if _, guarded := lockoutGuard(m); guarded {
+		if lockoutErr := lc.checkGroupMembersRemoval(groupIdInt, userIds); lockoutErr != nil {
+			return diag.FromErr(lockoutErr)
 		}
 	}
-	return nil
+	group, getGroupErr := lc.GetGroup(groupIdInt)
+	if getGroupErr != nil {
+		return getGroupErr
+	}
+	groupHasUserIds := group.GetUserIds()
+	// Expired users have already been removed. All others are configured, so they are removed
+	// even with reconcile_managed_only
+	removeUserIds := slices.DeleteFunc(slices.Clone(userIds), func(userId string) bool {
+		return !slices.Contains(groupHasUserIds, userId)
+	})
+	removedUserIds, diags := lc.RemoveUsersFromGroup(groupIdInt, removeUserIds)
+	if diags.HasError() {
+		remainingUserIds := slices.DeleteFunc(removeUserIds, func(userId string) bool {
+			return slices.Contains(removedUserIds, userId)
+		})
+		return append(diags, resourceGroupMembershipsSetApplied(d, remainingUserIds)...)
+	}
+	return diags
 }
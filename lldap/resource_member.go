@@ -12,31 +12,73 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-const ResourceMemberIdSeparator = ":"
-
 func resourceMember() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceMemberCreate,
 		ReadContext:   resourceMemberRead,
+		UpdateContext: resourceMemberUpdate,
 		DeleteContext: resourceMemberDelete,
+		CustomizeDiff: resourceMemberCustomizeDiff,
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceMemberV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceMemberStateUpgradeV0,
+			},
+		},
 		Importer: &schema.ResourceImporter{
 			StateContext: func(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
 				id := d.Id()
-				_, _, found := strings.Cut(id, ResourceMemberIdSeparator)
-				if !found {
-					return nil, fmt.Errorf("not a valid member id: %s", id)
+				var groupRef, userId string
+				if strings.HasPrefix(id, groupImportNamePrefix) {
+					// Display names may contain the separator, so split at the last one instead
+					separatorIndex := strings.LastIndex(id, compositeIdSeparator)
+					if separatorIndex <= len(groupImportNamePrefix) {
+						return nil, fmt.Errorf("not a valid member id: %s", id)
+					}
+					userIdParts, parseErr := ParseCompositeId(id[separatorIndex+len(compositeIdSeparator):], 1)
+					if parseErr != nil {
+						return nil, fmt.Errorf("not a valid member id: %s", parseErr)
+					}
+					groupRef, userId = id[:separatorIndex], userIdParts[0]
+				} else {
+					parts, parseErr := ParseCompositeId(id, 2)
+					if parseErr != nil {
+						return nil, fmt.Errorf("not a valid member id: %s", parseErr)
+					}
+					groupRef, userId = parts[0], parts[1]
 				}
+				groupId, resolveErr := resolveGroupImportId(m.(*LldapClient), groupRef)
+				if resolveErr != nil {
+					return nil, resolveErr
+				}
+				d.SetId(resourceMemberGetId(groupId, userId))
 				_ = d.Set("id", d.Id())
+				_ = d.Set("group_id", groupId)
+				_ = d.Set("user_id", userId)
 				return schema.ImportStatePassthroughContext(ctx, d, m)
 			},
 		},
 		Description: "Manages a LLDAP memberhip, i.e. a group-user relationship",
 		Schema: map[string]*schema.Schema{
+			"expired": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the membership has expired, expired memberships are removed from the group",
+			},
+			"expires_at": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "RFC 3339 timestamp after which the membership is removed, e.g. `2030-12-31T23:59:59Z`",
+				ValidateFunc: validateMembershipExpiry,
+			},
 			"group_display_name": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -51,7 +93,7 @@ func resourceMember() *schema.Resource {
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
-				Description: "The member 'ID', constructed as group_id:user_id",
+				Description: "The member 'ID', constructed as group_id:user_id with ':' and '%' in the user id escaped as '%3A' and '%25'",
 			},
 			"user_id": {
 				Type:        schema.TypeString,
@@ -64,38 +106,72 @@ func resourceMember() *schema.Resource {
 }
 
 func resourceMemberGetId(groupId int, userId string) string {
-	id := fmt.Sprintf("%d%s%s", groupId, ResourceMemberIdSeparator, userId)
-	return id
+	return FormatCompositeId(strconv.Itoa(groupId), userId)
+}
+
+// resourceMemberCustomizeDiff shows the membership being removed in the plan once it has expired,
+// and refuses memberships that are also managed by an authoritative membership resource.
+func resourceMemberCustomizeDiff(_ context.Context, d *schema.ResourceDiff, m any) error {
+	if lc, ok := m.(*LldapClient); ok && d.NewValueKnown("group_id") && d.NewValueKnown("user_id") {
+		if claimErr := lc.membershipClaims.claimMember(d.Get("group_id").(int), d.Get("user_id").(string)); claimErr != nil {
+			return claimErr
+		}
+	}
+	if !d.NewValueKnown("expires_at") {
+		return d.SetNewComputed("expired")
+	}
+	expired := IsMembershipExpired(d.Get("expires_at").(string), time.Now())
+	if d.Id() != "" && (expired && !d.Get("expired").(bool) || d.HasChange("group_id") || d.HasChange("user_id")) {
+		// The current membership is about to be removed
+		if lc, guarded := lockoutGuard(m); guarded {
+			oldGroupId, _ := d.GetChange("group_id")
+			oldUserId, _ := d.GetChange("user_id")
+			if lockoutErr := lc.checkGroupMembersRemoval(oldGroupId.(int), []string{oldUserId.(string)}); lockoutErr != nil {
+				return lockoutErr
+			}
+		}
+	}
+	if d.Id() == "" || d.Get("expired").(bool) != expired {
+		return d.SetNew("expired", expired)
+	}
+	return nil
 }
 
 func resourceMemberCreate(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
 	groupId := d.Get("group_id").(int)
 	userId := d.Get("user_id").(string)
 	id := resourceMemberGetId(groupId, userId)
+	expired := IsMembershipExpired(d.Get("expires_at").(string), time.Now())
 	lc := m.(*LldapClient)
-	createErr := lc.AddUserToGroup(groupId, userId)
-	if createErr != nil {
-		return createErr
+	if !expired {
+		createErr := lc.AddUserToGroup(groupId, userId)
+		if createErr != nil {
+			return createErr
+		}
 	}
 	group, getGroupErr := lc.GetGroup(groupId)
 	if getGroupErr != nil {
 		return getGroupErr
 	}
 	d.SetId(id)
-	if setErr := d.Set("group_display_name", group.DisplayName); setErr != nil {
-		return diag.FromErr(setErr)
+	for k, v := range map[string]any{
+		"expired":            expired,
+		"group_display_name": group.DisplayName,
+	} {
+		if setErr := d.Set(k, v); setErr != nil {
+			return diag.FromErr(setErr)
+		}
 	}
 	return nil
 }
 
 func resourceMemberRead(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	id := d.Id()
-	groupIdString, userId, ok := strings.Cut(id, ResourceMemberIdSeparator)
-	if !ok {
-		return diag.Errorf("not a valid lldap_member id: %s", id)
+	parts, parseErr := ParseCompositeId(d.Id(), 2)
+	if parseErr != nil {
+		return diag.Errorf("not a valid lldap_member id: %s", parseErr)
 	}
-
-	groupId, err := strconv.Atoi(groupIdString)
+	userId := parts[1]
+	groupId, err := strconv.Atoi(parts[0])
 	if err != nil {
 		return diag.Errorf("group_id should be an integer: %v", err)
 	}
@@ -115,7 +191,52 @@ func resourceMemberRead(_ context.Context, d *schema.ResourceData, m any) diag.D
 		groupMembers = append(groupMembers, user.Id)
 	}
 	if !slices.Contains(groupMembers, userId) {
+		// An expired membership is expected to be gone, e.g. after `lldap-cli member prune-expired`
+		if IsMembershipExpired(d.Get("expires_at").(string), time.Now()) {
+			if setErr := d.Set("expired", true); setErr != nil {
+				return diag.FromErr(setErr)
+			}
+			return nil
+		}
 		d.SetId("")
+		return nil
+	}
+	// A membership that is still present has not been removed yet, even if it should have been
+	if setErr := d.Set("expired", false); setErr != nil {
+		return diag.FromErr(setErr)
+	}
+	return nil
+}
+
+func resourceMemberUpdate(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	groupId := d.Get("group_id").(int)
+	userId := d.Get("user_id").(string)
+	expired := IsMembershipExpired(d.Get("expires_at").(string), time.Now())
+	lc := m.(*LldapClient)
+	group, getGroupErr := lc.GetGroup(groupId)
+	if getGroupErr != nil {
+		return getGroupErr
+	}
+	isMember := slices.Contains(group.GetUserIds(), userId)
+	if expired && isMember {
+		if _, guarded := lockoutGuard(m); guarded {
+			if lockoutErr := lc.checkGroupMembersRemoval(groupId, []string{userId}); lockoutErr != nil {
+				return diag.FromErr(lockoutErr)
+			}
+		}
+		removeErr := lc.RemoveUserFromGroup(groupId, userId)
+		if removeErr != nil {
+			return removeErr
+		}
+	}
+	if !expired && !isMember {
+		addErr := lc.AddUserToGroup(groupId, userId)
+		if addErr != nil {
+			return addErr
+		}
+	}
+	if setErr := d.Set("expired", expired); setErr != nil {
+		return diag.FromErr(setErr)
 	}
 	return nil
 }
@@ -124,6 +245,21 @@ func resourceMemberDelete(_ context.Context, d *schema.ResourceData, m any) diag
 	groupId := d.Get("group_id").(int)
 	userId := d.Get("user_id").(string)
 	lc := m.(*LldapClient)
+	if _, guarded := lockoutGuard(m); guarded {
+		if lockoutErr := lc.checkGroupMembersRemoval(groupId, []string{userId}); lockoutErr != nil {
+			return diag.FromErr(lockoutErr)
+		}
+	}
+	if d.Get("expired").(bool) {
+		// Expired memberships have already been removed
+		group, getGroupErr := lc.GetGroup(groupId)
+		if getGroupErr != nil {
+			return getGroupErr
+		}
+		if !slices.Contains(group.GetUserIds(), userId) {
+			return nil
+		}
+	}
 	removeErr := lc.RemoveUserFromGroup(groupId, userId)
 	if removeErr != nil {
 		return removeErr
@@ -0,0 +1,177 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// objectClassResource implements lldap_user_object_class and
+// lldap_group_object_class, which only differ in the client calls.
+type objectClassResource struct {
+	client            *LldapClient
+	typeNameSuffix    string
+	description       string
+	nameDescription   string
+	getObjectClasses  func(lc *LldapClient) ([]string, diag.Diagnostics)
+	addObjectClass    func(lc *LldapClient, name string) diag.Diagnostics
+	deleteObjectClass func(lc *LldapClient, name string) diag.Diagnostics
+}
+
+type objectClassResourceModel struct {
+	Id   types.String `tfsdk:"id"`
+	Name types.String `tfsdk:"name"`
+}
+
+var (
+	_ resource.ResourceWithConfigure   = &objectClassResource{}
+	_ resource.ResourceWithImportState = &objectClassResource{}
+)
+
+func NewUserObjectClassResource() resource.Resource {
+	return &objectClassResource{
+		typeNameSuffix:    "_user_object_class",
+		description:       "Adds an extra LDAP object class to all users",
+		nameDescription:   "The object class name, e.g. `posixAccount` or `mailRecipient`",
+		getObjectClasses:  (*LldapClient).GetUserObjectClasses,
+		addObjectClass:    (*LldapClient).AddUserObjectClass,
+		deleteObjectClass: (*LldapClient).DeleteUserObjectClass,
+	}
+}
+
+func NewGroupObjectClassResource() resource.Resource {
+	return &objectClassResource{
+		typeNameSuffix:    "_group_object_class",
+		description:       "Adds an extra LDAP object class to all groups",
+		nameDescription:   "The object class name, e.g. `posixGroup`",
+		getObjectClasses:  (*LldapClient).GetGroupObjectClasses,
+		addObjectClass:    (*LldapClient).AddGroupObjectClass,
+		deleteObjectClass: (*LldapClient).DeleteGroupObjectClass,
+	}
+}
+
+func (r *objectClassResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + r.typeNameSuffix
+}
+
+func (r *objectClassResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: r.description,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The object class name",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: r.nameDescription,
+				PlanModifiers: []planmodifier.String{
+					// LLDAP compares object classes case-insensitively, so a
+					// change of case is applied in place
+					stringplanmodifier.RequiresReplaceIf(
+						func(_ context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+							resp.RequiresReplace = !strings.EqualFold(req.StateValue.ValueString(), req.PlanValue.ValueString())
+						},
+						"Changing the object class name, other than its case, requires replacement",
+						"Changing the object class name, other than its case, requires replacement",
+					),
+				},
+			},
+		},
+	}
+}
+
+func (r *objectClassResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	client, clientDiags := frameworkClient(req.ProviderData)
+	resp.Diagnostics.Append(clientDiags...)
+	r.client = client
+}
+
+func (r *objectClassResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly(fmt.Sprintf("Creating lldap%s", r.typeNameSuffix)))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var plan objectClassResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	addErr := r.addObjectClass(r.client, plan.Name.ValueString())
+	resp.Diagnostics.Append(frameworkDiagnostics(addErr)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.Id = plan.Name
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *objectClassResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state objectClassResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	objectClasses, getErr := r.getObjectClasses(r.client)
+	resp.Diagnostics.Append(frameworkDiagnostics(getErr)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, objectClass := range objectClasses {
+		if strings.EqualFold(objectClass, state.Id.ValueString()) {
+			return
+		}
+	}
+	// If the object class no longer exists, mark the resource as deleted
+	resp.State.RemoveResource(ctx)
+}
+
+// Update only happens when the case of the name changes, which LLDAP ignores.
+func (r *objectClassResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly(fmt.Sprintf("Updating lldap%s", r.typeNameSuffix)))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var plan objectClassResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *objectClassResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly(fmt.Sprintf("Deleting lldap%s", r.typeNameSuffix)))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var state objectClassResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	deleteErr := r.deleteObjectClass(r.client, state.Id.ValueString())
+	resp.Diagnostics.Append(frameworkDiagnostics(deleteErr)...)
+}
+
+func (r *objectClassResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), req.ID)...)
+}
@@ -0,0 +1,160 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Schema version 0 built composite IDs without escaping and stored group ids
+// of lldap_group_memberships and lldap_user_memberships as strings. The version 0
+// schemas are those of the last release, attributes added since then are not part
+// of them and start out empty after the upgrade.
+
+// stateInt reads an integer from raw state, where it may be a JSON number or a string.
+func stateInt(v any) (int, error) {
+	switch value := v.(type) {
+	case float64:
+		return int(value), nil
+	case int:
+		return value, nil
+	case json.Number:
+		i, err := value.Int64()
+		return int(i), err
+	case string:
+		return strconv.Atoi(value)
+	}
+	return 0, fmt.Errorf("not an integer: %v", v)
+}
+
+func resourceMemberV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"group_display_name": {Type: schema.TypeString, Computed: true},
+			"group_id":           {Type: schema.TypeInt, Required: true},
+			"id":                 {Type: schema.TypeString, Computed: true},
+			"user_id":            {Type: schema.TypeString, Required: true},
+		},
+	}
+}
+
+// resourceMemberStateUpgradeV0 rebuilds the ID from group_id and user_id, escaping the user id.
+func resourceMemberStateUpgradeV0(_ context.Context, rawState map[string]any, _ any) (map[string]any, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+	groupId, groupIdErr := stateInt(rawState["group_id"])
+	if groupIdErr != nil {
+		return nil, fmt.Errorf("could not upgrade lldap_member state: %s", groupIdErr)
+	}
+	userId, _ := rawState["user_id"].(string)
+	rawState["id"] = resourceMemberGetId(groupId, userId)
+	return rawState, nil
+}
+
+func resourceUserAttributeAssignmentV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"attribute_id": {Type: schema.TypeString, Required: true},
+			"id":           {Type: schema.TypeString, Computed: true},
+			"user_id":      {Type: schema.TypeString, Required: true},
+			"value":        {Type: schema.TypeSet, Required: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		},
+	}
+}
+
+// resourceUserAttributeAssignmentStateUpgradeV0 rebuilds the ID from user_id and attribute_id, escaping both.
+func resourceUserAttributeAssignmentStateUpgradeV0(_ context.Context, rawState map[string]any, _ any) (map[string]any, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+	userId, _ := rawState["user_id"].(string)
+	attributeId, _ := rawState["attribute_id"].(string)
+	rawState["id"] = FormatCompositeId(userId, attributeId)
+	return rawState, nil
+}
+
+func resourceGroupAttributeAssignmentV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"attribute_id": {Type: schema.TypeString, Required: true},
+			"group_id":     {Type: schema.TypeInt, Required: true},
+			"id":           {Type: schema.TypeString, Computed: true},
+			"value":        {Type: schema.TypeSet, Required: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		},
+	}
+}
+
+// resourceGroupAttributeAssignmentStateUpgradeV0 rebuilds the ID from group_id and attribute_id, escaping the attribute name.
+func resourceGroupAttributeAssignmentStateUpgradeV0(_ context.Context, rawState map[string]any, _ any) (map[string]any, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+	groupId, groupIdErr := stateInt(rawState["group_id"])
+	if groupIdErr != nil {
+		return nil, fmt.Errorf("could not upgrade lldap_group_attribute_assignment state: %s", groupIdErr)
+	}
+	attributeId, _ := rawState["attribute_id"].(string)
+	rawState["id"] = FormatCompositeId(strconv.Itoa(groupId), attributeId)
+	return rawState, nil
+}
+
+func resourceGroupMembershipsV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"group_id": {Type: schema.TypeString, Required: true},
+			"id":       {Type: schema.TypeString, Computed: true},
+			"user_ids": {Type: schema.TypeSet, Required: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		},
+	}
+}
+
+// resourceGroupMembershipsStateUpgradeV0 converts group_id to a number.
+func resourceGroupMembershipsStateUpgradeV0(_ context.Context, rawState map[string]any, _ any) (map[string]any, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+	groupId, groupIdErr := stateInt(rawState["group_id"])
+	if groupIdErr != nil {
+		return nil, fmt.Errorf("could not upgrade lldap_group_memberships state: %s", groupIdErr)
+	}
+	rawState["group_id"] = groupId
+	return rawState, nil
+}
+
+func resourceUserMembershipsV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"group_ids": {Type: schema.TypeSet, Required: true, Elem: &schema.Schema{Type: schema.TypeString}},
+			"id":        {Type: schema.TypeString, Computed: true},
+			"user_id":   {Type: schema.TypeString, Required: true},
+		},
+	}
+}
+
+// resourceUserMembershipsStateUpgradeV0 converts group_ids to numbers.
+func resourceUserMembershipsStateUpgradeV0(_ context.Context, rawState map[string]any, _ any) (map[string]any, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+	rawGroupIds, _ := rawState["group_ids"].([]any)
+	groupIds := make([]any, len(rawGroupIds))
+	for i, rawGroupId := range rawGroupIds {
+		groupId, groupIdErr := stateInt(rawGroupId)
+		if groupIdErr != nil {
+			return nil, fmt.Errorf("could not upgrade lldap_user_memberships state: %s", groupIdErr)
+		}
+		groupIds[i] = groupId
+	}
+	rawState["group_ids"] = groupIds
+	return rawState, nil
+}
@@ -0,0 +1,155 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	ctymsgpack "github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceMemberStateUpgradeV0(t *testing.T) {
+	state, upgradeErr := resourceMemberStateUpgradeV0(context.Background(), map[string]any{
+		"id":       "1:we:ird",
+		"group_id": float64(1),
+		"user_id":  "we:ird",
+	}, nil)
+	assert.Nil(t, upgradeErr)
+	assert.Equal(t, "1:we%3Aird", state["id"])
+
+	_, upgradeErr = resourceMemberStateUpgradeV0(context.Background(), map[string]any{"id": "x:y"}, nil)
+	assert.NotNil(t, upgradeErr)
+}
+
+func TestResourceAttributeAssignmentStateUpgradeV0(t *testing.T) {
+	state, upgradeErr := resourceUserAttributeAssignmentStateUpgradeV0(context.Background(), map[string]any{
+		"id":           "we:ird:attr",
+		"user_id":      "we:ird",
+		"attribute_id": "attr",
+	}, nil)
+	assert.Nil(t, upgradeErr)
+	assert.Equal(t, "we%3Aird:attr", state["id"])
+
+	state, upgradeErr = resourceGroupAttributeAssignmentStateUpgradeV0(context.Background(), map[string]any{
+		"id":           "2:a:b",
+		"group_id":     float64(2),
+		"attribute_id": "a:b",
+	}, nil)
+	assert.Nil(t, upgradeErr)
+	assert.Equal(t, "2:a%3Ab", state["id"])
+}
+
+func TestResourceMembershipsStateUpgradeV0(t *testing.T) {
+	state, upgradeErr := resourceGroupMembershipsStateUpgradeV0(context.Background(), map[string]any{
+		"id":       "3",
+		"group_id": "3",
+		"user_ids": []any{"alice"},
+	}, nil)
+	assert.Nil(t, upgradeErr)
+	assert.Equal(t, 3, state["group_id"])
+
+	state, upgradeErr = resourceUserMembershipsStateUpgradeV0(context.Background(), map[string]any{
+		"id":        "alice",
+		"user_id":   "alice",
+		"group_ids": []any{"1", "20"},
+	}, nil)
+	assert.Nil(t, upgradeErr)
+	assert.Equal(t, []any{1, 20}, state["group_ids"])
+
+	_, upgradeErr = resourceUserMembershipsStateUpgradeV0(context.Background(), map[string]any{
+		"group_ids": []any{"not a number"},
+	}, nil)
+	assert.NotNil(t, upgradeErr)
+}
+
+func TestStateUpgraders(t *testing.T) {
+	// InternalValidate checks that the upgraders cover all versions below SchemaVersion
+	assert.Nil(t, Provider().InternalValidate())
+}
+
+// baselineStates are states as written by the last release, before schema version 1.
+var baselineStates = map[string]struct {
+	v0       *schema.Resource
+	state    string
+	expected map[string]cty.Value
+}{
+	"lldap_member": {
+		v0:    resourceMemberV0(),
+		state: `{"group_display_name":"ops","group_id":1,"id":"1:we:ird","user_id":"we:ird"}`,
+		expected: map[string]cty.Value{
+			"id":         cty.StringVal("1:we%3Aird"),
+			"group_id":   cty.NumberIntVal(1),
+			"expires_at": cty.NullVal(cty.String),
+		},
+	},
+	"lldap_user_attribute_assignment": {
+		v0:    resourceUserAttributeAssignmentV0(),
+		state: `{"attribute_id":"attr","id":"we:ird:attr","user_id":"we:ird","value":["a"]}`,
+		expected: map[string]cty.Value{
+			"id":    cty.StringVal("we%3Aird:attr"),
+			"value": cty.SetVal([]cty.Value{cty.StringVal("a")}),
+		},
+	},
+	"lldap_group_attribute_assignment": {
+		v0:    resourceGroupAttributeAssignmentV0(),
+		state: `{"attribute_id":"a:b","group_id":2,"id":"2:a:b","value":["a"]}`,
+		expected: map[string]cty.Value{
+			"id":       cty.StringVal("2:a%3Ab"),
+			"group_id": cty.NumberIntVal(2),
+		},
+	},
+	"lldap_group_memberships": {
+		v0:    resourceGroupMembershipsV0(),
+		state: `{"group_id":"3","id":"3","user_ids":["alice"]}`,
+		expected: map[string]cty.Value{
+			"group_id":   cty.NumberIntVal(3),
+			"user_ids":   cty.SetVal([]cty.Value{cty.StringVal("alice")}),
+			"expires_at": cty.NullVal(cty.Map(cty.String)),
+		},
+	},
+	"lldap_user_memberships": {
+		v0:    resourceUserMembershipsV0(),
+		state: `{"group_ids":["1","20"],"id":"alice","user_id":"alice"}`,
+		expected: map[string]cty.Value{
+			"group_ids": cty.SetVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(20)}),
+		},
+	},
+}
+
+func TestStateUpgradeFromBaseline(t *testing.T) {
+	server := schema.NewGRPCProviderServer(Provider())
+	for typeName, baseline := range baselineStates {
+		// The version 0 schema must match the state of the last release exactly
+		var rawState map[string]any
+		require.NoError(t, json.Unmarshal([]byte(baseline.state), &rawState))
+		v0Attributes := slices.Sorted(maps.Keys(baseline.v0.CoreConfigSchema().Attributes))
+		assert.Equal(t, slices.Sorted(maps.Keys(rawState)), v0Attributes, typeName)
+
+		resp, err := server.UpgradeResourceState(context.Background(), &tfprotov5.UpgradeResourceStateRequest{
+			TypeName: typeName,
+			Version:  0,
+			RawState: &tfprotov5.RawState{JSON: []byte(baseline.state)},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Diagnostics, typeName)
+		ty := Provider().ResourcesMap[typeName].CoreConfigSchema().ImpliedType()
+		upgraded, unmarshalErr := ctymsgpack.Unmarshal(resp.UpgradedState.MsgPack, ty)
+		require.NoError(t, unmarshalErr, typeName)
+		for name, expected := range baseline.expected {
+			assert.True(t, expected.RawEquals(upgraded.GetAttr(name)), "%s.%s: %#v", typeName, name, upgraded.GetAttr(name))
+		}
+	}
+}
@@ -8,223 +8,587 @@ package lldap
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func resourceUser() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceUserCreate,
-		ReadContext:   resourceUserRead,
-		UpdateContext: resourceUserUpdate,
-		DeleteContext: resourceUserDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: func(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
-				_ = d.Set("id", d.Id())
-				return schema.ImportStatePassthroughContext(ctx, d, m)
+// userResource implements lldap_user.
+type userResource struct {
+	client *LldapClient
+}
+
+// userResourceModelV0 is the state written by the SDK implementation, which the
+// current schema extends with the write-only password.
+type userResourceModelV0 struct {
+	Attributes         types.Set    `tfsdk:"attributes"`
+	Avatar             types.String `tfsdk:"avatar"`
+	CreationDate       types.String `tfsdk:"creation_date"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
+	DisplayName        types.String `tfsdk:"display_name"`
+	Email              types.String `tfsdk:"email"`
+	FirstName          types.String `tfsdk:"first_name"`
+	Groups             types.Set    `tfsdk:"groups"`
+	Id                 types.String `tfsdk:"id"`
+	LastName           types.String `tfsdk:"last_name"`
+	Password           types.String `tfsdk:"password"`
+	RenameStrategy     types.String `tfsdk:"rename_strategy"`
+	Username           types.String `tfsdk:"username"`
+	Uuid               types.String `tfsdk:"uuid"`
+}
+
+type userResourceModel struct {
+	userResourceModelV0
+	PasswordWo        types.String `tfsdk:"password_wo"`
+	PasswordWoVersion types.Int64  `tfsdk:"password_wo_version"`
+}
+
+var userGroupObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"creation_date": types.StringType,
+		"display_name":  types.StringType,
+		"id":            types.StringType,
+	},
+}
+
+var (
+	_ resource.ResourceWithConfigure      = &userResource{}
+	_ resource.ResourceWithImportState    = &userResource{}
+	_ resource.ResourceWithModifyPlan     = &userResource{}
+	_ resource.ResourceWithUpgradeState   = &userResource{}
+	_ resource.ResourceWithValidateConfig = &userResource{}
+)
+
+func NewUserResource() resource.Resource {
+	return &userResource{}
+}
+
+// customAttributesSchema is the computed attributes of lldap_user and lldap_group.
+func customAttributesSchema(description string) schema.SetNestedAttribute {
+	return schema.SetNestedAttribute{
+		Computed:    true,
+		Description: description,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					Computed:    true,
+					Description: "Unique name of this attribute",
+				},
+				"value": schema.SetAttribute{
+					Computed:    true,
+					ElementType: types.StringType,
+					Description: "List of values for this attribute",
+				},
 			},
 		},
+	}
+}
+
+func (r *userResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user"
+}
+
+func (r *userResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = userResourceSchema()
+}
+
+func userResourceSchema() schema.Schema {
+	return schema.Schema{
 		Description: "Manages a LLDAP user",
-		Schema: map[string]*schema.Schema{
-			"attributes": {
-				Type:        schema.TypeSet,
-				Computed:    true,
-				Description: "Attributes for this user",
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"name": {
-							Type:        schema.TypeString,
-							Required:    true,
-							Description: "Unique name of this attribute",
-						},
-						"value": {
-							Type:     schema.TypeSet,
-							Required: true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
-							Description: "List of values for this attribute",
-						},
-					},
-				},
-			},
-			"avatar": {
-				Type:        schema.TypeString,
+		// Version 1 stores unset profile fields as null instead of empty strings
+		Version: 1,
+		Attributes: map[string]schema.Attribute{
+			"attributes": customAttributesSchema("Custom attributes for this user, without the built-in attributes LLDAP manages itself"),
+			"avatar": schema.StringAttribute{
 				Optional:    true,
 				Description: "Base 64 encoded JPEG image",
 			},
-			"creation_date": {
-				Type:        schema.TypeString,
+			"creation_date": schema.StringAttribute{
 				Computed:    true,
 				Description: "Metadata of user object creation",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"display_name": {
-				Type:        schema.TypeString,
+			"deletion_protection": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: deletionProtectionSchema.Description,
+			},
+			"display_name": schema.StringAttribute{
 				Optional:    true,
-				Default:     "",
 				Description: "Display name of this user",
 			},
-			"email": {
-				Type:        schema.TypeString,
+			"email": schema.StringAttribute{
 				Required:    true,
 				Description: "The unique user email",
 			},
-			"first_name": {
-				Type:        schema.TypeString,
+			"first_name": schema.StringAttribute{
 				Optional:    true,
-				Default:     "",
 				Description: "First name of this user",
 			},
-			"groups": &dataSourceGroupsSchema,
-			"id": {
-				Type:        schema.TypeString,
+			"groups": schema.SetNestedAttribute{
+				Computed:    true,
+				Description: "Groups where the user is a member",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"creation_date": schema.StringAttribute{
+							Computed:    true,
+							Description: "Metadata of group object creation",
+						},
+						"display_name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Display name of the group",
+						},
+						"id": schema.StringAttribute{
+							Computed:    true,
+							Description: "The unique group ID",
+						},
+					},
+				},
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"id": schema.StringAttribute{
 				Computed:    true,
 				Description: "ID representing this specific user",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"last_name": {
-				Type:        schema.TypeString,
+			"last_name": schema.StringAttribute{
 				Optional:    true,
-				Default:     "",
 				Description: "Last name of this user",
 			},
-			"password": {
-				Type:        schema.TypeString,
+			"password": schema.StringAttribute{
 				Optional:    true,
 				Sensitive:   true,
-				Description: "Password for the user. Note that the provider cannot read the password from LLDAP, so if this value is not set, the password attribute will be entirely ignored by the provider",
+				Description: "Password for the user. Note that the provider cannot read the password from LLDAP, so if this value is not set, the password attribute will be entirely ignored by the provider. A password that no longer works is shown as a change in the next plan. Conflicts with `password_wo`",
+			},
+			"password_wo": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				WriteOnly:   true,
+				Description: "Password for the user that is never stored in the plan or state, requires Terraform 1.11 or later. It is set when the user is created and whenever `password_wo_version` changes. Conflicts with `password`",
+			},
+			"password_wo_version": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Changing this value sets the password to `password_wo` again",
 			},
-			"username": {
-				Type:        schema.TypeString,
+			"rename_strategy": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(RenameStrategyRecreate),
+				Description: "How a change of `username` is applied, as LLDAP cannot rename users: `recreate` replaces the user, `migrate` creates the new user with the profile fields, custom attributes, group memberships and known password of the old one, then deletes the old user, which is refused while `deletion_protection` is `true`. Both give the user a new UUID (default: `recreate`)",
+			},
+			"username": schema.StringAttribute{
 				Required:    true,
-				ForceNew:    true,
-				Description: "The unique username",
-				StateFunc: func(val any) string {
-					return strings.ToLower(val.(string))
+				Description: "The unique username in lower case, changing it replaces the user unless `rename_strategy` is `migrate`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(
+						func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+							var renameStrategy types.String
+							resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("rename_strategy"), &renameStrategy)...)
+							resp.RequiresReplace = renameStrategy.ValueString() != RenameStrategyMigrate
+						},
+						"Changing the username replaces the user, unless rename_strategy is migrate",
+						"Changing the username replaces the user, unless `rename_strategy` is `migrate`",
+					),
 				},
 			},
-			"uuid": {
-				Type:        schema.TypeString,
+			"uuid": schema.StringAttribute{
 				Computed:    true,
 				Description: "UUID of user",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
 }
 
-func resourceUserSetResourceData(d *schema.ResourceData, user *LldapUser) diag.Diagnostics {
-	for k, v := range map[string]any{
-		"attributes":    attributesParser(user.Attributes),
-		"avatar":        user.Avatar,
-		"creation_date": user.CreationDate,
-		"display_name":  user.DisplayName,
-		"email":         user.Email,
-		"first_name":    user.FirstName,
-		"groups":        dataSourceGroupsParser(user.Groups),
-		"last_name":     user.LastName,
-		"username":      user.Id,
-		"uuid":          user.Uuid,
-	} {
-		if setErr := d.Set(k, v); setErr != nil {
-			return diag.FromErr(setErr)
-		}
+func (r *userResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	client, clientDiags := frameworkClient(req.ProviderData)
+	resp.Diagnostics.Append(clientDiags...)
+	r.client = client
+}
+
+// UpgradeState converts state written by the SDK implementation, which stored unset profile
+// fields and passwords as empty strings, and had no rename_strategy before it was added.
+func (r *userResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	priorSchema := userResourceSchema()
+	priorSchema.Version = 0
+	delete(priorSchema.Attributes, "password_wo")
+	delete(priorSchema.Attributes, "password_wo_version")
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &priorSchema,
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior userResourceModelV0
+				resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				for _, field := range []*types.String{&prior.Avatar, &prior.DisplayName, &prior.FirstName, &prior.LastName, &prior.Password} {
+					*field = optionalStringValue(field.ValueString(), types.StringNull())
+				}
+				if prior.RenameStrategy.IsNull() {
+					prior.RenameStrategy = types.StringValue(RenameStrategyRecreate)
+				}
+				upgraded := userResourceModel{
+					userResourceModelV0: prior,
+					PasswordWo:          types.StringNull(),
+					PasswordWoVersion:   types.Int64Null(),
+				}
+				resp.Diagnostics.Append(resp.State.Set(ctx, upgraded)...)
+			},
+		},
 	}
-	if user.Password != "" {
-		if setErr := d.Set("password", user.Password); setErr != nil {
-			return diag.FromErr(setErr)
-		}
+}
+
+func (r *userResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config userResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// LLDAP stores user ids in lower case, which the plan must match
+	if username := config.Username.ValueString(); username != strings.ToLower(username) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("username"),
+			"Invalid username",
+			fmt.Sprintf("LLDAP user ids are lower case, use '%s' instead of '%s'", strings.ToLower(username), username),
+		)
+	}
+	if renameStrategy := config.RenameStrategy; !renameStrategy.IsNull() && !renameStrategy.IsUnknown() && !slices.Contains(RenameStrategies, renameStrategy.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rename_strategy"),
+			"Invalid rename_strategy",
+			fmt.Sprintf("rename_strategy must be one of %s, got: %s", strings.Join(RenameStrategies, ", "), renameStrategy.ValueString()),
+		)
+	}
+	if !config.Password.IsNull() && !config.PasswordWo.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password_wo"),
+			"Conflicting passwords",
+			"Only one of password and password_wo can be set",
+		)
 	}
-	return nil
 }
 
-func resourceUserGetResourceData(d *schema.ResourceData) LldapUser {
-	return LldapUser{
-		Id:          d.Get("username").(string),
-		Email:       d.Get("email").(string),
-		Password:    d.Get("password").(string),
-		DisplayName: d.Get("display_name").(string),
-		FirstName:   d.Get("first_name").(string),
-		LastName:    d.Get("last_name").(string),
-		Uuid:        d.Get("uuid").(string),
-		Avatar:      d.Get("avatar").(string),
+// ModifyPlan refuses plans migrating users with deletion protection, plans replacing the provider
+// user or changing its password, and passwords violating the password policy. It also applies the
+// provider default if deletion_protection is not configured.
+func (r *userResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	var plan userResourceModel
+	var passwordWo types.String
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password_wo"), &passwordWo)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var state *userResourceModel
+	if !req.State.Raw.IsNull() {
+		state = &userResourceModel{}
+		resp.Diagnostics.Append(req.State.Get(ctx, state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	renamed := state != nil && !plan.Username.IsUnknown() && !plan.Username.Equal(state.Username)
+	if renamed {
+		// Migrating deletes the old user, so it is refused while the user has deletion protection
+		if plan.RenameStrategy.ValueString() == RenameStrategyMigrate && state.DeletionProtection.ValueBool() {
+			resp.Diagnostics.AddAttributeError(path.Root("username"), "Deletion protection is enabled",
+				fmt.Sprintf("Cannot migrate user '%s' to '%s' while deletion_protection is enabled, as migrating deletes the old user. Set deletion_protection = false and apply before renaming it.", state.Id.ValueString(), plan.Username.ValueString()))
+			return
+		}
+		// The migrated user is a new user
+		for _, computed := range []string{"creation_date", "id", "uuid"} {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(computed), types.StringUnknown())...)
+		}
+	}
+	if r.client == nil {
+		return
+	}
+	passwordChanged := !plan.Password.IsUnknown() && plan.Password.ValueString() != "" &&
+		(state == nil || !plan.Password.Equal(state.Password))
+	passwordWoChanged := !passwordWo.IsUnknown() && passwordWo.ValueString() != "" &&
+		(state == nil || !plan.PasswordWoVersion.Equal(state.PasswordWoVersion))
+	if lc, guarded := lockoutGuard(r.client); guarded && state != nil && lc.isProviderUser(state.Id.ValueString()) {
+		if renamed {
+			resp.Diagnostics.AddAttributeError(path.Root("username"), "Refusing to replace the provider user",
+				lc.lockoutError(fmt.Sprintf("Replacing user '%s'", state.Id.ValueString())).Error())
+		}
+		for attribute, changed := range map[string]bool{"password": passwordChanged, "password_wo": passwordWoChanged} {
+			password := plan.Password
+			if attribute == "password_wo" {
+				password = passwordWo
+			}
+			if changed && password.ValueString() != lc.Config.Password {
+				resp.Diagnostics.AddAttributeError(path.Root(attribute), "Refusing to change the password of the provider user",
+					lc.lockoutError(fmt.Sprintf("Changing the password of user '%s'", state.Id.ValueString())).Error())
+			}
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	// Passwords that are unknown at plan time are checked on apply, before any change is made
+	email := ""
+	if !plan.Email.IsUnknown() {
+		email = plan.Email.ValueString()
+	}
+	if passwordChanged {
+		if policyErr := r.client.ValidatePassword(plan.Password.ValueString(), plan.Username.ValueString(), email); policyErr != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("password"), "Invalid password", policyErr.Error())
+		}
+	}
+	if passwordWoChanged {
+		if policyErr := r.client.ValidatePassword(passwordWo.ValueString(), plan.Username.ValueString(), email); policyErr != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("password_wo"), "Invalid password", policyErr.Error())
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	planDeletionProtection(ctx, r.client, req, resp)
 }
 
-func resourceUserCreate(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	user := resourceUserGetResourceData(d)
-	lc := m.(*LldapClient)
-	createErr := lc.CreateUser(&user)
-	if createErr != nil {
-		return createErr
+func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly("Creating lldap_user"))...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if user.Password != "" {
-		setPwErr := lc.SetUserPassword(user.Id, user.Password)
-		if setPwErr != nil {
-			return setPwErr
+	var plan userResourceModel
+	var passwordWo types.String
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password_wo"), &passwordWo)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	user := plan.toUser()
+	password := user.Password
+	if password == "" {
+		password = passwordWo.ValueString()
+	}
+	if password != "" {
+		if policyErr := r.client.ValidatePassword(password, user.Id, user.Email); policyErr != nil {
+			resp.Diagnostics.AddError("Invalid password", policyErr.Error())
+			return
 		}
 	}
-	d.SetId(user.Id)
-	setRdErr := resourceUserSetResourceData(d, &user)
-	if setRdErr != nil {
-		return setRdErr
+	createErr := r.client.CreateUser(&user)
+	resp.Diagnostics.Append(frameworkDiagnostics(createErr)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// The user exists from here on, so it is kept in state even if setting it up fails
+	if password != "" {
+		resp.Diagnostics.Append(frameworkDiagnostics(r.client.SetUserPassword(user.Id, password))...)
 	}
-	return nil
+	if !resp.Diagnostics.HasError() {
+		resp.Diagnostics.Append(frameworkDiagnostics(r.client.MarkUserManaged(&user))...)
+	}
+	r.setComputed(&plan, &user, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
-func resourceUserRead(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	lc := m.(*LldapClient)
-	user, getUserErr := lc.GetUser(d.Id())
+func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state userResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	user, getUserErr := r.client.GetUser(state.Id.ValueString())
 	if getUserErr != nil {
 		// If the user was not found, mark the resource as deleted so Terraform will recreate it
 		if isEntityNotFoundError(getUserErr) {
-			d.SetId("")
-			return nil
+			resp.State.RemoveResource(ctx)
+			return
 		}
-		return getUserErr
+		resp.Diagnostics.Append(frameworkDiagnostics(getUserErr)...)
+		return
 	}
 	// We cannot read the password from LLDAP, but we can check whether the value from state is still valid.
-	statePassword := d.Get("password").(string)
-	if statePassword != "" {
-		isValidPassword, _ := lc.IsValidPassword(user.Id, statePassword)
-		if isValidPassword {
-			user.Password = statePassword
+	// Read-only credentials may not be allowed to bind as other users, and the password is kept if the bind
+	// fails for other reasons than invalid credentials.
+	if statePassword := state.Password.ValueString(); statePassword != "" && !r.client.Config.ReadOnly {
+		isValidPassword, bindErr := r.client.IsValidPassword(user.Id, statePassword)
+		if bindErr == nil && !isValidPassword {
+			state.Password = types.StringNull()
 		}
 	}
-	setRdErr := resourceUserSetResourceData(d, user)
-	if setRdErr != nil {
-		return setRdErr
-	}
-	return nil
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.markUserManagedOnRead(user))...)
+	state.Avatar = optionalStringValue(user.Avatar, state.Avatar)
+	state.DisplayName = optionalStringValue(user.DisplayName, state.DisplayName)
+	state.Email = types.StringValue(user.Email)
+	state.FirstName = optionalStringValue(user.FirstName, state.FirstName)
+	state.LastName = optionalStringValue(user.LastName, state.LastName)
+	state.Username = types.StringValue(user.Id)
+	r.setComputed(&state, user, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
 }
 
-func resourceUserUpdate(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	lc := m.(*LldapClient)
-	user := resourceUserGetResourceData(d)
-	updateErr := lc.UpdateUser(&user)
-	if updateErr != nil {
-		return updateErr
+func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly("Updating lldap_user"))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var plan, state userResourceModel
+	var passwordWo types.String
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password_wo"), &passwordWo)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// The prior state is kept if the update fails
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	user := plan.toUser()
+	passwordWoChanged := passwordWo.ValueString() != "" && !plan.PasswordWoVersion.Equal(state.PasswordWoVersion)
+	var changedPasswords []string
+	if user.Password != "" && !plan.Password.Equal(state.Password) {
+		changedPasswords = append(changedPasswords, user.Password)
+	}
+	if passwordWoChanged {
+		changedPasswords = append(changedPasswords, passwordWo.ValueString())
+	}
+	for _, password := range changedPasswords {
+		if policyErr := r.client.ValidatePassword(password, user.Id, user.Email); policyErr != nil {
+			resp.Diagnostics.AddError("Invalid password", policyErr.Error())
+			return
+		}
+	}
+	if !plan.Username.Equal(state.Username) {
+		knownPassword := user.Password
+		if knownPassword == "" {
+			knownPassword = passwordWo.ValueString()
+		}
+		renamedUser, renameErr := r.client.RenameUser(state.Id.ValueString(), user.Id, knownPassword)
+		resp.Diagnostics.Append(frameworkDiagnostics(renameErr)...)
+		if renameErr != nil {
+			// Only track the new user if it was kept, otherwise the old user is left as it was
+			if renamedUser != nil {
+				r.setComputed(&plan, renamedUser, &resp.Diagnostics)
+				resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+			}
+			return
+		}
+	}
+	updateErr := r.client.UpdateUser(&user)
+	resp.Diagnostics.Append(frameworkDiagnostics(updateErr)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Users imported or created before managed_marker was configured are tagged as well
+	markErr := r.client.MarkUserManaged(&user)
+	resp.Diagnostics.Append(frameworkDiagnostics(markErr)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 	if user.Password != "" {
-		isValidPassword, bindErr := lc.IsValidPassword(user.Id, user.Password)
-		if bindErr != nil {
-			return bindErr
+		isValidPassword, bindErr := r.client.IsValidPassword(user.Id, user.Password)
+		resp.Diagnostics.Append(frameworkDiagnostics(bindErr)...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
 		if !isValidPassword {
-			setPwErr := lc.SetUserPassword(user.Id, user.Password)
-			if setPwErr != nil {
-				return setPwErr
-			}
+			r.setPassword(user.Id, user.Password, &resp.Diagnostics)
 		}
+	} else if passwordWoChanged {
+		r.setPassword(user.Id, passwordWo.ValueString(), &resp.Diagnostics)
 	}
-	return nil
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	updatedUser, getUserErr := r.client.GetUser(user.Id)
+	resp.Diagnostics.Append(frameworkDiagnostics(getUserErr)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.setComputed(&plan, updatedUser, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
-func resourceUserDelete(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	lc := m.(*LldapClient)
-	deleteErr := lc.DeleteUser(d.Id())
-	if deleteErr != nil {
-		return deleteErr
+func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly("Deleting lldap_user"))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var state userResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	userId := state.Id.ValueString()
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.Append(frameworkDiagnostics(deletionProtectionError("user", userId))...)
+		return
+	}
+	if lc, guarded := lockoutGuard(r.client); guarded && lc.isProviderUser(userId) {
+		resp.Diagnostics.AddError("Refusing to delete the provider user", lc.lockoutError(fmt.Sprintf("Deleting user '%s'", userId)).Error())
+		return
+	}
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.DeleteUser(userId))...)
+}
+
+func (r *userResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("rename_strategy"), RenameStrategyRecreate)...)
+}
+
+// setPassword sets the password of a user, unless it is the password of the provider user.
+func (r *userResource) setPassword(userId string, password string, diags *fwdiag.Diagnostics) {
+	if lc, guarded := lockoutGuard(r.client); guarded && lc.isProviderUser(userId) && password != lc.Config.Password {
+		diags.AddError("Refusing to change the password of the provider user", lc.lockoutError(fmt.Sprintf("Changing the password of user '%s'", userId)).Error())
+		return
+	}
+	diags.Append(frameworkDiagnostics(r.client.SetUserPassword(userId, password))...)
+}
+
+// setComputed sets the values LLDAP assigns to the user.
+func (r *userResource) setComputed(model *userResourceModel, user *LldapUser, diags *fwdiag.Diagnostics) {
+	attributes, attributesErr := r.client.UserCustomAttributes(user)
+	diags.Append(frameworkDiagnostics(attributesErr)...)
+	groups := make([]attr.Value, len(user.Groups))
+	for i, group := range user.Groups {
+		groups[i] = types.ObjectValueMust(userGroupObjectType.AttrTypes, map[string]attr.Value{
+			"creation_date": types.StringValue(group.CreationDate),
+			"display_name":  types.StringValue(group.DisplayName),
+			"id":            types.StringValue(fmt.Sprint(group.Id)),
+		})
+	}
+	model.Attributes = customAttributesValue(attributes)
+	model.CreationDate = types.StringValue(user.CreationDate)
+	model.Groups = types.SetValueMust(userGroupObjectType, groups)
+	model.Id = types.StringValue(user.Id)
+	model.Uuid = types.StringValue(user.Uuid)
+}
+
+func (m userResourceModel) toUser() LldapUser {
+	return LldapUser{
+		Id:          m.Username.ValueString(),
+		Email:       m.Email.ValueString(),
+		Password:    m.Password.ValueString(),
+		DisplayName: m.DisplayName.ValueString(),
+		FirstName:   m.FirstName.ValueString(),
+		LastName:    m.LastName.ValueString(),
+		Avatar:      m.Avatar.ValueString(),
 	}
-	return nil
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -21,7 +22,12 @@ func resourceUserAttribute() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceUserAttributeCreate,
 		ReadContext:   resourceUserAttributeRead,
+		UpdateContext: resourceUserAttributeUpdate,
 		DeleteContext: resourceUserAttributeDelete,
+		CustomizeDiff: customdiff.All(
+			deletionProtectionCustomizeDiff,
+			attributeMigrationCustomizeDiff("user", []string{"attribute_type", "is_editable", "is_list", "is_visible"}, (*LldapClient).UserAttributeValues),
+		),
 		Importer: &schema.ResourceImporter{
 			StateContext: func(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
 				_ = d.Set("id", d.Id())
@@ -33,7 +39,6 @@ func resourceUserAttribute() *schema.Resource {
 			"attribute_type": {
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
 				Description: fmt.Sprintf("The attribute type (one of: %s)", strings.Join(VALID_ATTRIBUTE_TYPES[:], ", ")),
 				ValidateDiagFunc: func(v any, p cty.Path) diag.Diagnostics {
 					attributeType := v.(string)
@@ -44,6 +49,7 @@ func resourceUserAttribute() *schema.Resource {
 					return nil
 				},
 			},
+			"deletion_protection": &deletionProtectionSchema,
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -52,21 +58,18 @@ func resourceUserAttribute() *schema.Resource {
 			"is_editable": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
 				Default:     false,
 				Description: "Is this attribute user editable?",
 			},
 			"is_list": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
 				Default:     false,
 				Description: "Does this represent a list?",
 			},
 			"is_visible": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
 				Default:     true,
 				Description: "Is this attribute visible in LDAP?",
 			},
@@ -80,6 +83,7 @@ func resourceUserAttribute() *schema.Resource {
 				Computed:    true,
 				Description: "Is this attribute hardcoded (i.e. managed by LLDAP)?",
 			},
+			"migrate_values": &migrateValuesSchema,
 			"name": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -166,7 +170,26 @@ func resourceUserAttributeRead(_ context.Context, d *schema.ResourceData, m any)
 	return nil
 }
 
+// resourceUserAttributeUpdate migrates the attribute if its settings changed with migrate_values set,
+// deletion_protection and migrate_values are only kept in state
+func resourceUserAttributeUpdate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	if d.HasChanges("attribute_type", "is_editable", "is_list", "is_visible") {
+		lc := m.(*LldapClient)
+		target, getAttrErr := resourceUserAttributeGetResourceData(d)
+		if getAttrErr != nil {
+			return diag.FromErr(getAttrErr)
+		}
+		if migrateErr := lc.MigrateUserAttribute(*target); migrateErr != nil {
+			return migrateErr
+		}
+	}
+	return resourceUserAttributeRead(ctx, d, m)
+}
+
 func resourceUserAttributeDelete(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	if protectedErr := deletionProtectionCheck(d, "user attribute", d.Id()); protectedErr != nil {
+		return protectedErr
+	}
 	lc := m.(*LldapClient)
 	deleteErr := lc.DeleteUserAttribute(d.Id())
 	if deleteErr != nil {
@@ -11,27 +11,31 @@ import (
 	"encoding/json"
 	"fmt"
 	"slices"
-	"strings"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-const resourceUserAttributeAssignmentIdSeparator = ":"
-
 func resourceUserAttributeAssignment() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceUserAttributeAssignmentCreate,
 		ReadContext:   resourceUserAttributeAssignmentRead,
 		UpdateContext: resourceUserAttributeAssignmentUpdate,
 		DeleteContext: resourceUserAttributeAssignmentDelete,
+		CustomizeDiff: resourceUserAttributeAssignmentCustomizeDiff,
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceUserAttributeAssignmentV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceUserAttributeAssignmentStateUpgradeV0,
+			},
+		},
 		Importer: &schema.ResourceImporter{
 			StateContext: func(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
-				id := d.Id()
-				_, _, found := strings.Cut(id, resourceUserAttributeAssignmentIdSeparator)
-				if !found {
-					return nil, fmt.Errorf("not a valid attribute assignment id: %s", id)
+				if _, parseErr := ParseCompositeId(d.Id(), 2); parseErr != nil {
+					return nil, fmt.Errorf("not a valid attribute assignment id: %s", parseErr)
 				}
 				_ = d.Set("id", d.Id())
 				return schema.ImportStatePassthroughContext(ctx, d, m)
@@ -42,7 +46,7 @@ func resourceUserAttributeAssignment() *schema.Resource {
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
-				Description: "The assignment 'ID', constructed as user_id:attribute_name",
+				Description: "The assignment 'ID', constructed as user_id:attribute_name with ':' and '%' in the parts escaped as '%3A' and '%25'",
 			},
 			"attribute_id": {
 				Type:        schema.TypeString,
@@ -50,6 +54,11 @@ func resourceUserAttributeAssignment() *schema.Resource {
 				ForceNew:    true,
 				Description: "The attribute name",
 			},
+			"attribute_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The attribute type, used to compare values independent of their representation",
+			},
 			"user_id": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -59,44 +68,73 @@ func resourceUserAttributeAssignment() *schema.Resource {
 			"value": {
 				Type:        schema.TypeSet,
 				Required:    true,
-				Description: "The value(s) for this attribute",
+				Description: "The value(s) for this attribute, compared according to the attribute type (e.g. equivalent DATE_TIME timestamps or padded INTEGER values do not cause a diff)",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
+				DiffSuppressFunc: attributeValueDiffSuppress,
 			},
 		},
 	}
 }
 
+// resourceUserAttributeAssignmentCustomizeDiff validates the values against the attribute type at plan time.
+// Attributes that do not exist yet (e.g. created within the same apply) are validated on apply.
+func resourceUserAttributeAssignmentCustomizeDiff(_ context.Context, d *schema.ResourceDiff, m any) error {
+	if !d.NewValueKnown("attribute_id") || !d.NewValueKnown("value") {
+		return nil
+	}
+	lc, ok := m.(*LldapClient)
+	if !ok {
+		return nil
+	}
+	attributeId := d.Get("attribute_id").(string)
+	attributeSchema, getSchemaErr := lc.GetUserAttributeSchema(attributeId)
+	if getSchemaErr != nil || attributeSchema == nil {
+		return nil
+	}
+	_, canonicalErr := CanonicalAttributeValues(attributeSchema.AttributeType, attributeValueSetToList(d.Get("value")))
+	if canonicalErr != nil {
+		return fmt.Errorf("invalid value for user attribute '%s': %s", attributeId, canonicalErr)
+	}
+	return nil
+}
+
 func resourceUserAttributeAssignmentCreate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
 	userId := d.Get("user_id").(string)
 	attributeId := d.Get("attribute_id").(string)
-	valueRaw := d.Get("value").(*schema.Set)
-	valueRawList := valueRaw.List()
-	value := make([]string, len(valueRawList))
-	for i, vRaw := range valueRawList {
-		value[i] = vRaw.(string)
-	}
+	value := attributeValueSetToList(d.Get("value"))
 	something, _ := json.Marshal(value)
 	tflog.Error(ctx, fmt.Sprintf("Got something: %s", string(something)))
-	id := fmt.Sprintf("%s%s%s", userId, resourceUserAttributeAssignmentIdSeparator, attributeId)
+	id := FormatCompositeId(userId, attributeId)
 	tflog.Debug(ctx, fmt.Sprintf("Will create user attribute assignment with id: %s", id))
-	d.SetId(id)
 	lc := m.(*LldapClient)
-	addAttrErr := lc.AddAttributeToUser(userId, attributeId, value)
+	attributeType, getTypeErr := lc.GetUserAttributeType(attributeId)
+	if getTypeErr != nil {
+		return getTypeErr
+	}
+	canonicalValue, canonicalErr := CanonicalAttributeValues(attributeType, value)
+	if canonicalErr != nil {
+		return diag.FromErr(canonicalErr)
+	}
+	d.SetId(id)
+	addAttrErr := lc.AddAttributeToUser(userId, attributeId, canonicalValue)
 	if addAttrErr != nil {
 		return addAttrErr
 	}
+	if setErr := d.Set("attribute_type", attributeType); setErr != nil {
+		return diag.FromErr(setErr)
+	}
 	tflog.Info(ctx, fmt.Sprintf("Created user attribute assignment with id: %s", id))
 	return nil
 }
 
 func resourceUserAttributeAssignmentRead(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	id := d.Id()
-	userId, attributeId, ok := strings.Cut(id, resourceUserAttributeAssignmentIdSeparator)
-	if !ok {
-		return diag.Errorf("not a valid lldap_user_attribute_assignment id: %s", id)
+	parts, parseErr := ParseCompositeId(d.Id(), 2)
+	if parseErr != nil {
+		return diag.Errorf("not a valid lldap_user_attribute_assignment id: %s", parseErr)
 	}
+	userId, attributeId := parts[0], parts[1]
 
 	lc := m.(*LldapClient)
 	user, getUserErr := lc.GetUser(userId)
@@ -121,10 +159,20 @@ func resourceUserAttributeAssignmentRead(_ context.Context, d *schema.ResourceDa
 		d.SetId("")
 		return nil
 	}
+	attributeType, getTypeErr := lc.GetUserAttributeType(attributeId)
+	if getTypeErr != nil {
+		return getTypeErr
+	}
+	// Keep the representation from state if LLDAP returns an equivalent value
+	stateValue := attributeValueSetToList(d.Get("value"))
+	if AttributeValuesEquivalent(attributeType, stateValue, value) {
+		value = stateValue
+	}
 	for k, v := range map[string]any{
-		"user_id":      userId,
-		"attribute_id": attributeId,
-		"value":        value,
+		"attribute_type": attributeType,
+		"user_id":        userId,
+		"attribute_id":   attributeId,
+		"value":          value,
 	} {
 		if setErr := d.Set(k, v); setErr != nil {
 			return diag.FromErr(setErr)
@@ -136,23 +184,23 @@ func resourceUserAttributeAssignmentRead(_ context.Context, d *schema.ResourceDa
 func resourceUserAttributeAssignmentUpdate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
 	userId := d.Get("user_id").(string)
 	attributeId := d.Get("attribute_id").(string)
-	valueRaw := d.Get("value").(*schema.Set)
-	valueRawList := valueRaw.List()
-	value := make([]string, len(valueRawList))
-	for i, vRaw := range valueRawList {
-		value[i] = vRaw.(string)
-	}
+	value := attributeValueSetToList(d.Get("value"))
 	lc := m.(*LldapClient)
-
-	// First remove the existing attribute
-	removeAttrErr := lc.RemoveAttributeFromUser(userId, attributeId)
-	if removeAttrErr != nil {
-		return removeAttrErr
+	attributeType, getTypeErr := lc.GetUserAttributeType(attributeId)
+	if getTypeErr != nil {
+		return getTypeErr
+	}
+	canonicalValue, canonicalErr := CanonicalAttributeValues(attributeType, value)
+	if canonicalErr != nil {
+		return diag.FromErr(canonicalErr)
 	}
 
-	// Then add it back with the new values
-	updateAttrErr := lc.AddAttributeToUser(userId, attributeId, value)
+	// Inserting an attribute replaces its existing value in a single mutation,
+	// so a failed update never leaves the attribute removed
+	updateAttrErr := lc.AddAttributeToUser(userId, attributeId, canonicalValue)
 	if updateAttrErr != nil {
+		// Keep the previous value in state instead of the planned one
+		d.Partial(true)
 		return updateAttrErr
 	}
 	tflog.Info(ctx, fmt.Sprintf("Updated user attribute assignment with id: %s", d.Id()))
@@ -8,9 +8,7 @@ package lldap
 
 import (
 	"context"
-	"fmt"
 	"slices"
-	"strconv"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -22,6 +20,15 @@ func resourceUserMemberships() *schema.Resource {
 		ReadContext:   resourceUserMembershipsRead,
 		UpdateContext: resourceUserMembershipsUpdate,
 		DeleteContext: resourceUserMembershipsDelete,
+		CustomizeDiff: resourceUserMembershipsCustomizeDiff,
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceUserMembershipsV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceUserMembershipsStateUpgradeV0,
+			},
+		},
 		Importer: &schema.ResourceImporter{
 			StateContext: func(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
 				_ = d.Set("id", d.Id())
@@ -35,14 +42,7 @@ func resourceUserMemberships() *schema.Resource {
 				Description: "Groups id where the user must be a member",
 				Required:    true,
 				Elem: &schema.Schema{
-					Type: schema.TypeString,
-					ValidateFunc: func(val any, key string) (warns []string, errs []error) {
-						v := val.(string)
-						if _, err := strconv.Atoi(v); err != nil {
-							errs = append(errs, fmt.Errorf("%q must be a string representing an integer, got: %s", key, v))
-						}
-						return
-					},
+					Type: schema.TypeInt,
 				},
 			},
 			"id": {
@@ -61,14 +61,11 @@ func resourceUserMemberships() *schema.Resource {
 }
 
 func resourceUserMembershipsSetResourceData(d *schema.ResourceData, user *LldapUser) diag.Diagnostics {
-	groupIdsStr := make([]string, len(user.Groups))
-	for i, group := range user.Groups {
-		groupIdsStr[i] = strconv.Itoa(group.Id)
-	}
-	slices.Sort(groupIdsStr)
+	groupIds := user.GetGroupIds()
+	slices.Sort(groupIds)
 	for k, v := range map[string]any{
 		"user_id":   user.Id,
-		"group_ids": groupIdsStr,
+		"group_ids": groupIds,
 	} {
 		if v != nil {
 			if setErr := d.Set(k, v); setErr != nil {
@@ -80,16 +77,30 @@ func resourceUserMembershipsSetResourceData(d *schema.ResourceData, user *LldapU
 }
 
 func resourceUserMembershipsGetGroupIds(d *schema.ResourceData) ([]int, diag.Diagnostics) {
-	groupIdsUnparsedList := d.Get("group_ids").(*schema.Set).List()
-	groupIds := make([]int, len(groupIdsUnparsedList))
-	for i, groupIdVal := range groupIdsUnparsedList {
-		groupId, groupIdParseErr := strconv.Atoi(groupIdVal.(string))
-		if groupIdParseErr != nil {
-			return nil, diag.FromErr(groupIdParseErr)
+	return intSetToList(d.Get("group_ids")), nil
+}
+
+// resourceUserMembershipsCustomizeDiff refuses memberships that are also managed by another membership
+// resource, and plans removing the provider user from lldap_admin.
+func resourceUserMembershipsCustomizeDiff(_ context.Context, d *schema.ResourceDiff, m any) error {
+	if lc, ok := m.(*LldapClient); ok && d.NewValueKnown("user_id") && d.NewValueKnown("group_ids") {
+		if claimErr := lc.membershipClaims.claimUserGroups(d.Get("user_id").(string), intSetToList(d.Get("group_ids"))); claimErr != nil {
+			return claimErr
 		}
-		groupIds[i] = groupId
 	}
-	return groupIds, nil
+	lc, guarded := lockoutGuard(m)
+	if !guarded || d.Id() == "" || !d.NewValueKnown("group_ids") {
+		return nil
+	}
+	oldUserId, _ := d.GetChange("user_id")
+	oldGroupIds, newGroupIds := d.GetChange("group_ids")
+	removedGroupIds := []int{}
+	for _, groupId := range intSetToList(oldGroupIds) {
+		if d.HasChange("user_id") || !slices.Contains(intSetToList(newGroupIds), groupId) {
+			removedGroupIds = append(removedGroupIds, groupId)
+		}
+	}
+	return lc.checkUserGroupsRemoval(oldUserId.(string), removedGroupIds)
 }
 
 func resourceUserMembershipsCreate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
@@ -103,13 +114,23 @@ func resourceUserMembershipsCreate(ctx context.Context, d *schema.ResourceData,
 	if getUserErr != nil {
 		return getUserErr
 	}
-	for _, groupId := range groupIds {
-		addErr := lc.AddUserToGroup(groupId, userId)
-		if addErr != nil {
-			return addErr
-		}
+	addedGroupIds, addErr := lc.AddUserToGroups(groupIds, userId)
+	if addErr.HasError() && len(addedGroupIds) == 0 {
+		return addErr
 	}
 	d.SetId(user.Id)
+	if addErr.HasError() {
+		return append(addErr, resourceUserMembershipsSetApplied(d, addedGroupIds)...)
+	}
+	return nil
+}
+
+// resourceUserMembershipsSetApplied records the groups the user actually is a member of after a partially failed apply.
+func resourceUserMembershipsSetApplied(d *schema.ResourceData, memberGroupIds []int) diag.Diagnostics {
+	slices.Sort(memberGroupIds)
+	if setErr := d.Set("group_ids", memberGroupIds); setErr != nil {
+		return diag.FromErr(setErr)
+	}
 	return nil
 }
 
@@ -125,11 +146,27 @@ func resourceUserMembershipsRead(ctx context.Context, d *schema.ResourceData, m
 		}
 		return getUserErr
 	}
+	// Unmanaged groups that are not part of the configuration are ignored
+	stateGroupIds := intSetToList(d.Get("group_ids"))
+	unknownGroupIds := slices.DeleteFunc(user.GetGroupIds(), func(groupId int) bool {
+		return slices.Contains(stateGroupIds, groupId)
+	})
+	unmanagedGroupIds, unmanagedErr := lc.unmanagedUserGroups(user.Id, unknownGroupIds)
+	if unmanagedErr != nil {
+		return unmanagedErr
+	}
+	var diags diag.Diagnostics
+	for _, groupId := range unmanagedGroupIds {
+		diags = append(diags, unmanagedMembershipWarning(groupId, user.Id))
+	}
+	user.Groups = slices.DeleteFunc(user.Groups, func(group LldapGroup) bool {
+		return slices.Contains(unmanagedGroupIds, group.Id)
+	})
 	setRdErr := resourceUserMembershipsSetResourceData(d, user)
 	if setRdErr != nil {
 		return setRdErr
 	}
-	return nil
+	return diags
 }
 
 func resourceUserMembershipsUpdate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
@@ -144,23 +181,47 @@ func resourceUserMembershipsUpdate(ctx context.Context, d *schema.ResourceData,
 		return getUserErr
 	}
 	userHasGroupIds := user.GetGroupIds()
-	for _, wantsGroupId := range userWantsGroupIds {
-		if !slices.Contains(userHasGroupIds, wantsGroupId) {
-			addErr := lc.AddUserToGroup(wantsGroupId, user.Id)
-			if addErr != nil {
-				return addErr
-			}
+	if _, guarded := lockoutGuard(m); guarded {
+		removedGroupIds := slices.DeleteFunc(slices.Clone(userHasGroupIds), func(groupId int) bool {
+			return slices.Contains(userWantsGroupIds, groupId)
+		})
+		if lockoutErr := lc.checkUserGroupsRemoval(user.Id, removedGroupIds); lockoutErr != nil {
+			return diag.FromErr(lockoutErr)
 		}
 	}
-	for _, hasGroupId := range userHasGroupIds {
-		if !slices.Contains(userWantsGroupIds, hasGroupId) {
-			removeErr := lc.RemoveUserFromGroup(hasGroupId, user.Id)
-			if removeErr != nil {
-				return removeErr
-			}
-		}
+	addGroupIds := slices.DeleteFunc(slices.Clone(userWantsGroupIds), func(groupId int) bool {
+		return slices.Contains(userHasGroupIds, groupId)
+	})
+	removeGroupIds := slices.DeleteFunc(slices.Clone(userHasGroupIds), func(groupId int) bool {
+		return slices.Contains(userWantsGroupIds, groupId)
+	})
+	// Memberships that were ever configured are Terraform's own, only the others may be unmanaged
+	priorGroupIds, _ := d.GetChange("group_ids")
+	configuredGroupIds := append(intSetToList(priorGroupIds), userWantsGroupIds...)
+	unknownGroupIds := slices.DeleteFunc(slices.Clone(removeGroupIds), func(groupId int) bool {
+		return slices.Contains(configuredGroupIds, groupId)
+	})
+	unmanagedGroupIds, unmanagedErr := lc.unmanagedUserGroups(user.Id, unknownGroupIds)
+	if unmanagedErr != nil {
+		return unmanagedErr
 	}
-	return nil
+	var diags diag.Diagnostics
+	for _, groupId := range unmanagedGroupIds {
+		diags = append(diags, unmanagedMembershipWarning(groupId, user.Id))
+	}
+	removeGroupIds = slices.DeleteFunc(removeGroupIds, func(groupId int) bool {
+		return slices.Contains(unmanagedGroupIds, groupId)
+	})
+	addedGroupIds, addErr := lc.AddUserToGroups(addGroupIds, user.Id)
+	removedGroupIds, removeErr := lc.RemoveUserFromGroups(removeGroupIds, user.Id)
+	diags = append(append(diags, addErr...), removeErr...)
+	if diags.HasError() {
+		memberGroupIds := slices.DeleteFunc(slices.Clone(userHasGroupIds), func(groupId int) bool {
+			return slices.Contains(removedGroupIds, groupId) || slices.Contains(unmanagedGroupIds, groupId)
+		})
+		return append(diags, resourceUserMembershipsSetApplied(d, append(memberGroupIds, addedGroupIds...))...)
+	}
+	return diags
 }
 
 func resourceUserMembershipsDelete(_ context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
@@ -170,11 +231,19 @@ func resourceUserMembershipsDelete(_ context.Context, d *schema.ResourceData, m
 		return getGroupIdsErr
 	}
 	lc := m.(*LldapClient)
-	for _, groupId := range groupIds {
-		removeErr := lc.RemoveUserFromGroup(groupId, userId)
-		if removeErr != nil {
-			return removeErr
+	if _, guarded := lockoutGuard(m); guarded {
+		if lockoutErr := lc.checkUserGroupsRemoval(userId, groupIds); lockoutErr != nil {
+			return diag.FromErr(lockoutErr)
 		}
 	}
-	return nil
+	// All groups are configured, so they are removed even with reconcile_managed_only
+	removeGroupIds := slices.Clone(groupIds)
+	removedGroupIds, diags := lc.RemoveUserFromGroups(removeGroupIds, userId)
+	if diags.HasError() {
+		remainingGroupIds := slices.DeleteFunc(removeGroupIds, func(groupId int) bool {
+			return slices.Contains(removedGroupIds, groupId)
+		})
+		return append(diags, resourceUserMembershipsSetApplied(d, remainingGroupIds)...)
+	}
+	return diags
 }
@@ -0,0 +1,336 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// usersResource implements lldap_users, which manages many users with a
+// single bulk read and batched mutations instead of one resource per user.
+type usersResource struct {
+	client *LldapClient
+}
+
+type usersResourceModel struct {
+	Authoritative      types.Bool                `tfsdk:"authoritative"`
+	DeletionProtection types.Bool                `tfsdk:"deletion_protection"`
+	Id                 types.String              `tfsdk:"id"`
+	Results            types.Map                 `tfsdk:"results"`
+	Users              map[string]usersUserModel `tfsdk:"users"`
+}
+
+type usersUserModel struct {
+	Avatar      types.String `tfsdk:"avatar"`
+	DisplayName types.String `tfsdk:"display_name"`
+	Email       types.String `tfsdk:"email"`
+	FirstName   types.String `tfsdk:"first_name"`
+	LastName    types.String `tfsdk:"last_name"`
+}
+
+var (
+	_ resource.ResourceWithConfigure      = &usersResource{}
+	_ resource.ResourceWithModifyPlan     = &usersResource{}
+	_ resource.ResourceWithValidateConfig = &usersResource{}
+)
+
+func NewUsersResource() resource.Resource {
+	return &usersResource{}
+}
+
+func (r *usersResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_users"
+}
+
+func (r *usersResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	optionalString := func(description string) schema.StringAttribute {
+		return schema.StringAttribute{
+			Optional:    true,
+			Computed:    true,
+			Default:     stringdefault.StaticString(""),
+			Description: description,
+		}
+	}
+	resp.Schema = schema.Schema{
+		Description: "Manages many LLDAP users at once, using a single bulk read and batched mutations. Passwords, groups and custom attributes are not managed by this resource",
+		Attributes: map[string]schema.Attribute{
+			"authoritative": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Delete all users that are not part of `users`, except the provider's own `username` and, with `reconcile_managed_only`, users that are not managed by Terraform (default: `false`). Otherwise only users removed from `users` are deleted",
+			},
+			"deletion_protection": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Prevents Terraform from deleting any of these users while `true`, defaults to the provider's `deletion_protection` setting",
+			},
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "ID representing this set of users",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"results": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Changes applied by the last apply, `created`, `updated` or `deleted` by user id",
+			},
+			"users": schema.MapNestedAttribute{
+				Required:    true,
+				Description: "Users by their lower case user id. Existing users are adopted instead of created",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"avatar":       optionalString("Base 64 encoded JPEG image"),
+						"display_name": optionalString("Display name of this user"),
+						"email": schema.StringAttribute{
+							Required:    true,
+							Description: "The unique user email",
+						},
+						"first_name": optionalString("First name of this user"),
+						"last_name":  optionalString("Last name of this user"),
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *usersResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	client, clientDiags := frameworkClient(req.ProviderData)
+	resp.Diagnostics.Append(clientDiags...)
+	r.client = client
+}
+
+func (r *usersResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var users types.Map
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("users"), &users)...)
+	if resp.Diagnostics.HasError() || users.IsNull() || users.IsUnknown() {
+		return
+	}
+	for userId := range users.Elements() {
+		if userId != strings.ToLower(userId) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("users").AtMapKey(userId),
+				"Invalid user id",
+				fmt.Sprintf("LLDAP user ids are lower case, use '%s' instead of '%s'", strings.ToLower(userId), userId),
+			)
+		}
+	}
+}
+
+// ModifyPlan refuses plans deleting the provider user and applies the provider default if
+// deletion_protection is not configured. The effective value is kept in state, because the
+// configuration is no longer available on delete.
+func (r *usersResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+	if lc, guarded := lockoutGuard(r.client); guarded && !req.State.Raw.IsNull() {
+		var priorUsers, plannedUsers types.Map
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("users"), &priorUsers)...)
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("users"), &plannedUsers)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !plannedUsers.IsUnknown() {
+			lockoutErr := lc.checkUsersRemoval(slices.Collect(maps.Keys(priorUsers.Elements())), slices.Collect(maps.Keys(plannedUsers.Elements())))
+			if lockoutErr != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("users"), "Refusing to delete the provider user", lockoutErr.Error())
+				return
+			}
+		}
+	}
+	var deletionProtection types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("deletion_protection"), &deletionProtection)...)
+	if resp.Diagnostics.HasError() || !deletionProtection.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("deletion_protection"), r.client.Config.DeletionProtection)...)
+}
+
+func (r *usersResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly("Creating lldap_users"))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var plan usersResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.Id = types.StringValue("users")
+	r.apply(map[string]usersUserModel{}, &plan, plan.DeletionProtection.ValueBool(), &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *usersResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state usersResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	reconcileManagedOnly := state.Authoritative.ValueBool() && r.client.reconcileManagedOnly()
+	// Attributes are only needed to find the managed users
+	getUsers := r.client.GetUsers
+	if reconcileManagedOnly {
+		getUsers = r.client.GetUsersWithAttributes
+	}
+	currentUsers, getUsersErr := getUsers()
+	resp.Diagnostics.Append(frameworkDiagnostics(getUsersErr)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	users := map[string]usersUserModel{}
+	for _, user := range currentUsers {
+		_, inState := state.Users[user.Id]
+		if !inState {
+			if !state.Authoritative.ValueBool() {
+				continue
+			}
+			if _, guarded := lockoutGuard(r.client); guarded && r.client.isProviderUser(user.Id) {
+				continue
+			}
+			if reconcileManagedOnly && !IsManaged(user.Attributes, r.client.Config.ManagedMarker) {
+				continue
+			}
+		}
+		users[user.Id] = usersUserModelFromUser(&user)
+	}
+	state.Users = users
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+func (r *usersResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly("Updating lldap_users"))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var plan, state usersResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(state.Users, &plan, state.DeletionProtection.ValueBool(), &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *usersResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(frameworkDiagnostics(r.client.checkReadOnly("Deleting lldap_users"))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var state usersResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	target := state
+	target.Users = map[string]usersUserModel{}
+	r.apply(state.Users, &target, state.DeletionProtection.ValueBool(), &resp.Diagnostics)
+}
+
+// apply turns the users in prior into the users in target. Users that could not be changed are
+// reverted to their current values in target, so the next plan retries them.
+// Deletions are refused while deletionProtection, the value from before the apply, is set.
+func (r *usersResource) apply(prior map[string]usersUserModel, target *usersResourceModel, deletionProtection bool, diags *fwdiag.Diagnostics) {
+	target.Results = types.MapNull(types.StringType)
+	currentUsers, getUsersErr := r.client.GetUsers()
+	diags.Append(frameworkDiagnostics(getUsersErr)...)
+	if diags.HasError() {
+		target.Users = prior
+		return
+	}
+	desired := map[string]LldapUser{}
+	for userId, user := range target.Users {
+		desired[userId] = user.toUser(userId)
+	}
+	changes := DiffBulkUsers(desired, slices.Collect(maps.Keys(prior)), currentUsers)
+	for _, change := range changes {
+		if change.Action != BulkUserDelete {
+			continue
+		}
+		if deletionProtection {
+			diags.Append(frameworkDiagnostics(deletionProtectionError("user", change.User.Id))...)
+		}
+		if _, guarded := lockoutGuard(r.client); guarded && r.client.isProviderUser(change.User.Id) {
+			diags.AddAttributeError(
+				path.Root("users").AtMapKey(change.User.Id),
+				"Refusing to delete the provider user",
+				r.client.lockoutError(fmt.Sprintf("Deleting user '%s'", change.User.Id)).Error(),
+			)
+		}
+	}
+	if diags.HasError() {
+		target.Users = prior
+		return
+	}
+	currentById := map[string]*LldapUser{}
+	for i := range currentUsers {
+		currentById[currentUsers[i].Id] = &currentUsers[i]
+	}
+	results := map[string]attr.Value{}
+	for _, result := range r.client.ApplyUserChanges(changes) {
+		if result.Err == nil {
+			results[result.UserId] = types.StringValue(string(result.Action) + "d")
+			continue
+		}
+		for _, d := range result.Err {
+			diags.AddAttributeError(
+				path.Root("users").AtMapKey(result.UserId),
+				fmt.Sprintf("Could not %s user '%s'", result.Action, result.UserId),
+				d.Summary,
+			)
+		}
+		if currentUser, exists := currentById[result.UserId]; exists {
+			target.Users[result.UserId] = usersUserModelFromUser(currentUser)
+		} else {
+			delete(target.Users, result.UserId)
+		}
+	}
+	resultsValue, resultsDiags := types.MapValue(types.StringType, results)
+	diags.Append(resultsDiags...)
+	target.Results = resultsValue
+}
+
+func usersUserModelFromUser(user *LldapUser) usersUserModel {
+	return usersUserModel{
+		Avatar:      types.StringValue(user.Avatar),
+		DisplayName: types.StringValue(user.DisplayName),
+		Email:       types.StringValue(user.Email),
+		FirstName:   types.StringValue(user.FirstName),
+		LastName:    types.StringValue(user.LastName),
+	}
+}
+
+func (m usersUserModel) toUser(userId string) LldapUser {
+	return LldapUser{
+		Id:          userId,
+		Avatar:      m.Avatar.ValueString(),
+		DisplayName: m.DisplayName.ValueString(),
+		Email:       m.Email.ValueString(),
+		FirstName:   m.FirstName.ValueString(),
+		LastName:    m.LastName.ValueString(),
+	}
+}
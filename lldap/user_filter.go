@@ -0,0 +1,200 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	ldap "github.com/go-ldap/ldap/v3"
+)
+
+// Common LDAP attribute names and their LLDAP counterparts
+var userFilterAttributeAliases = map[string]string{
+	"cn":              "display_name",
+	"createtimestamp": "creation_date",
+	"displayname":     "display_name",
+	"email":           "mail",
+	"entryuuid":       "uuid",
+	"givenname":       "first_name",
+	"id":              "user_id",
+	"sn":              "last_name",
+	"uid":             "user_id",
+}
+
+// LldapUserFilter selects users with an LDAP search filter (RFC 4515), e.g.
+// `(&(department=engineering)(!(mail=*@contractor.test)))`. The filter is
+// evaluated by the client against user fields and custom attributes, so it
+// works for every attribute LLDAP knows about.
+type LldapUserFilter struct {
+	Expression string
+	packet     *ber.Packet
+}
+
+func ParseUserFilter(expression string) (*LldapUserFilter, error) {
+	packet, compileErr := ldap.CompileFilter(expression)
+	if compileErr != nil {
+		return nil, fmt.Errorf("invalid filter '%s': %s", expression, compileErr)
+	}
+	if checkErr := checkUserFilterPacket(packet); checkErr != nil {
+		return nil, fmt.Errorf("invalid filter '%s': %s", expression, checkErr)
+	}
+	return &LldapUserFilter{
+		Expression: expression,
+		packet:     packet,
+	}, nil
+}
+
+func checkUserFilterPacket(packet *ber.Packet) error {
+	switch packet.Tag {
+	case ldap.FilterAnd, ldap.FilterOr, ldap.FilterNot:
+		for _, child := range packet.Children {
+			if checkErr := checkUserFilterPacket(child); checkErr != nil {
+				return checkErr
+			}
+		}
+		return nil
+	case ldap.FilterEqualityMatch, ldap.FilterSubstrings, ldap.FilterGreaterOrEqual,
+		ldap.FilterLessOrEqual, ldap.FilterPresent, ldap.FilterApproxMatch:
+		return nil
+	}
+	return fmt.Errorf("unsupported filter type: %s", ldap.FilterMap[uint64(packet.Tag)])
+}
+
+// Matches reports whether the user is selected by this filter.
+func (f *LldapUserFilter) Matches(user *LldapUser) bool {
+	return matchUserFilterPacket(f.packet, userFilterAttributes(user))
+}
+
+// MatchingUserIds returns the sorted ids of all users selected by this filter.
+func (f *LldapUserFilter) MatchingUserIds(users []LldapUser) []string {
+	result := make([]string, 0)
+	for _, user := range users {
+		if f.Matches(&user) {
+			result = append(result, user.Id)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+func userFilterAttributes(user *LldapUser) map[string][]string {
+	result := map[string][]string{
+		"avatar":        {user.Avatar},
+		"creation_date": {user.CreationDate},
+		"display_name":  {user.DisplayName},
+		"first_name":    {user.FirstName},
+		"last_name":     {user.LastName},
+		"mail":          {user.Email},
+		"user_id":       {user.Id},
+		"uuid":          {user.Uuid},
+	}
+	for _, attr := range user.Attributes {
+		result[strings.ToLower(attr.Name)] = attr.Value
+	}
+	return result
+}
+
+func userFilterAttributeValues(attributes map[string][]string, name string) []string {
+	name = strings.ToLower(name)
+	if alias, isAlias := userFilterAttributeAliases[name]; isAlias {
+		name = alias
+	}
+	values := make([]string, 0)
+	for _, value := range attributes[name] {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func matchUserFilterPacket(packet *ber.Packet, attributes map[string][]string) bool {
+	switch packet.Tag {
+	case ldap.FilterAnd:
+		for _, child := range packet.Children {
+			if !matchUserFilterPacket(child, attributes) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range packet.Children {
+			if matchUserFilterPacket(child, attributes) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !matchUserFilterPacket(packet.Children[0], attributes)
+	case ldap.FilterPresent:
+		return len(userFilterAttributeValues(attributes, packet.Data.String())) > 0
+	case ldap.FilterSubstrings:
+		values := userFilterAttributeValues(attributes, packet.Children[0].Data.String())
+		return slices.ContainsFunc(values, func(value string) bool {
+			return matchUserFilterSubstrings(packet.Children[1].Children, value)
+		})
+	}
+	values := userFilterAttributeValues(attributes, packet.Children[0].Data.String())
+	assertion := packet.Children[1].Data.String()
+	return slices.ContainsFunc(values, func(value string) bool {
+		switch packet.Tag {
+		case ldap.FilterGreaterOrEqual:
+			return compareUserFilterValues(value, assertion) >= 0
+		case ldap.FilterLessOrEqual:
+			return compareUserFilterValues(value, assertion) <= 0
+		}
+		// Equality and approximate match, case-insensitive like LDAP
+		return strings.EqualFold(value, assertion)
+	})
+}
+
+func matchUserFilterSubstrings(substrings []*ber.Packet, value string) bool {
+	rest := strings.ToLower(value)
+	for _, substring := range substrings {
+		part := strings.ToLower(substring.Data.String())
+		switch substring.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(rest, part) {
+				return false
+			}
+			rest = rest[len(part):]
+		case ldap.FilterSubstringsFinal:
+			if !strings.HasSuffix(rest, part) {
+				return false
+			}
+			rest = ""
+		default:
+			index := strings.Index(rest, part)
+			if index < 0 {
+				return false
+			}
+			rest = rest[index+len(part):]
+		}
+	}
+	return true
+}
+
+// compareUserFilterValues compares integers numerically, everything else
+// (including RFC 3339 timestamps) lexicographically.
+func compareUserFilterValues(a string, b string) int {
+	intA, errA := strconv.ParseInt(a, 10, 64)
+	intB, errB := strconv.ParseInt(b, 10, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case intA < intB:
+			return -1
+		case intA > intB:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
@@ -0,0 +1,70 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testUserFilterUsers() []LldapUser {
+	return []LldapUser{
+		{
+			Id:          "alice",
+			Email:       "alice@corp.test",
+			DisplayName: "Alice",
+			Attributes: []LldapCustomAttribute{
+				{Name: "department", Value: []string{"Engineering"}},
+				{Name: "level", Value: []string{"12"}},
+			},
+		},
+		{
+			Id:    "bob",
+			Email: "bob@contractor.test",
+			Attributes: []LldapCustomAttribute{
+				{Name: "department", Value: []string{"engineering", "sales"}},
+				{Name: "level", Value: []string{"3"}},
+			},
+		},
+		{
+			Id:    "carol",
+			Email: "carol@corp.test",
+		},
+	}
+}
+
+func TestUserFilterMatchingUserIds(t *testing.T) {
+	users := testUserFilterUsers()
+	for expression, expected := range map[string][]string{
+		"(department=engineering)":                               {"alice", "bob"},
+		"(&(department=engineering)(!(mail=*@contractor.test)))": {"alice"},
+		"(|(uid=carol)(department=sales))":                       {"bob", "carol"},
+		"(department=*)":                                         {"alice", "bob"},
+		"(!(department=*))":                                      {"carol"},
+		"(level>=10)":                                            {"alice"},
+		"(level<=10)":                                            {"bob"},
+		"(email=*corp*)":                                         {"alice", "carol"},
+		"(cn=ali*)":                                              {"alice"},
+		"(user_id=nobody)":                                       {},
+	} {
+		filter, parseErr := ParseUserFilter(expression)
+		assert.Nil(t, parseErr, expression)
+		assert.Equal(t, expected, filter.MatchingUserIds(users), expression)
+	}
+}
+
+func TestParseUserFilterErr(t *testing.T) {
+	for _, expression := range []string{
+		"department=engineering",
+		"(department=engineering",
+		"(department:caseExactMatch:=Engineering)",
+	} {
+		_, parseErr := ParseUserFilter(expression)
+		assert.NotNil(t, parseErr, expression)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package lldap
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+const (
+	// RenameStrategyRecreate replaces a renamed user, the LLDAP default as there is no rename mutation.
+	RenameStrategyRecreate = "recreate"
+	// RenameStrategyMigrate copies a renamed user to the new username and deletes the old one.
+	RenameStrategyMigrate = "migrate"
+)
+
+var RenameStrategies = []string{RenameStrategyRecreate, RenameStrategyMigrate}
+
+// renameEmail is the placeholder email of the old user while it is migrated, as emails are unique.
+func renameEmail(oldId string, newId string) string {
+	return fmt.Sprintf("%s.renamed-to.%s@rename.invalid", oldId, newId)
+}
+
+// RenameUser migrates a user to a new username, as LLDAP has no rename mutation: it creates the new user
+// with the profile fields, custom attributes and group memberships of the old one, sets the password if it
+// is known, and deletes the old user. The new user gets a new UUID and creation date. If the migration
+// fails before the old user is deleted, the new user is deleted again and the old one is left as it was.
+func (lc *LldapClient) RenameUser(oldId string, newId string, password string) (*LldapUser, diag.Diagnostics) {
+	if lc.isProviderUser(oldId) {
+		return nil, diag.Errorf("Renaming user '%s' is not possible, the provider authenticates as this user", oldId)
+	}
+	oldUser, getUserErr := lc.GetUser(oldId)
+	if getUserErr != nil {
+		return nil, getUserErr
+	}
+	if password != "" {
+		if policyErr := lc.ValidatePassword(password, newId, oldUser.Email); policyErr != nil {
+			return nil, diag.FromErr(policyErr)
+		}
+	}
+	// Free the unique email for the new user
+	email := oldUser.Email
+	oldUser.Email = renameEmail(oldId, newId)
+	if freeEmailErr := lc.updateUser(oldUser, nil, nil); freeEmailErr != nil {
+		return nil, prefixDiagnostics(fmt.Sprintf("Renaming user '%s' to '%s'", oldId, newId), freeEmailErr)
+	}
+	oldUser.Email = email
+
+	newUser, migrateErr := lc.migrateUser(oldUser, newId, password)
+	if migrateErr != nil {
+		if restoreErr := lc.updateUser(oldUser, nil, nil); restoreErr != nil {
+			migrateErr = append(migrateErr, restoreErr...)
+		}
+		return nil, prefixDiagnostics(fmt.Sprintf("Renaming user '%s' to '%s'", oldId, newId), migrateErr)
+	}
+	if deleteErr := lc.DeleteUser(oldId); deleteErr != nil {
+		return newUser, prefixDiagnostics(fmt.Sprintf("Renamed user '%s' to '%s', but deleting the old user failed", oldId, newId), deleteErr)
+	}
+	return newUser, nil
+}
+
+// migrateUser creates newId as a copy of the user, and deletes it again if copying fails.
+func (lc *LldapClient) migrateUser(oldUser *LldapUser, newId string, password string) (*LldapUser, diag.Diagnostics) {
+	newUser := LldapUser{
+		Id:          newId,
+		Email:       oldUser.Email,
+		DisplayName: oldUser.DisplayName,
+		FirstName:   oldUser.FirstName,
+		LastName:    oldUser.LastName,
+		Avatar:      oldUser.Avatar,
+	}
+	if createErr := lc.CreateUser(&newUser); createErr != nil {
+		return nil, createErr
+	}
+	copyErr := lc.copyUser(oldUser, &newUser, password)
+	if copyErr != nil {
+		if deleteErr := lc.DeleteUser(newId); deleteErr != nil {
+			log.Printf("Error deleting user '%s' after a failed rename: %s", newId, deleteErr[0].Summary)
+		}
+		return nil, copyErr
+	}
+	return &newUser, nil
+}
+
+func (lc *LldapClient) copyUser(oldUser *LldapUser, newUser *LldapUser, password string) diag.Diagnostics {
+	attributes, attributesErr := lc.UserCustomAttributes(oldUser)
+	if attributesErr != nil {
+		return attributesErr
+	}
+	if len(attributes) > 0 {
+		if attributesErr := lc.updateUser(newUser, nil, attributes); attributesErr != nil {
+			return attributesErr
+		}
+		newUser.Attributes = append(newUser.Attributes, attributes...)
+	}
+	addedGroupIds, addErr := lc.AddUserToGroups(oldUser.GetGroupIds(), newUser.Id)
+	if addErr != nil {
+		return addErr
+	}
+	for _, group := range oldUser.Groups {
+		if slices.Contains(addedGroupIds, group.Id) {
+			newUser.Groups = append(newUser.Groups, group)
+		}
+	}
+	if password != "" {
+		if setPwErr := lc.SetUserPassword(newUser.Id, password); setPwErr != nil {
+			return setPwErr
+		}
+		newUser.Password = password
+	}
+	return nil
+}
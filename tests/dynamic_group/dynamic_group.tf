@@ -0,0 +1,77 @@
+terraform {
+  required_providers {
+    lldap = {
+      source  = "tasansga/lldap"
+      version = "0.0.1"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "3.6.3"
+    }
+  }
+}
+
+variable "lldap_http_url" {}
+variable "lldap_ldap_url" {}
+variable "lldap_username" {}
+variable "lldap_password" {}
+variable "lldap_base_dn" {}
+
+variable "department" {
+  default = "engineering"
+}
+
+provider "lldap" {
+  http_url = var.lldap_http_url
+  ldap_url = var.lldap_ldap_url
+  username = var.lldap_username
+  password = var.lldap_password
+  base_dn  = var.lldap_base_dn
+}
+
+resource "random_string" "random" {
+  length  = 8
+  special = false
+  upper   = false
+}
+
+resource "lldap_user_attribute" "department" {
+  name           = "department-${random_string.random.result}"
+  attribute_type = "STRING"
+}
+
+resource "lldap_user" "user" {
+  for_each = toset(["engineering", "sales"])
+  username = "user-${each.key}-${random_string.random.result}"
+  email    = "user-${each.key}-${random_string.random.result}@this.test"
+}
+
+resource "lldap_user_attribute_assignment" "department" {
+  for_each     = lldap_user.user
+  user_id      = each.value.id
+  attribute_id = lldap_user_attribute.department.id
+  value        = [each.key]
+}
+
+resource "lldap_group" "group" {
+  display_name = "Dynamic group ${random_string.random.result}"
+}
+
+resource "lldap_dynamic_group" "group" {
+  group_id = lldap_group.group.id
+  filter   = "(${lldap_user_attribute.department.id}=${var.department})"
+
+  depends_on = [lldap_user_attribute_assignment.department]
+}
+
+output "members_match" {
+  value = lldap_dynamic_group.group.members == toset([lldap_user.user[var.department].id])
+}
+
+output "group_id" {
+  value = lldap_group.group.id
+}
+
+output "engineering_user_id" {
+  value = lldap_user.user["engineering"].id
+}
@@ -0,0 +1,31 @@
+#!/usr/bin/env bash
+
+set -exo pipefail
+
+echo "=== Dynamic Group Lifecycle Test ==="
+
+echo "=== Test Create ==="
+tofu apply -auto-approve
+test "$(tofu output -raw members_match)" == "true"
+tofu plan -detailed-exitcode
+
+echo "=== Test Update (filter change) ==="
+tofu apply -auto-approve -var department=sales
+test "$(tofu output -raw members_match)" == "true"
+tofu plan -detailed-exitcode -var department=sales
+
+echo "=== Test Out-of-Band Changes with lldap-cli ==="
+export LLDAP_BASE_DN="dc=terraform-provider-lldap,dc=tasansga,dc=github,dc=com"
+export LLDAP_HTTP_URL="http://${LLDAP_HOST}:${LLDAP_PORT_HTTP}"
+export LLDAP_LDAP_URL="ldap://${LLDAP_HOST}:${LLDAP_PORT_LDAP}"
+export LLDAP_USER="admin"
+../../dist/lldap-cli member add "$(tofu output -raw group_id)" "$(tofu output -raw engineering_user_id)"
+# The plan must show the user that no longer matches being removed
+tofu plan -detailed-exitcode -var department=sales && exit 1 || test $? -eq 2
+tofu apply -auto-approve -var department=sales
+test "$(tofu output -raw members_match)" == "true"
+
+echo "=== Test Delete ==="
+tofu apply -auto-approve -destroy -var department=sales
+
+echo "=== All dynamic group tests completed successfully! ==="
@@ -0,0 +1,46 @@
+terraform {
+  required_providers {
+    lldap = {
+      source  = "tasansga/lldap"
+      version = "0.0.1"
+    }
+  }
+}
+
+variable "lldap_http_url" {}
+variable "lldap_ldap_url" {}
+variable "lldap_username" {}
+variable "lldap_password" {}
+variable "lldap_base_dn" {}
+
+provider "lldap" {
+  http_url = var.lldap_http_url
+  ldap_url = var.lldap_ldap_url
+  username = var.lldap_username
+  password = var.lldap_password
+  base_dn  = var.lldap_base_dn
+}
+
+resource "lldap_group" "special" {
+  display_name = "R&D, functions+test"
+}
+
+output "user_dn" {
+  value = provider::lldap::user_dn(var.lldap_username, var.lldap_base_dn)
+}
+
+output "group_dn" {
+  value = provider::lldap::group_dn(lldap_group.special.display_name, var.lldap_base_dn)
+}
+
+output "escaped_dn_value" {
+  value = provider::lldap::escape_dn_value(" #a,b ")
+}
+
+output "escaped_filter" {
+  value = provider::lldap::escape_filter("a*(b)\\")
+}
+
+output "member_of_filter" {
+  value = provider::lldap::member_of_filter(["lldap_admin", lldap_group.special.display_name], var.lldap_base_dn)
+}
@@ -0,0 +1,24 @@
+#!/usr/bin/env bash
+
+set -exo pipefail
+
+echo "=== Provider Functions Test ==="
+tofu apply -auto-approve
+
+BASE_DN="dc=terraform-provider-lldap,dc=tasansga,dc=github,dc=com"
+test "$(tofu output -raw user_dn)" == "uid=admin,ou=people,${BASE_DN}"
+test "$(tofu output -raw group_dn)" == "cn=R&D\\, functions\\+test,ou=groups,${BASE_DN}"
+test "$(tofu output -raw escaped_dn_value)" == "\\ #a\\,b\\ "
+test "$(tofu output -raw escaped_filter)" == "a\\2a\\28b\\29\\5c"
+test "$(tofu output -raw member_of_filter)" == "(|(memberOf=cn=lldap_admin,ou=groups,${BASE_DN})(memberOf=cn=R&D\\5c, functions\\5c+test,ou=groups,${BASE_DN}))"
+
+echo "=== Test Invalid Arguments ==="
+if tofu console <<< 'provider::lldap::member_of_filter([], "dc=example,dc=com")'
+then
+    echo "member_of_filter must refuse an empty list of groups"
+    exit 1
+fi
+
+tofu apply -auto-approve -destroy
+
+echo "=== All provider function tests completed successfully! ==="
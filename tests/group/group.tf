@@ -17,12 +17,17 @@ variable "lldap_username" {}
 variable "lldap_password" {}
 variable "lldap_base_dn" {}
 
+variable "deletion_protection" {
+  default = false
+}
+
 provider "lldap" {
-  http_url = var.lldap_http_url
-  ldap_url = var.lldap_ldap_url
-  username = var.lldap_username
-  password = var.lldap_password
-  base_dn  = var.lldap_base_dn
+  http_url            = var.lldap_http_url
+  ldap_url            = var.lldap_ldap_url
+  username            = var.lldap_username
+  password            = var.lldap_password
+  base_dn             = var.lldap_base_dn
+  deletion_protection = var.deletion_protection
 }
 
 # Variable for controlling group count
@@ -113,6 +118,11 @@ data "lldap_groups" "all_groups" {
   depends_on = [lldap_group.test_groups, lldap_group.group_with_attrs, lldap_group.group_with_members]
 }
 
+data "lldap_group" "by_display_name" {
+  count        = var.group_count > 0 ? 1 : 0
+  display_name = lldap_group.test_groups[0].display_name
+}
+
 data "lldap_group_attributes" "all_group_attrs" {
   depends_on = [lldap_group_attribute.test_attr]
 }
@@ -126,6 +136,10 @@ output "created_groups" {
   value = lldap_group.test_groups
 }
 
+output "group_by_display_name_matches" {
+  value = alltrue([for group in data.lldap_group.by_display_name : tostring(group.id) == lldap_group.test_groups[0].id])
+}
+
 output "all_groups" {
   value = data.lldap_groups.all_groups.groups
 }
@@ -6,6 +6,24 @@ echo "=== Basic Group Lifecycle Test ==="
 tofu apply -auto-approve
 tofu apply -auto-approve -destroy
 
+echo "=== Test Group Lookup and Import by Display Name ==="
+tofu apply -auto-approve -var group_count=1
+test "$(tofu output -raw group_by_display_name_matches)" == "true"
+display_name="$(tofu output -json created_groups | jq -r '.[0].display_name')"
+tofu state rm 'lldap_group.test_groups[0]'
+tofu import -var group_count=1 'lldap_group.test_groups[0]' "name:${display_name}"
+tofu plan -detailed-exitcode -var group_count=1 -target='lldap_group.test_groups[0]'
+tofu apply -auto-approve -destroy -var group_count=1
+
+echo "=== Test Deletion Protection ==="
+tofu apply -auto-approve -var group_count=1 -var deletion_protection=true
+if tofu apply -auto-approve -destroy -var group_count=1 -var deletion_protection=true; then
+  echo "Expected destroy to fail because of deletion protection"
+  exit 1
+fi
+tofu apply -auto-approve -var group_count=1
+tofu apply -auto-approve -destroy -var group_count=1
+
 echo "=== Test Group Count Scaling ==="
 # Start with 0 groups
 tofu apply -auto-approve -var group_count=0
@@ -0,0 +1,118 @@
+terraform {
+  required_providers {
+    lldap = {
+      source  = "tasansga/lldap"
+      version = "0.0.1"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "3.6.3"
+    }
+  }
+}
+
+variable "lldap_http_url" {}
+variable "lldap_ldap_url" {}
+variable "lldap_username" {}
+variable "lldap_password" {}
+variable "lldap_base_dn" {}
+
+variable "include_dbas" {
+  default = true
+}
+
+variable "create_cycle" {
+  default = false
+}
+
+provider "lldap" {
+  http_url = var.lldap_http_url
+  ldap_url = var.lldap_ldap_url
+  username = var.lldap_username
+  password = var.lldap_password
+  base_dn  = var.lldap_base_dn
+}
+
+resource "random_string" "random" {
+  length  = 8
+  special = false
+  upper   = false
+}
+
+resource "lldap_group" "group" {
+  for_each     = toset(["platform", "sre", "dbas", "oncall"])
+  display_name = "${each.key}-${random_string.random.result}"
+}
+
+resource "lldap_user" "user" {
+  for_each = toset(["sre", "dbas", "oncall"])
+  username = "user-${each.key}-${random_string.random.result}"
+  email    = "user-${each.key}-${random_string.random.result}@this.test"
+}
+
+resource "lldap_member" "member" {
+  for_each = lldap_user.user
+  group_id = lldap_group.group[each.key].id
+  user_id  = each.value.id
+}
+
+# direct is a member of dbas and a direct member of platform already
+resource "lldap_user" "direct" {
+  username = "user-direct-${random_string.random.result}"
+  email    = "user-direct-${random_string.random.result}@this.test"
+}
+
+resource "lldap_member" "direct" {
+  for_each = toset(["platform", "dbas"])
+  group_id = lldap_group.group[each.key].id
+  user_id  = lldap_user.direct.id
+}
+
+# oncall is nested in sre, which is nested in platform
+resource "lldap_group_inclusion" "sre" {
+  parent_group_id = lldap_group.group["sre"].id
+  child_group_ids = [lldap_group.group["oncall"].id]
+
+  depends_on = [lldap_member.member]
+}
+
+resource "lldap_group_inclusion" "platform" {
+  parent_group_id = lldap_group.group["platform"].id
+  child_group_ids = concat(
+    [lldap_group.group["sre"].id],
+    var.include_dbas ? [lldap_group.group["dbas"].id] : [],
+  )
+
+  depends_on = [lldap_member.member, lldap_member.direct, lldap_group_inclusion.sre]
+}
+
+resource "lldap_group_inclusion" "cycle" {
+  count           = var.create_cycle ? 1 : 0
+  parent_group_id = lldap_group.group["oncall"].id
+  child_group_ids = [lldap_group.group["platform"].id]
+}
+
+data "lldap_group_inclusion" "platform" {
+  group_id = lldap_group.group["platform"].id
+
+  depends_on = [lldap_group_inclusion.platform]
+}
+
+output "platform_members_match" {
+  value = data.lldap_group_inclusion.platform.effective_members == toset(concat(
+    [for key, user in lldap_user.user : user.id if var.include_dbas || key != "dbas"],
+    [lldap_user.direct.id],
+  ))
+}
+
+output "platform_inclusion_skips_direct_member" {
+  value = !contains(lldap_group_inclusion.platform.members, lldap_user.direct.id)
+}
+
+output "platform_group_id" {
+  value = lldap_group.group["platform"].id
+}
+
+output "direct_user_id" {
+  value = lldap_user.direct.id
+}
@@ -0,0 +1,31 @@
+#!/usr/bin/env bash
+
+set -exo pipefail
+
+echo "=== Group Inclusion Lifecycle Test ==="
+
+echo "=== Test Create ==="
+tofu apply -auto-approve
+test "$(tofu output -raw platform_members_match)" == "true"
+test "$(tofu output -raw platform_inclusion_skips_direct_member)" == "true"
+tofu plan -detailed-exitcode
+
+echo "=== Test Update (remove child group) ==="
+tofu apply -auto-approve -var include_dbas=false
+test "$(tofu output -raw platform_members_match)" == "true"
+tofu plan -detailed-exitcode -var include_dbas=false
+
+echo "=== Test Cycle Detection ==="
+if tofu plan -var create_cycle=true; then
+  echo "Expected plan to fail because of the inclusion cycle"
+  exit 1
+fi
+
+echo "=== Test Delete keeps direct members ==="
+tofu apply -auto-approve -destroy -target lldap_group_inclusion.platform -var include_dbas=false
+../../dist/lldap-cli group get "$(tofu output -raw platform_group_id)" | jq -e --arg user "$(tofu output -raw direct_user_id)" '.users[] | select(.id == $user)'
+
+echo "=== Test Delete ==="
+tofu apply -auto-approve -destroy
+
+echo "=== All group inclusion tests completed successfully! ==="
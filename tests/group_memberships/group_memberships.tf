@@ -25,7 +25,7 @@ variable "max_users" {
   default = 10
 }
 
-variable "enable_out_of_band_user" {
+variable "enable_conflicting_member" {
   default = false
 }
 
@@ -35,6 +35,8 @@ provider "lldap" {
   username = var.lldap_username
   password = var.lldap_password
   base_dn  = var.lldap_base_dn
+
+  max_concurrent_requests = 8
 }
 
 resource "random_string" "random" {
@@ -70,8 +72,9 @@ resource "lldap_user" "out_of_band" {
   last_name    = "LAST"
 }
 
-resource "lldap_member" "out_of_band" {
-  count    = var.enable_out_of_band_user ? 1 : 0
+# Conflicts with lldap_group_memberships, which manages all members of the group
+resource "lldap_member" "conflicting" {
+  count    = var.enable_conflicting_member ? 1 : 0
   group_id = lldap_group.group.id
   user_id  = lldap_user.out_of_band.id
 }
@@ -84,7 +87,7 @@ resource "lldap_group_memberships" "group" {
 # Data sources for verification
 data "lldap_group" "test_group" {
   id = lldap_group.group.id
-  depends_on = [lldap_group_memberships.group, lldap_member.out_of_band]
+  depends_on = [lldap_group_memberships.group, lldap_member.conflicting]
 }
 
 data "lldap_groups" "all_groups" {
@@ -107,6 +110,10 @@ output "group_memberships" {
   value = lldap_group_memberships.group
 }
 
+output "group_id" {
+  value = lldap_group.group.id
+}
+
 output "test_group" {
   value = lldap_group.group
 }
@@ -138,8 +145,12 @@ output "out_of_band_user" {
   sensitive = true
 }
 
-output "out_of_band_member" {
-  value = var.enable_out_of_band_user ? lldap_member.out_of_band[0] : null
+output "out_of_band_user_id" {
+  value = lldap_user.out_of_band.id
+}
+
+output "conflicting_member" {
+  value = var.enable_conflicting_member ? lldap_member.conflicting[0] : null
 }
 
 output "num_users" {
@@ -31,8 +31,19 @@ done
 
 echo "=== Test Out-of-Band Changes ==="
 tofu apply -auto-approve
-tofu apply -auto-approve -var enable_out_of_band_user=true
+../../dist/lldap-cli member add "$(tofu output -raw group_id)" "$(tofu output -raw out_of_band_user_id)"
+if tofu plan -detailed-exitcode; then
+  echo "Expected plan to remove the out-of-band member"
+  exit 1
+fi
 tofu apply -auto-approve
+tofu plan -detailed-exitcode
+
+echo "=== Test Conflicting lldap_member Is Refused ==="
+if tofu plan -var enable_conflicting_member=true; then
+  echo "Expected plan to fail because lldap_member conflicts with lldap_group_memberships"
+  exit 1
+fi
 tofu apply -auto-approve -destroy
 
 echo "=== Test Member Count Variations ==="
@@ -0,0 +1,73 @@
+terraform {
+  required_providers {
+    lldap = {
+      source  = "tasansga/lldap"
+      version = "0.0.1"
+    }
+  }
+}
+
+variable "lldap_http_url" {}
+variable "lldap_ldap_url" {}
+variable "lldap_username" {}
+variable "lldap_password" {}
+variable "lldap_base_dn" {}
+
+provider "lldap" {
+  http_url = var.lldap_http_url
+  ldap_url = var.lldap_ldap_url
+  username = var.lldap_username
+  password = var.lldap_password
+  base_dn  = var.lldap_base_dn
+}
+
+resource "lldap_user" "app_user" {
+  username = "ldap-search-app-user"
+  email    = "ldap-search-app-user@example.com"
+}
+
+resource "lldap_user" "other_user" {
+  username = "ldap-search-other-user"
+  email    = "ldap-search-other-user@example.com"
+}
+
+resource "lldap_group" "app_users" {
+  display_name = "App users, LDAP search"
+}
+
+resource "lldap_member" "app_user" {
+  group_id = lldap_group.app_users.id
+  user_id  = lldap_user.app_user.id
+}
+
+data "lldap_ldap_search" "app_users" {
+  base_dn    = "ou=people,${var.lldap_base_dn}"
+  scope      = "one"
+  filter     = "(&(objectClass=person)${provider::lldap::member_of_filter([lldap_group.app_users.display_name], var.lldap_base_dn)})"
+  attributes = ["uid", "mail"]
+  depends_on = [lldap_member.app_user]
+}
+
+data "lldap_ldap_search" "group" {
+  base_dn = provider::lldap::group_dn(lldap_group.app_users.display_name, var.lldap_base_dn)
+  scope   = "base"
+}
+
+check "app_filter" {
+  assert {
+    condition     = length(data.lldap_ldap_search.app_users.entries) == 1
+    error_message = "The app filter must match exactly one user"
+  }
+}
+
+output "app_user_ids" {
+  value = join(",", flatten(data.lldap_ldap_search.app_users.entries[*].attributes.uid))
+}
+
+output "app_user_dns" {
+  value = join(",", data.lldap_ldap_search.app_users.entries[*].dn)
+}
+
+output "group_entries" {
+  value = length(data.lldap_ldap_search.group.entries)
+}
@@ -0,0 +1,22 @@
+#!/usr/bin/env bash
+
+set -exo pipefail
+
+echo "=== LDAP Search Test ==="
+tofu apply -auto-approve
+
+BASE_DN="dc=terraform-provider-lldap,dc=tasansga,dc=github,dc=com"
+test "$(tofu output -raw app_user_ids)" == "ldap-search-app-user"
+test "$(tofu output -raw app_user_dns)" == "uid=ldap-search-app-user,ou=people,${BASE_DN}"
+test "$(tofu output -raw group_entries)" == "1"
+
+echo "=== Test lldap-cli ldap search ==="
+export LLDAP_BASE_DN="$BASE_DN"
+export LLDAP_HTTP_URL="http://${LLDAP_HOST}:${LLDAP_PORT_HTTP}"
+export LLDAP_LDAP_URL="ldap://${LLDAP_HOST}:${LLDAP_PORT_LDAP}"
+export LLDAP_USER="admin"
+test "$(../../dist/lldap-cli ldap search '(uid=ldap-search-other-user)' --attributes uid | jq -r '.[0].attributes.uid[0]')" == "ldap-search-other-user"
+
+tofu apply -auto-approve -destroy
+
+echo "=== All LDAP search tests completed successfully! ==="
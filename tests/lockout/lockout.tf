@@ -0,0 +1,63 @@
+terraform {
+  required_providers {
+    lldap = {
+      source  = "tasansga/lldap"
+      version = "0.0.1"
+    }
+  }
+}
+
+variable "lldap_http_url" {}
+variable "lldap_ldap_url" {}
+variable "lldap_username" {}
+variable "lldap_password" {}
+variable "lldap_base_dn" {}
+
+variable "allow_admin_lockout" {
+  default = false
+}
+
+variable "admin_password" {
+  default = null
+}
+
+variable "admin_group_user_ids" {
+  default = null
+}
+
+provider "lldap" {
+  http_url            = var.lldap_http_url
+  ldap_url            = var.lldap_ldap_url
+  username            = var.lldap_username
+  password            = var.lldap_password
+  base_dn             = var.lldap_base_dn
+  allow_admin_lockout = var.allow_admin_lockout
+}
+
+data "lldap_group" "admin" {
+  display_name = "lldap_admin"
+}
+
+data "lldap_user" "admin" {
+  id = var.lldap_username
+}
+
+resource "lldap_user" "admin" {
+  username = data.lldap_user.admin.username
+  email    = data.lldap_user.admin.email
+  password = var.admin_password == null ? var.lldap_password : var.admin_password
+}
+
+resource "lldap_group_memberships" "admin" {
+  group_id = data.lldap_group.admin.id
+  user_ids = var.admin_group_user_ids == null ? [var.lldap_username] : var.admin_group_user_ids
+}
+
+resource "lldap_group" "other" {
+  display_name = "lockout other group"
+}
+
+resource "lldap_member" "admin" {
+  group_id = lldap_group.other.id
+  user_id  = var.lldap_username
+}
@@ -0,0 +1,39 @@
+#!/usr/bin/env bash
+
+set -exo pipefail
+
+echo "=== Lockout Guard Test ==="
+
+tofu apply -auto-approve -target lldap_group.other -target lldap_member.admin
+tofu import lldap_user.admin "$LLDAP_USERNAME"
+tofu import lldap_group_memberships.admin name:lldap_admin
+tofu apply -auto-approve
+
+echo "=== Test removing the provider user from lldap_admin is refused ==="
+if tofu plan -var 'admin_group_user_ids=[]'; then
+  echo "Expected plan to fail because of the lockout guard"
+  exit 1
+fi
+tofu plan -var 'admin_group_user_ids=[]' -var allow_admin_lockout=true
+
+echo "=== Test changing the provider user password is refused ==="
+if tofu plan -var admin_password=changed-password; then
+  echo "Expected plan to fail because of the lockout guard"
+  exit 1
+fi
+tofu plan -var admin_password=changed-password -var allow_admin_lockout=true
+
+echo "=== Test deleting the provider user is refused ==="
+if tofu destroy -auto-approve -target lldap_user.admin; then
+  echo "Expected destroy to fail because of the lockout guard"
+  exit 1
+fi
+
+echo "=== Test memberships in other groups are not guarded ==="
+tofu destroy -auto-approve -target lldap_member.admin
+
+echo "=== Cleanup ==="
+tofu state rm lldap_user.admin lldap_group_memberships.admin
+tofu destroy -auto-approve
+
+echo "=== All lockout guard tests completed successfully! ==="
@@ -0,0 +1,78 @@
+terraform {
+  required_providers {
+    lldap = {
+      source  = "tasansga/lldap"
+      version = "0.0.1"
+    }
+  }
+}
+
+variable "lldap_http_url" {}
+variable "lldap_ldap_url" {}
+variable "lldap_username" {}
+variable "lldap_password" {}
+variable "lldap_base_dn" {}
+
+variable "reconcile_managed_only" {
+  default = true
+}
+
+variable "extra_user_ids" {
+  default = []
+}
+
+provider "lldap" {
+  http_url               = var.lldap_http_url
+  ldap_url               = var.lldap_ldap_url
+  username               = var.lldap_username
+  password               = var.lldap_password
+  base_dn                = var.lldap_base_dn
+  managed_marker         = "terraform_managed"
+  reconcile_managed_only = var.reconcile_managed_only
+}
+
+resource "lldap_user" "managed" {
+  username = "managed-user"
+  email    = "managed-user@example.com"
+}
+
+resource "lldap_group" "managed" {
+  display_name = "managed group"
+}
+
+resource "lldap_group_memberships" "managed" {
+  group_id = lldap_group.managed.id
+  user_ids = concat([lldap_user.managed.id], var.extra_user_ids)
+}
+
+resource "lldap_user" "other" {
+  username = "other-managed-user"
+  email    = "other-managed-user@example.com"
+}
+
+resource "lldap_group" "other" {
+  display_name = "other managed group"
+}
+
+resource "lldap_user_memberships" "other" {
+  user_id   = lldap_user.other.id
+  group_ids = [lldap_group.other.id]
+}
+
+output "group_id" {
+  value = lldap_group.managed.id
+}
+
+output "user_is_managed" {
+  value = contains(
+    [for attr in lldap_user.managed.attributes : attr.name if contains(attr.value, "terraform")],
+    "terraform_managed"
+  )
+}
+
+output "group_is_managed" {
+  value = contains(
+    [for attr in lldap_group.managed.attributes : attr.name if contains(attr.value, "terraform")],
+    "terraform_managed"
+  )
+}
@@ -0,0 +1,41 @@
+#!/usr/bin/env bash
+
+set -exo pipefail
+
+echo "=== Managed Marker Test ==="
+
+tofu apply -auto-approve
+tofu refresh
+test "$(tofu output -raw user_is_managed)" == "true"
+test "$(tofu output -raw group_is_managed)" == "true"
+
+echo "=== Test unmanaged memberships are left untouched ==="
+GROUP_ID="$(tofu output -raw group_id)"
+../../dist/lldap-cli user create unmanaged-user --email unmanaged-user@example.com
+../../dist/lldap-cli member add "$GROUP_ID" unmanaged-user
+../../dist/lldap-cli group create "unmanaged group"
+UNMANAGED_GROUP_ID="$(../../dist/lldap-cli group get | jq -r '.[] | select(.displayName == "unmanaged group") | .id')"
+../../dist/lldap-cli member add "$UNMANAGED_GROUP_ID" other-managed-user
+tofu plan -detailed-exitcode
+tofu apply -auto-approve
+../../dist/lldap-cli group get "$GROUP_ID" | jq -e '.users[] | select(.id == "unmanaged-user")'
+../../dist/lldap-cli user get other-managed-user | jq -e --argjson gid "$UNMANAGED_GROUP_ID" '.groups[] | select(.id == $gid)'
+
+echo "=== Test unmanaged memberships are reconciled without reconcile_managed_only ==="
+if tofu plan -detailed-exitcode -var reconcile_managed_only=false; then
+  echo "Expected plan to remove the unmanaged memberships"
+  exit 1
+fi
+
+echo "=== Test configured memberships of unmanaged users are removed ==="
+tofu apply -auto-approve -var 'extra_user_ids=["unmanaged-user"]'
+tofu apply -auto-approve
+if ../../dist/lldap-cli group get "$GROUP_ID" | jq -e '.users[] | select(.id == "unmanaged-user")'; then
+  echo "Expected the membership removed from user_ids to be removed"
+  exit 1
+fi
+
+echo "=== Clean up ==="
+tofu apply -auto-approve -destroy
+../../dist/lldap-cli user delete unmanaged-user
+../../dist/lldap-cli group delete "$UNMANAGED_GROUP_ID"
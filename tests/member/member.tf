@@ -80,9 +80,15 @@ resource "lldap_group" "test_groups" {
   display_name = "Test Group ${count.index} - ${random_string.test.result}"
 }
 
+variable "member_expires_at" {
+  type    = string
+  default = null
+}
+
 resource "lldap_member" "member" {
-  group_id = lldap_group.group.id
-  user_id  = lldap_user.user.id
+  group_id   = lldap_group.group.id
+  user_id    = lldap_user.user.id
+  expires_at = var.member_expires_at
 }
 
 # Create multiple member relationships
@@ -188,3 +194,7 @@ output "test_members" {
 output "member_count" {
   value = var.member_count
 }
+
+output "member_in_group" {
+  value = contains([for user in data.lldap_group.test_group.users : user.id], lldap_user.user.id)
+}
@@ -54,6 +54,16 @@ tofu apply -auto-approve
 echo "=== Clean up ==="
 tofu apply -auto-approve -destroy
 
+echo "=== Test Membership Expiry ==="
+tofu apply -auto-approve -var member_expires_at=2999-12-31T23:59:59Z
+test "$(tofu output -raw member_in_group)" == "true"
+tofu apply -auto-approve -var member_expires_at=2000-01-01T00:00:00Z
+test "$(tofu output -raw member_in_group)" == "false"
+tofu plan -detailed-exitcode -var member_expires_at=2000-01-01T00:00:00Z
+tofu apply -auto-approve -var member_expires_at=2999-12-31T23:59:59Z
+test "$(tofu output -raw member_in_group)" == "true"
+tofu apply -auto-approve -destroy
+
 echo "=== Test Member Lifecycle with Different Configurations ==="
 # Test with zero members
 tofu apply -auto-approve -var member_count=0
@@ -0,0 +1,64 @@
+terraform {
+  required_providers {
+    lldap = {
+      source  = "tasansga/lldap"
+      version = "0.0.1"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "3.6.3"
+    }
+  }
+}
+
+variable "lldap_http_url" {}
+variable "lldap_ldap_url" {}
+variable "lldap_username" {}
+variable "lldap_password" {}
+variable "lldap_base_dn" {}
+
+provider "lldap" {
+  http_url = var.lldap_http_url
+  ldap_url = var.lldap_ldap_url
+  username = var.lldap_username
+  password = var.lldap_password
+  base_dn  = var.lldap_base_dn
+}
+
+resource "random_string" "suffix" {
+  length  = 8
+  special = false
+  upper   = false
+  numeric = false
+}
+
+resource "lldap_user_object_class" "test" {
+  name = "testuserclass${random_string.suffix.result}"
+}
+
+resource "lldap_group_object_class" "test" {
+  name = "testgroupclass${random_string.suffix.result}"
+}
+
+data "lldap_object_classes" "all" {
+  depends_on = [
+    lldap_user_object_class.test,
+    lldap_group_object_class.test,
+  ]
+}
+
+output "user_object_class" {
+  value = lldap_user_object_class.test.id
+}
+
+output "group_object_class" {
+  value = lldap_group_object_class.test.id
+}
+
+output "user_object_class_listed" {
+  value = contains(data.lldap_object_classes.all.user_object_classes, lldap_user_object_class.test.id)
+}
+
+output "group_object_class_listed" {
+  value = contains(data.lldap_object_classes.all.group_object_classes, lldap_group_object_class.test.id)
+}
@@ -0,0 +1,26 @@
+#!/usr/bin/env bash
+
+set -exo pipefail
+
+echo "=== Object Class Lifecycle Test ==="
+
+echo "=== Test Create ==="
+tofu apply -auto-approve
+
+echo "=== Test Read (via data source) ==="
+tofu refresh
+test "$(tofu output -raw user_object_class_listed)" == "true"
+test "$(tofu output -raw group_object_class_listed)" == "true"
+
+echo "=== Test Import ==="
+user_object_class="$(tofu output -raw user_object_class)"
+group_object_class="$(tofu output -raw group_object_class)"
+tofu state rm lldap_user_object_class.test lldap_group_object_class.test
+tofu import lldap_user_object_class.test "$user_object_class"
+tofu import lldap_group_object_class.test "$group_object_class"
+tofu plan -detailed-exitcode
+
+echo "=== Test Delete ==="
+tofu apply -auto-approve -destroy
+
+echo "=== All object class tests completed successfully! ==="
@@ -9,6 +9,7 @@ tofu apply -auto-approve
 
 echo "=== Test Read (via data sources) ==="
 tofu refresh
+test "$(tofu output -raw main_user_lookup_matches)" == "true"
 
 echo "=== Test Update (password change) ==="
 tofu taint random_password.user
@@ -135,6 +135,22 @@ data "lldap_user" "main_user" {
   depends_on = [lldap_user.user]
 }
 
+# Look up the main user by its email and UUID
+data "lldap_user" "main_user_by_email" {
+  email = lldap_user.user.email
+}
+
+data "lldap_user" "main_user_by_uuid" {
+  uuid = lldap_user.user.uuid
+}
+
+output "main_user_lookup_matches" {
+  value = alltrue([
+    data.lldap_user.main_user_by_email.id == lldap_user.user.id,
+    data.lldap_user.main_user_by_uuid.id == lldap_user.user.id,
+  ])
+}
+
 # Outputs for verification
 output "user_count" {
   value = var.user_count
@@ -0,0 +1,23 @@
+#!/usr/bin/env bash
+
+set -exo pipefail
+
+echo "=== User Rename Test ==="
+
+echo "=== Test Create ==="
+tofu apply -auto-approve
+test "$(tofu output -raw id)" == "rename-before"
+uuid_before="$(tofu output -raw uuid)"
+
+echo "=== Test Rename Without Replacement ==="
+tofu plan -var username="rename-after" | grep -q "1 to change, 0 to destroy"
+tofu apply -auto-approve -var username="rename-after"
+test "$(tofu output -raw id)" == "rename-after"
+test "$(tofu output -raw email)" == "rename@example.com"
+test "$(tofu output -raw uuid)" != "$uuid_before"
+tofu plan -detailed-exitcode -var username="rename-after"
+
+echo "=== Test Delete ==="
+tofu apply -auto-approve -destroy -var username="rename-after"
+
+echo "=== All user rename tests completed successfully! ==="
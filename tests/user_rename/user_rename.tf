@@ -0,0 +1,46 @@
+terraform {
+  required_providers {
+    lldap = {
+      source  = "tasansga/lldap"
+      version = "0.0.1"
+    }
+  }
+}
+
+variable "lldap_http_url" {}
+variable "lldap_ldap_url" {}
+variable "lldap_username" {}
+variable "lldap_password" {}
+variable "lldap_base_dn" {}
+
+variable "username" {
+  default = "rename-before"
+}
+
+provider "lldap" {
+  http_url = var.lldap_http_url
+  ldap_url = var.lldap_ldap_url
+  username = var.lldap_username
+  password = var.lldap_password
+  base_dn  = var.lldap_base_dn
+}
+
+resource "lldap_user" "renamed" {
+  username        = var.username
+  email           = "rename@example.com"
+  display_name    = "Renamed user"
+  password        = "rename-password"
+  rename_strategy = "migrate"
+}
+
+output "id" {
+  value = lldap_user.renamed.id
+}
+
+output "uuid" {
+  value = lldap_user.renamed.uuid
+}
+
+output "email" {
+  value = lldap_user.renamed.email
+}
@@ -0,0 +1,27 @@
+#!/usr/bin/env bash
+
+set -exo pipefail
+
+echo "=== Bulk Users Test ==="
+
+echo "=== Test Create ==="
+tofu apply -auto-approve
+test "$(tofu output -raw user_count)" == "120"
+test "$(tofu output -raw result_count)" == "120"
+tofu plan -detailed-exitcode
+
+echo "=== Test Update ==="
+tofu apply -auto-approve -var display_name="Changed"
+test "$(tofu output -raw result_count)" == "1"
+test "$(tofu output -raw first_display_name)" == "Changed"
+
+echo "=== Test Delete Removed Users ==="
+tofu apply -auto-approve -var display_name="Changed" -var user_count=100
+test "$(tofu output -raw user_count)" == "100"
+test "$(tofu output -raw result_count)" == "20"
+tofu plan -detailed-exitcode -var display_name="Changed" -var user_count=100
+
+echo "=== Test Delete ==="
+tofu apply -auto-approve -destroy -var display_name="Changed" -var user_count=100
+
+echo "=== All bulk users tests completed successfully! ==="
@@ -0,0 +1,56 @@
+terraform {
+  required_providers {
+    lldap = {
+      source  = "tasansga/lldap"
+      version = "0.0.1"
+    }
+  }
+}
+
+variable "lldap_http_url" {}
+variable "lldap_ldap_url" {}
+variable "lldap_username" {}
+variable "lldap_password" {}
+variable "lldap_base_dn" {}
+
+variable "user_count" {
+  default = 120
+}
+
+variable "display_name" {
+  default = "Bulk user"
+}
+
+provider "lldap" {
+  http_url = var.lldap_http_url
+  ldap_url = var.lldap_ldap_url
+  username = var.lldap_username
+  password = var.lldap_password
+  base_dn  = var.lldap_base_dn
+}
+
+resource "lldap_users" "bulk" {
+  users = {
+    for i in range(var.user_count) : "bulk-user-${i}" => {
+      email        = "bulk-user-${i}@this.test"
+      display_name = i == 0 ? var.display_name : "Bulk user"
+    }
+  }
+}
+
+data "lldap_user" "first" {
+  depends_on = [lldap_users.bulk]
+  id         = "bulk-user-0"
+}
+
+output "user_count" {
+  value = length(lldap_users.bulk.users)
+}
+
+output "result_count" {
+  value = length(lldap_users.bulk.results)
+}
+
+output "first_display_name" {
+  value = data.lldap_user.first.display_name
+}